@@ -0,0 +1,75 @@
+// Command backfill saves the CBR rates of every day of a period, so expenses of past
+// dates are converted without fetching the rates while the user waits.
+//
+//	go run ./cmd/backfill -from 2025-01-01 -to 2025-12-31
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/cmd/logging"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/currency"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/database"
+	"go.uber.org/zap"
+)
+
+func main() {
+	from := flag.String("from", "", "first date of the period, YYYY-MM-DD")
+	to := flag.String("to", time.Now().Format("2006-01-02"), "last date of the period, YYYY-MM-DD")
+	configPath := flag.String("config", config.DefaultPath, "path to the config file, BOT_* variables override it")
+	delay := flag.Duration("delay", 200*time.Millisecond, "pause between requests to the CBR")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	logger := logging.InitLogger()
+
+	dateBegin, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		logger.Fatal("incorrect -from date", zap.Error(err))
+	}
+
+	dateEnd, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		logger.Fatal("incorrect -to date", zap.Error(err))
+	}
+
+	config, err := config.New(*configPath)
+	if err != nil {
+		logger.Fatal("config init failed:", zap.Error(err))
+	}
+
+	db, err := database.New(config)
+	if err != nil {
+		logger.Fatal("database init failed", zap.Error(err))
+	}
+
+	rateProviders, err := currency.NewProviders(config)
+	if err != nil {
+		logger.Fatal("rate providers init failed:", zap.Error(err))
+	}
+
+	updater := currency.NewRateUpdater(config, database.NewRatesDB(db), rateProviders)
+
+	for date := dateBegin; !date.After(dateEnd); date = date.AddDate(0, 0, 1) {
+		if ctx.Err() != nil {
+			logger.Info("backfill interrupted", zap.Time("date", date))
+			return
+		}
+
+		err := updater.UpdateCurrencyRateOnDate(ctx, date)
+		if err != nil {
+			logger.Error("cannot update rates", zap.Time("date", date), zap.Error(err))
+		}
+
+		time.Sleep(*delay)
+	}
+
+	logger.Info("backfill finished")
+}
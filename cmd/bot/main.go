@@ -2,24 +2,33 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/cmd/logging"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/cmd/metrics"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/cmd/tracing"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/cache"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/clients/tg"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/currency"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/database"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/model/callbacks"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/model/messages"
-	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/redis"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/worker"
 	"go.uber.org/zap"
 )
 
+// configCheckInterval is how often the config file is checked for changes.
+const configCheckInterval = 10 * time.Second
+
 func main() {
+	configPath := flag.String("config", config.DefaultPath, "path to the config file, BOT_* variables override it")
+	flag.Parse()
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
@@ -27,10 +36,11 @@ func main() {
 	tracing.InitTracing("actions_handler", logger)
 
 	logger.Info("initializing config")
-	config, err := config.New()
+	config, err := config.New(*configPath)
 	if err != nil {
 		logger.Fatal("config init failed:", zap.Error(err))
 	}
+	logging.Level.SetLevel(config.GetLogLevel())
 
 	logger.Info("initializing database")
 	db, err := database.New(config)
@@ -38,8 +48,8 @@ func main() {
 		logger.Fatal("database init failed", zap.Error(err))
 	}
 
-	logger.Info("initializing cache")
-	cache, err := redis.New(config)
+	logger.Info("initializing cache", zap.String("backend", config.GetCacheBackend()))
+	cache, err := cache.New(config)
 	if err != nil {
 		logger.Fatal("cache init failed", zap.Error(err))
 	}
@@ -48,6 +58,14 @@ func main() {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
+	budgetsDB := database.NewBudgetsDB(db)
+	recurringDB := database.NewRecurringDB(db)
+	importsDB := database.NewImportsDB(db)
+	accountsDB := database.NewAccountsDB(db)
+	groupsDB := database.NewGroupsDB(db)
+	splitsDB := database.NewSplitsDB(db)
+	categoriesDB := database.NewCategoriesDB(db)
+	txManager := database.NewTxManager(db)
 
 	logger.Info("initializing telegram client")
 	tgClient, err := tg.New(config)
@@ -55,15 +73,45 @@ func main() {
 		logger.Fatal("tg client init failed:", zap.Error(err))
 	}
 
-	currencyUpdateModel := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	rateProviders, err := currency.NewProviders(config)
+	if err != nil {
+		logger.Fatal("rate providers init failed:", zap.Error(err))
+	}
+
+	currencyUpdateModel := currency.NewRateUpdater(config, ratesDB, rateProviders)
 
-	msgModel := messages.New(tgClient, expensesDB, usersDB, ratesDB, limitsDB, currencyUpdateModel)
-	callbackModel := callbacks.New(tgClient, expensesDB, usersDB, ratesDB)
+	callbackModel := callbacks.New(tgClient, expensesDB, usersDB, ratesDB, budgetsDB, importsDB, accountsDB, groupsDB, categoriesDB, txManager)
+	msgModel := messages.New(tgClient, expensesDB, usersDB, ratesDB, limitsDB, budgetsDB, recurringDB, importsDB,
+		accountsDB, groupsDB, splitsDB, categoriesDB, txManager, currencyUpdateModel, callbackModel, config)
+
+	callbackModel.SetBudgetChecker(msgModel)
 
 	currencyRateWorker := worker.NewCurrencyRateWorker(currencyUpdateModel)
+	recurringExpenseWorker := worker.NewRecurringExpenseWorker(recurringDB, expensesDB, txManager, msgModel)
 	updateListenerWorker := worker.NewUpdateListenerWorker(tgClient, msgModel, callbackModel, cache)
 
+	// The settings which are read on every use change by themselves, the rest are handed over here.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	config.Watch(ctx, *configPath, configCheckInterval, reload, func(rejected []string, err error) {
+		if err != nil {
+			logger.Error("config reload failed", zap.Error(err))
+			return
+		}
+
+		for _, field := range rejected {
+			logger.Warn("config field needs a restart, the change is ignored", zap.String("field", field))
+		}
+
+		logging.Level.SetLevel(config.GetLogLevel())
+		currency.UpdateProviderURLs(rateProviders, config)
+		currencyRateWorker.SetUpdateFrequency(config.GetUpdateRate())
+		recurringExpenseWorker.SetCheckFrequency(config.GetRecurringCheckRate())
+		logger.Info("config reloaded")
+	})
+
 	metrics.CollectMetrics(logger)
 	currencyRateWorker.Run(ctx, config.GetUpdateRate())
+	recurringExpenseWorker.Run(ctx, config.GetRecurringCheckRate())
 	updateListenerWorker.Run(ctx)
 }
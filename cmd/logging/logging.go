@@ -6,8 +6,14 @@ import (
 	"go.uber.org/zap"
 )
 
+// Level is the level of the logger, it is changed when the config is reloaded.
+var Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
 func InitLogger() *zap.Logger {
-	logger, err := zap.NewProduction()
+	config := zap.NewProductionConfig()
+	config.Level = Level
+
+	logger, err := config.Build()
 	if err != nil {
 		log.Fatal("cannot init logger ", err)
 	}
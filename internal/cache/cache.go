@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/redis"
+)
+
+const (
+	BackendRedis = "redis"
+	BackendLRU   = "lru"
+	BackendNone  = "none"
+)
+
+// ErrNotFound is returned by Get of a key which is not in the cache.
+var ErrNotFound = errors.New("key not found")
+
+// Cache is the database.CacheModel the bot closes on shutdown.
+type Cache interface {
+	Ping() error
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Exists(key string) (bool, error)
+	Delete(key string) error
+	Close()
+}
+
+// New creates the cache backend chosen in the config.
+func New(config *config.Service) (Cache, error) {
+	switch config.GetCacheBackend() {
+	case BackendRedis:
+		cache, err := redis.New(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot redis.New")
+		}
+
+		return cache, nil
+	case BackendLRU:
+		return NewLRU(config.GetCacheSize(), config.GetCacheTTL()), nil
+	case BackendNone:
+		return NewNoop(), nil
+	}
+
+	return nil, errors.Errorf("unknown cache backend %q", config.GetCacheBackend())
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Zero if the entry does not expire.
+}
+
+// LRU is the in-process cache which keeps at most size entries and drops the least recently used one.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	maxTTL  time.Duration
+	entries map[string]*list.Element
+	order   *list.List // The most recently used entry is at the front.
+	now     func() time.Time
+}
+
+// NewLRU creates the cache of size entries, a positive maxTTL limits the life of every entry.
+func NewLRU(size int, maxTTL time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		maxTTL:  maxTTL,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (c *LRU) Ping() error {
+	return nil
+}
+
+func (c *LRU) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return entry.value, nil
+}
+
+// Set stores the value for the ttl cut to the max ttl of the cache, a zero ttl is the max ttl.
+func (c *LRU) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxTTL > 0 && (ttl <= 0 || ttl > c.maxTTL) {
+		ttl = c.maxTTL
+	}
+
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *LRU) Exists(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.get(key)
+
+	return ok, nil
+}
+
+func (c *LRU) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	return nil
+}
+
+func (c *LRU) Close() {}
+
+// get returns the entry which has not expired and marks it as used, the lock must be held.
+func (c *LRU) get(key string) (*lruEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry, true
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
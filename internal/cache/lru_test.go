@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_ShouldEvictLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRU(2, 0)
+
+	assert.NoError(t, cache.Set("a", []byte("1"), 0))
+	assert.NoError(t, cache.Set("b", []byte("2"), 0))
+
+	// "a" is used, so "b" is the one to go.
+	value, err := cache.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	assert.NoError(t, cache.Set("c", []byte("3"), 0))
+
+	ok, _ := cache.Exists("b")
+	assert.False(t, ok)
+	ok, _ = cache.Exists("a")
+	assert.True(t, ok)
+	ok, _ = cache.Exists("c")
+	assert.True(t, ok)
+
+	assert.NoError(t, cache.Delete("a"))
+	_, err = cache.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLRU_ShouldExpireEntries(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cache := NewLRU(10, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	assert.NoError(t, cache.Set("short", []byte("1"), time.Minute))
+	assert.NoError(t, cache.Set("long", []byte("2"), 24*time.Hour))
+	assert.NoError(t, cache.Set("forever", []byte("3"), 0))
+
+	now = now.Add(2 * time.Minute)
+
+	ok, _ := cache.Exists("short")
+	assert.False(t, ok)
+	ok, _ = cache.Exists("long")
+	assert.True(t, ok)
+
+	// The max ttl of the cache cuts both the longer ttl and no ttl.
+	now = now.Add(time.Hour)
+
+	ok, _ = cache.Exists("long")
+	assert.False(t, ok)
+	ok, _ = cache.Exists("forever")
+	assert.False(t, ok)
+}
@@ -0,0 +1,32 @@
+package cache
+
+import "time"
+
+// Noop is the cache which keeps nothing, every report is read from the database.
+type Noop struct{}
+
+func NewNoop() *Noop {
+	return &Noop{}
+}
+
+func (c *Noop) Ping() error {
+	return nil
+}
+
+func (c *Noop) Get(string) ([]byte, error) {
+	return nil, ErrNotFound
+}
+
+func (c *Noop) Set(string, []byte, time.Duration) error {
+	return nil
+}
+
+func (c *Noop) Exists(string) (bool, error) {
+	return false, nil
+}
+
+func (c *Noop) Delete(string) error {
+	return nil
+}
+
+func (c *Noop) Close() {}
@@ -0,0 +1,157 @@
+// Package charts draws report charts as PNG images. The images have no text,
+// the caption of the message explains them with the emoji of the colors.
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	width  = 600
+	height = 400
+)
+
+// Color is a chart color and the emoji square of the same color.
+type Color struct {
+	RGBA  color.RGBA
+	Emoji string
+}
+
+// Palette colors pie slices in order, the last color is for the rest of them.
+var Palette = []Color{
+	{RGBA: color.RGBA{R: 0xdd, G: 0x2e, B: 0x44, A: 0xff}, Emoji: "🟥"},
+	{RGBA: color.RGBA{R: 0xf4, G: 0x90, B: 0x0c, A: 0xff}, Emoji: "🟧"},
+	{RGBA: color.RGBA{R: 0xfd, G: 0xcb, B: 0x58, A: 0xff}, Emoji: "🟨"},
+	{RGBA: color.RGBA{R: 0x78, G: 0xb1, B: 0x59, A: 0xff}, Emoji: "🟩"},
+	{RGBA: color.RGBA{R: 0x55, G: 0xac, B: 0xee, A: 0xff}, Emoji: "🟦"},
+	{RGBA: color.RGBA{R: 0xaa, G: 0x8e, B: 0xd6, A: 0xff}, Emoji: "🟪"},
+	{RGBA: color.RGBA{R: 0xc1, G: 0x69, B: 0x4f, A: 0xff}, Emoji: "🟫"},
+	{RGBA: color.RGBA{R: 0x31, G: 0x37, B: 0x3d, A: 0xff}, Emoji: "⬛"},
+}
+
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	gridColor  = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	axisColor  = color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+)
+
+// Pie draws the values as slices clockwise from the top, colored by the palette in order.
+func Pie(values []float64) ([]byte, error) {
+	if len(values) > len(Palette) {
+		return nil, errors.Errorf("too many slices: %d", len(values))
+	}
+
+	total := 0.0
+	for _, value := range values {
+		if value < 0 {
+			return nil, errors.New("negative value")
+		}
+		total += value
+	}
+
+	img := newImage()
+	if total == 0 {
+		return encode(img)
+	}
+
+	// Angles where each slice ends, as a share of the full circle.
+	ends := make([]float64, len(values))
+	sum := 0.0
+	for i, value := range values {
+		sum += value
+		ends[i] = sum / total
+	}
+
+	centerX, centerY := width/2, height/2
+	radius := float64(height)/2 - 20
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := float64(x-centerX)+0.5, float64(y-centerY)+0.5
+			if math.Hypot(dx, dy) > radius {
+				continue
+			}
+
+			// Clockwise from 12 o'clock, in [0, 1).
+			share := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if share < 0 {
+				share++
+			}
+
+			for i, end := range ends {
+				if share < end || i == len(ends)-1 {
+					img.Set(x, y, Palette[i].RGBA)
+					break
+				}
+			}
+		}
+	}
+
+	return encode(img)
+}
+
+// Bar draws the values as bars from left to right with horizontal grid lines at every quarter of the maximum.
+func Bar(values []float64) ([]byte, error) {
+	img := newImage()
+
+	const margin = 20
+	plotWidth, plotHeight := width-2*margin, height-2*margin
+	bottom := height - margin
+
+	for quarter := 1; quarter <= 4; quarter++ {
+		y := bottom - plotHeight*quarter/4
+		fill(img, image.Rect(margin, y, width-margin, y+1), gridColor)
+	}
+
+	maxValue := 0.0
+	for _, value := range values {
+		if value < 0 {
+			return nil, errors.New("negative value")
+		}
+		maxValue = math.Max(maxValue, value)
+	}
+
+	if len(values) > 0 && maxValue > 0 {
+		slot := float64(plotWidth) / float64(len(values))
+		gap := int(slot / 5)
+
+		for i, value := range values {
+			left := margin + int(float64(i)*slot)
+			right := margin + int(float64(i+1)*slot)
+			top := bottom - int(value/maxValue*float64(plotHeight))
+			fill(img, image.Rect(left+gap, top, right-gap, bottom), Palette[4].RGBA)
+		}
+	}
+
+	fill(img, image.Rect(margin, bottom, width-margin, bottom+2), axisColor)
+
+	return encode(img)
+}
+
+func newImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), background)
+
+	return img
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "cannot Encode")
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,49 @@
+package charts
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPie(t *testing.T) {
+	data, err := Pie([]float64{3, 1})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// The first slice takes three quarters clockwise from the top: right, bottom and left parts.
+	assert.Equal(t, Palette[0].RGBA, toRGBA(img.At(width/2+100, height/2)))
+	assert.Equal(t, Palette[0].RGBA, toRGBA(img.At(width/2, height/2+100)))
+	assert.Equal(t, Palette[1].RGBA, toRGBA(img.At(width/2-50, height/2-100)))
+	assert.Equal(t, background, toRGBA(img.At(0, 0)))
+}
+
+func TestPie_TooManySlices(t *testing.T) {
+	_, err := Pie(make([]float64, len(Palette)+1))
+	assert.Error(t, err)
+}
+
+func TestBar(t *testing.T) {
+	data, err := Bar([]float64{1, 0, 2})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// Bars take the thirds of the plot, the highest one reaches the top.
+	assert.Equal(t, Palette[4].RGBA, toRGBA(img.At(width-width/6, height/4)))
+	assert.Equal(t, Palette[4].RGBA, toRGBA(img.At(width/6, height-height/4)))
+	assert.NotEqual(t, Palette[4].RGBA, toRGBA(img.At(width/6, height/4)))
+	assert.NotEqual(t, Palette[4].RGBA, toRGBA(img.At(width/2, height-height/4)))
+}
+
+func toRGBA(c color.Color) color.RGBA {
+	r, g, b, a := c.RGBA()
+	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+}
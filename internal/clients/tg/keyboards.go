@@ -1,8 +1,11 @@
 package tg
 
 import (
+	"fmt"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/model/callbacks"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
 )
 
 var createExpenseKeyboard = tgbotapi.NewInlineKeyboardMarkup(
@@ -15,6 +18,9 @@ var createExpenseKeyboard = tgbotapi.NewInlineKeyboardMarkup(
 	tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("Изменить дату", callbacks.ChangeExpenseDate),
 	),
+	tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Изменить счет", callbacks.ChangeExpenseAccount),
+	),
 	tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("Готово", callbacks.ChangeExpenseDone),
 	),
@@ -33,19 +39,162 @@ var getReportKeyboard = tgbotapi.NewInlineKeyboardMarkup(
 	tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("Год", callbacks.GetYearReport),
 	),
-)
-
-var changeCurrencyKeyboard = tgbotapi.NewInlineKeyboardMarkup(
-	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("USD", callbacks.USD),
-	),
 	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("CNY", callbacks.CNY),
+		tgbotapi.NewInlineKeyboardButtonData("Этот месяц", callbacks.GetCalendarMonthReport),
+		tgbotapi.NewInlineKeyboardButtonData("Прошлый месяц", callbacks.GetPreviousMonthReport),
 	),
 	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("EUR", callbacks.EUR),
+		tgbotapi.NewInlineKeyboardButtonData("Квартал", callbacks.GetQuarterReport),
+		tgbotapi.NewInlineKeyboardButtonData("С начала года", callbacks.GetYearToDateReport),
 	),
 	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("RUB", callbacks.RUB),
+		tgbotapi.NewInlineKeyboardButtonData("📊 Текст / графики", callbacks.ToggleReportMode),
+		tgbotapi.NewInlineKeyboardButtonData("💱 Курс пересчета", callbacks.ToggleReportCurrency),
 	),
 )
+
+// currencyKeyboard has a button for every currency on the page and page switches.
+func currencyKeyboard(page types.CurrencyPage) tgbotapi.InlineKeyboardMarkup {
+	const buttonsInRow = 4
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, currency := range page.Currencies {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			string(currency.Code),
+			fmt.Sprintf("%s:%s", callbacks.SelectCurrency, currency.Code),
+		))
+
+		if len(row) == buttonsInRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	var pages []tgbotapi.InlineKeyboardButton
+	if page.Page > 0 {
+		pages = append(pages, tgbotapi.NewInlineKeyboardButtonData(
+			"◀️", fmt.Sprintf("%s:%d:%s", callbacks.CurrencyPage, page.Page-1, page.Query),
+		))
+	}
+	if page.Page+1 < page.PagesCount {
+		pages = append(pages, tgbotapi.NewInlineKeyboardButtonData(
+			"▶️", fmt.Sprintf("%s:%d:%s", callbacks.CurrencyPage, page.Page+1, page.Query),
+		))
+	}
+	if len(pages) > 0 {
+		rows = append(rows, pages)
+	}
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// accountsKeyboard has a button for every account of the user and one to clear the account.
+func accountsKeyboard(accounts []types.Account) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for _, account := range accounts {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s, %s", account.Name, account.Currency),
+				fmt.Sprintf("%s:%d", callbacks.SelectExpenseAccount, account.ID),
+			),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Без счета", fmt.Sprintf("%s:%d", callbacks.SelectExpenseAccount, 0)),
+	))
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// categoriesKeyboard has a button for every category offered for new expenses, two in a row,
+// and one to go back to the edit buttons.
+func categoriesKeyboard(categories []types.Category) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for i, category := range categories {
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			category.Title(),
+			fmt.Sprintf("%s:%d", callbacks.SelectExpenseCategory, category.ID),
+		)
+
+		if i%2 == 0 {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("%s:%d", callbacks.SelectExpenseCategory, 0)),
+	))
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// reportKeyboard has a button for every parent category of the report to see its subcategories.
+func reportKeyboard(drillDowns []types.ReportDrillDown) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for _, drillDown := range drillDowns {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"🔍 "+drillDown.Title,
+				fmt.Sprintf("%s:%d:%s:%s", callbacks.ReportCategory, drillDown.CategoryID,
+					drillDown.DateBegin.Format("20060102"), drillDown.DateEnd.Format("20060102")),
+			),
+		))
+	}
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// historyKeyboard has edit and delete buttons for every expense on the page and page switches.
+func historyKeyboard(page types.HistoryPage) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for i, expense := range page.Expenses {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✏️ %d", i+1),
+				fmt.Sprintf("%s:%d", callbacks.HistoryEdit, expense.ExpenseID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("🗑 %d", i+1),
+				fmt.Sprintf("%s:%d:%d", callbacks.HistoryDelete, expense.ExpenseID, page.Page),
+			),
+		))
+	}
+
+	var pages []tgbotapi.InlineKeyboardButton
+	if page.Page > 0 {
+		pages = append(pages, tgbotapi.NewInlineKeyboardButtonData(
+			"◀️", fmt.Sprintf("%s:%d", callbacks.HistoryPage, page.Page-1),
+		))
+	}
+	if page.Page+1 < page.PagesCount {
+		pages = append(pages, tgbotapi.NewInlineKeyboardButtonData(
+			"▶️", fmt.Sprintf("%s:%d", callbacks.HistoryPage, page.Page+1),
+		))
+	}
+	if len(pages) > 0 {
+		rows = append(rows, pages)
+	}
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func importKeyboard(importID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Импортировать", fmt.Sprintf("%s:%d", callbacks.ImportConfirm, importID)),
+			tgbotapi.NewInlineKeyboardButtonData("Отменить", fmt.Sprintf("%s:%d", callbacks.ImportCancel, importID)),
+		),
+	)
+}
@@ -1,10 +1,13 @@
 package tg
 
 import (
+	"io"
 	"log"
+	"net/http"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
 )
 
 type tokenGetter interface {
@@ -34,20 +37,37 @@ func (c *Client) SendMessage(text string, userID int64) error {
 	return nil
 }
 
-func (c *Client) CreateExpense(text string, userID int64) error {
+// SendReportMessage sends the text report with the buttons to look into the parent categories.
+func (c *Client) SendReportMessage(text string, userID int64, drillDowns []types.ReportDrillDown) error {
 	msg := tgbotapi.NewMessage(userID, text)
 
-	msg.ReplyMarkup = createExpenseKeyboard
+	if len(drillDowns) > 0 {
+		msg.ReplyMarkup = reportKeyboard(drillDowns)
+	}
 
 	_, err := c.client.Send(msg)
-
 	if err != nil {
 		return errors.Wrap(err, "cannot Send")
 	}
-
 	return nil
 }
 
+// CreateExpense sends the expense card and returns its message id,
+// which is used as the id of the expense.
+func (c *Client) CreateExpense(text string, userID int64) (int, error) {
+	msg := tgbotapi.NewMessage(userID, text)
+
+	msg.ReplyMarkup = createExpenseKeyboard
+
+	sent, err := c.client.Send(msg)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot Send")
+	}
+
+	return sent.MessageID, nil
+}
+
 func (c *Client) ShowAlert(text string, messageID string) error {
 	alert := tgbotapi.NewCallback(messageID, text)
 	_, err := c.client.Request(alert)
@@ -127,12 +147,141 @@ func (c *Client) GetReport(text string, userID int64) error {
 	return nil
 }
 
-func (c *Client) ChangeCurrency(text string, userID int64) error {
+func (c *Client) SendHistory(text string, userID int64, page types.HistoryPage) error {
 	msg := tgbotapi.NewMessage(userID, text)
-	msg.ReplyMarkup = changeCurrencyKeyboard
+	msg.ReplyMarkup = historyKeyboard(page)
 	_, err := c.client.Send(msg)
 
-	if err == nil {
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+func (c *Client) EditHistory(text string, userID int64, messageID int, page types.HistoryPage) error {
+	editMessage := tgbotapi.NewEditMessageTextAndMarkup(userID, messageID, text, historyKeyboard(page))
+	_, err := c.client.Send(editMessage)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+// SendDocument uploads the file while it is being read from data.
+func (c *Client) SendDocument(name string, data io.Reader, userID int64) error {
+	document := tgbotapi.NewDocument(userID, tgbotapi.FileReader{
+		Name:   name,
+		Reader: data,
+	})
+	_, err := c.client.Send(document)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+func (c *Client) SendPhoto(name string, data []byte, caption string, userID int64) error {
+	photo := tgbotapi.NewPhoto(userID, tgbotapi.FileBytes{
+		Name:  name,
+		Bytes: data,
+	})
+	photo.Caption = caption
+	_, err := c.client.Send(photo)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+// SendImportPreview sends parsed rows of the statement with confirm and cancel buttons.
+func (c *Client) SendImportPreview(text string, userID int64, importID int) error {
+	msg := tgbotapi.NewMessage(userID, text)
+	msg.ReplyMarkup = importKeyboard(importID)
+	_, err := c.client.Send(msg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+// DownloadFile returns the content of the file sent to the bot.
+func (c *Client) DownloadFile(fileID string) ([]byte, error) {
+	url, err := c.client.GetFileDirectURL(fileID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot GetFileDirectURL")
+	}
+
+	resp, err := http.Get(url)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Get")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot ReadAll")
+	}
+
+	return data, nil
+}
+
+func (c *Client) SendCurrencies(text string, userID int64, page types.CurrencyPage) error {
+	msg := tgbotapi.NewMessage(userID, text)
+	msg.ReplyMarkup = currencyKeyboard(page)
+	_, err := c.client.Send(msg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+func (c *Client) EditCurrencies(text string, userID int64, messageID int, page types.CurrencyPage) error {
+	editMessage := tgbotapi.NewEditMessageTextAndMarkup(userID, messageID, text, currencyKeyboard(page))
+	_, err := c.client.Send(editMessage)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+// EditExpenseAccounts replaces the buttons of the expense card with the accounts to choose from.
+func (c *Client) EditExpenseAccounts(userID int64, messageID int, accounts []types.Account) error {
+	editMarkup := tgbotapi.NewEditMessageReplyMarkup(userID, messageID, accountsKeyboard(accounts))
+	_, err := c.client.Send(editMarkup)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Send")
+	}
+
+	return nil
+}
+
+// EditExpenseCategories replaces the buttons of the expense card with the categories to choose from.
+func (c *Client) EditExpenseCategories(userID int64, messageID int, categories []types.Category) error {
+	editMarkup := tgbotapi.NewEditMessageReplyMarkup(userID, messageID, categoriesKeyboard(categories))
+	_, err := c.client.Send(editMarkup)
+
+	if err != nil {
 		return errors.Wrap(err, "cannot Send")
 	}
 
@@ -2,18 +2,28 @@ package config
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v3"
 )
 
-const configFile = "data/config.yaml"
+// DefaultPath is the config file used without the --config flag.
+const DefaultPath = "data/config.yaml"
 
 type Config struct {
-	Token                       string `yaml:"token"`
-	CbrServiceUrl               string `yaml:"cbr_service_url"`
-	FrequencyCurrencyRateUpdate int    `yaml:"frequency_currency_rate_update"`
+	Token                       string   `yaml:"token"`
+	CbrServiceUrl               string   `yaml:"cbr_service_url"`
+	EcbServiceUrl               string   `yaml:"ecb_service_url"`
+	RateProviders               []string `yaml:"rate_providers"`
+	ProviderMaxStalenessDays    int      `yaml:"provider_max_staleness_days"`
+	FrequencyCurrencyRateUpdate int      `yaml:"frequency_currency_rate_update"`
+	FrequencyRecurringCheck     int      `yaml:"frequency_recurring_check"`
+	BudgetThresholds            []int    `yaml:"budget_thresholds"`
+	DefaultLimit                int      `yaml:"default_limit"` // Roubles a month for users without their own limit.
+	LogLevel                    string   `yaml:"log_level"`
 
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
@@ -22,44 +32,280 @@ type Config struct {
 	Database string `yaml:"database"`
 	TestDB   string `yaml:"test_db"`
 	SslMode  string `yaml:"sslmode"`
+
+	Cache CacheConfig `yaml:"cache"`
+	Redis RedisConfig `yaml:"redis"`
+}
+
+// CacheConfig chooses where the reports are cached: "redis", "lru" in the process or "none".
+type CacheConfig struct {
+	Backend string `yaml:"backend"`
+	Size    int    `yaml:"size"` // Entries of the lru cache.
+	TTL     int    `yaml:"ttl"`  // Seconds an entry of the lru cache lives at most.
 }
 
+// RedisConfig is the connection to Redis, the timeouts are in seconds.
+type RedisConfig struct {
+	Address       string `yaml:"address"` // host:port, the database host and the default port if empty.
+	Password      string `yaml:"password"`
+	DB            int    `yaml:"db"`
+	MaxIdle       int    `yaml:"max_idle"`
+	MaxActive     int    `yaml:"max_active"` // Zero is no limit.
+	IdleTimeout   int    `yaml:"idle_timeout"`
+	DialTimeout   int    `yaml:"dial_timeout"`
+	ReadTimeout   int    `yaml:"read_timeout"`
+	WriteTimeout  int    `yaml:"write_timeout"`
+	TLS           bool   `yaml:"tls"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify"`
+	TLSServerName string `yaml:"tls_server_name"`
+}
+
+// Service gives the config to the rest of the bot. The getters are safe to call
+// while Reload replaces the settings which can be changed live.
 type Service struct {
+	mu     sync.RWMutex
 	Config Config
 }
 
-func New() (*Service, error) {
+// New reads the config file, overrides its fields with the BOT_* environment variables,
+// fills in the defaults and validates the result. The default file may be missing
+// if everything is set in the environment.
+func New(path string) (*Service, error) {
+	return load(path, path == DefaultPath, os.LookupEnv)
+}
+
+// load reads the config, a missing file is the same as an empty one if it is optional.
+func load(path string, optional bool, lookupEnv func(string) (string, bool)) (*Service, error) {
 	s := &Service{}
 
-	rawYAML, err := os.ReadFile(configFile)
-	if err != nil {
+	rawYAML, err := os.ReadFile(path)
+
+	switch {
+	case os.IsNotExist(err) && optional:
+	case err != nil:
 		return nil, errors.Wrap(err, "cannot ReadFile")
+	default:
+		err = yaml.Unmarshal(rawYAML, &s.Config)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot Unmarshal")
+		}
 	}
 
-	err = yaml.Unmarshal(rawYAML, &s.Config)
+	err = applyEnv(&s.Config, lookupEnv)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot Unmarshal")
+		return nil, errors.Wrap(err, "cannot applyEnv")
+	}
+
+	setDefaults(&s.Config)
+
+	err = s.Config.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid config")
 	}
 
 	return s, nil
 }
 
+// setDefaults fills in the optional fields which are not set.
+func setDefaults(c *Config) {
+	if c.EcbServiceUrl == "" {
+		c.EcbServiceUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	}
+
+	// The CBR only.
+	if len(c.RateProviders) == 0 {
+		c.RateProviders = []string{"cbr"}
+	}
+
+	if c.ProviderMaxStalenessDays == 0 {
+		c.ProviderMaxStalenessDays = 4
+	}
+
+	// Hourly.
+	if c.FrequencyCurrencyRateUpdate == 0 {
+		c.FrequencyCurrencyRateUpdate = 3600
+	}
+
+	if c.FrequencyRecurringCheck == 0 {
+		c.FrequencyRecurringCheck = 3600
+	}
+
+	if len(c.BudgetThresholds) == 0 {
+		c.BudgetThresholds = []int{80, 100}
+	}
+
+	if c.DefaultLimit == 0 {
+		c.DefaultLimit = 10000
+	}
+
+	if c.LogLevel == "" {
+		c.LogLevel = "info"
+	}
+
+	if c.Port == 0 {
+		c.Port = 5432
+	}
+
+	if c.Cache.Backend == "" {
+		c.Cache.Backend = "redis"
+	}
+
+	if c.Cache.Size == 0 {
+		c.Cache.Size = 10000
+	}
+
+	if c.Cache.TTL == 0 {
+		c.Cache.TTL = 3600
+	}
+
+	if c.Redis.Address == "" {
+		c.Redis.Address = c.Host + ":6379"
+	}
+
+	if c.Redis.MaxIdle == 0 {
+		c.Redis.MaxIdle = 3
+	}
+
+	if c.Redis.IdleTimeout == 0 {
+		c.Redis.IdleTimeout = 240
+	}
+
+	if c.Redis.DialTimeout == 0 {
+		c.Redis.DialTimeout = 5
+	}
+
+	if c.Redis.ReadTimeout == 0 {
+		c.Redis.ReadTimeout = 3
+	}
+
+	if c.Redis.WriteTimeout == 0 {
+		c.Redis.WriteTimeout = 3
+	}
+}
+
 func (s *Service) Token() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return s.Config.Token
 }
 
 func (s *Service) GetUrl() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return s.Config.CbrServiceUrl
 }
 
+func (s *Service) GetEcbUrl() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.EcbServiceUrl
+}
+
+// GetRateProviders returns the names of the rate providers in the order they are tried.
+func (s *Service) GetRateProviders() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.RateProviders
+}
+
+// GetProviderMaxStaleness returns how many days old the rates of a provider may be before the next one is tried.
+func (s *Service) GetProviderMaxStaleness() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.ProviderMaxStalenessDays
+}
+
 func (s *Service) GetUpdateRate() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return time.Duration(s.Config.FrequencyCurrencyRateUpdate) * time.Second
 }
 
+// GetRecurringCheckRate returns how often due recurring expenses are written.
+func (s *Service) GetRecurringCheckRate() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return time.Duration(s.Config.FrequencyRecurringCheck) * time.Second
+}
+
+// GetBudgetThresholds returns percents of a category budget at which the user is warned.
+func (s *Service) GetBudgetThresholds() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.BudgetThresholds
+}
+
 func (s *Service) GetHost() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return s.Config.Host
 }
 
 func (s *Service) GetPort() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return s.Config.Port
 }
+
+// GetRedis returns the Redis connection settings.
+func (s *Service) GetRedis() RedisConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.Redis
+}
+
+// GetCacheBackend returns the cache backend.
+func (s *Service) GetCacheBackend() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.Cache.Backend
+}
+
+// GetCacheSize returns how many entries the lru cache keeps.
+func (s *Service) GetCacheSize() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.Cache.Size
+}
+
+// GetCacheTTL returns how long an entry of the lru cache lives at most.
+func (s *Service) GetCacheTTL() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return time.Duration(s.Config.Cache.TTL) * time.Second
+}
+
+// GetDefaultLimit returns the month limit in kopecks of the users who have not set their own.
+func (s *Service) GetDefaultLimit() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Config.DefaultLimit * 100
+}
+
+// GetLogLevel returns the level of the log, the level is checked by Validate.
+func (s *Service) GetLogLevel() zapcore.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	level, err := zapcore.ParseLevel(s.Config.LogLevel)
+	if err != nil {
+		return zapcore.InfoLevel
+	}
+
+	return level
+}
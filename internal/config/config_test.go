@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func TestLoad_ShouldOverrideFileWithEnvironment(t *testing.T) {
+	path := writeConfig(t, `
+token: from-file
+cbr_service_url: http://cbr/
+host: db
+port: 5433
+budget_thresholds: [50]
+redis:
+  db: 1
+`)
+
+	service, err := load(path, false, env(map[string]string{
+		"BOT_TOKEN":             "from-env",
+		"BOT_PASSWORD":          "secret",
+		"BOT_BUDGET_THRESHOLDS": "80, 100",
+		"BOT_REDIS_PASSWORD":    "redis-secret",
+		"BOT_REDIS_TLS":         "true",
+		"BOT_CACHE_BACKEND":     "lru",
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", service.Token())
+	assert.Equal(t, "secret", service.Config.Password)
+	assert.Equal(t, 5433, service.GetPort())
+	assert.Equal(t, []int{80, 100}, service.GetBudgetThresholds())
+	assert.Equal(t, "redis-secret", service.GetRedis().Password)
+	assert.Equal(t, 1, service.GetRedis().DB)
+	assert.True(t, service.GetRedis().TLS)
+	assert.Equal(t, "lru", service.GetCacheBackend())
+}
+
+func TestLoad_ShouldFillDefaults(t *testing.T) {
+	path := writeConfig(t, "token: t\ncbr_service_url: http://cbr/\nhost: db\n")
+
+	service, err := load(path, false, env(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, service.GetUpdateRate())
+	assert.Equal(t, time.Hour, service.GetRecurringCheckRate())
+	assert.Equal(t, []string{"cbr"}, service.GetRateProviders())
+	assert.Equal(t, []int{80, 100}, service.GetBudgetThresholds())
+	assert.Equal(t, 5432, service.GetPort())
+	assert.Equal(t, "redis", service.GetCacheBackend())
+	assert.Equal(t, "db:6379", service.GetRedis().Address)
+}
+
+func TestLoad_ShouldRejectInvalidConfig(t *testing.T) {
+	path := writeConfig(t, `
+port: 70000
+frequency_currency_rate_update: -1
+cache:
+  backend: memcached
+`)
+
+	_, err := load(path, false, env(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token is required")
+	assert.Contains(t, err.Error(), "cbr_service_url is required")
+	assert.Contains(t, err.Error(), "port must be from 1 to 65535")
+	assert.Contains(t, err.Error(), "frequency_currency_rate_update must be positive")
+	assert.Contains(t, err.Error(), `unknown cache.backend "memcached"`)
+
+	_, err = load(path, false, env(map[string]string{"BOT_PORT": "many"}))
+	assert.ErrorContains(t, err, "incorrect BOT_PORT")
+}
+
+func TestLoad_ShouldRequireExplicitFile(t *testing.T) {
+	_, err := load(filepath.Join(t.TempDir(), "missing.yaml"), false, env(map[string]string{
+		"BOT_TOKEN":           "t",
+		"BOT_CBR_SERVICE_URL": "http://cbr/",
+	}))
+
+	assert.ErrorContains(t, err, "cannot ReadFile")
+}
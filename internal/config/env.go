@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnvPrefix starts the environment variables which override the config file:
+// BOT_TOKEN for token, BOT_REDIS_PASSWORD for password of the redis section.
+// Lists are separated by commas: BOT_BUDGET_THRESHOLDS=50,80,100.
+const EnvPrefix = "BOT_"
+
+func applyEnv(config *Config, lookupEnv func(string) (string, bool)) error {
+	return applyEnvToStruct(reflect.ValueOf(config).Elem(), EnvPrefix, lookupEnv)
+}
+
+func applyEnvToStruct(value reflect.Value, prefix string, lookupEnv func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		name := prefix + strings.ToUpper(strings.Split(field.Tag.Get("yaml"), ",")[0])
+
+		if field.Type.Kind() == reflect.Struct {
+			err := applyEnvToStruct(value.Field(i), name+"_", lookupEnv)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		raw, ok := lookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		err := setField(value.Field(i), raw)
+		if err != nil {
+			return errors.Wrapf(err, "incorrect %s", name)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.Wrap(err, "cannot Atoi")
+		}
+
+		field.SetInt(int64(value))
+	case reflect.Bool:
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.Wrap(err, "cannot ParseBool")
+		}
+
+		field.SetBool(value)
+	case reflect.Slice:
+		var parts []string
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setField(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+	default:
+		return errors.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// applyLive copies the settings which are safe to change without a restart.
+func applyLive(to *Config, from Config) {
+	to.CbrServiceUrl = from.CbrServiceUrl
+	to.EcbServiceUrl = from.EcbServiceUrl
+	to.ProviderMaxStalenessDays = from.ProviderMaxStalenessDays
+	to.FrequencyCurrencyRateUpdate = from.FrequencyCurrencyRateUpdate
+	to.FrequencyRecurringCheck = from.FrequencyRecurringCheck
+	to.BudgetThresholds = from.BudgetThresholds
+	to.DefaultLimit = from.DefaultLimit
+	to.LogLevel = from.LogLevel
+}
+
+// Reload reads the config again and applies the settings which can be changed live.
+// It returns the fields which changed but need a restart, they keep the old values.
+// An invalid config changes nothing, as well as a missing file: it may be replaced right now.
+func (s *Service) Reload(path string) ([]string, error) {
+	loaded, err := load(path, false, os.LookupEnv)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applied := s.Config
+	applyLive(&applied, loaded.Config)
+
+	rejected := changedFields(reflect.ValueOf(applied), reflect.ValueOf(loaded.Config), "")
+	s.Config = applied
+
+	return rejected, nil
+}
+
+// changedFields lists the yaml names of the fields which differ, redis.password for the nested ones.
+func changedFields(old, new reflect.Value, prefix string) []string {
+	var changed []string
+
+	for i := 0; i < old.NumField(); i++ {
+		name := prefix + strings.Split(old.Type().Field(i).Tag.Get("yaml"), ",")[0]
+
+		if old.Field(i).Kind() == reflect.Struct {
+			changed = append(changed, changedFields(old.Field(i), new.Field(i), name+".")...)
+			continue
+		}
+
+		if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
+
+// Watch calls Reload when the modification time or the size of the file changes,
+// checked every interval, and on every signal from reload (SIGHUP in the bot).
+// The result of every reload is passed to done.
+func (s *Service) Watch(ctx context.Context, path string, interval time.Duration, reload <-chan os.Signal,
+	done func(rejected []string, err error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		modified, size := fileVersion(path)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				modified, size = fileVersion(path)
+			case <-ticker.C:
+				newModified, newSize := fileVersion(path)
+				if newModified.Equal(modified) && newSize == size {
+					continue
+				}
+
+				modified, size = newModified, newSize
+			}
+
+			done(s.Reload(path))
+		}
+	}()
+}
+
+func fileVersion(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	return info.ModTime(), info.Size()
+}
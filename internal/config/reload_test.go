@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload_ShouldApplyOnlyLiveSettings(t *testing.T) {
+	path := writeConfig(t, "token: t\nhost: db\nfrequency_currency_rate_update: 60\ncbr_service_url: http://old/\n")
+
+	service, err := New(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("token: t\nhost: db2\nfrequency_currency_rate_update: 120\n"+
+		"cbr_service_url: http://new/\nlog_level: debug\nredis:\n  password: p\n"), 0o600))
+
+	rejected, err := service.Reload(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host", "redis.address", "redis.password"}, rejected)
+	assert.Equal(t, 2*time.Minute, service.GetUpdateRate())
+	assert.Equal(t, "http://new/", service.GetUrl())
+	assert.Equal(t, "debug", service.GetLogLevel().String())
+	assert.Equal(t, "db", service.GetHost())
+	assert.Equal(t, "", service.GetRedis().Password)
+
+	// An invalid config changes nothing.
+	require.NoError(t, os.WriteFile(path, []byte("token: t\nfrequency_currency_rate_update: -1\n"), 0o600))
+
+	_, err = service.Reload(path)
+
+	assert.Error(t, err)
+	assert.Equal(t, 2*time.Minute, service.GetUpdateRate())
+
+	// Neither does a file which is being replaced.
+	require.NoError(t, os.Remove(path))
+
+	_, err = service.Reload(path)
+
+	assert.ErrorContains(t, err, "cannot ReadFile")
+	assert.Equal(t, 2*time.Minute, service.GetUpdateRate())
+	assert.Equal(t, "http://new/", service.GetUrl())
+}
+
+func TestWatch_ShouldReloadOnSignal(t *testing.T) {
+	path := writeConfig(t, "token: t\ncbr_service_url: http://cbr/\nhost: db\nprovider_max_staleness_days: 4\n")
+
+	service, err := New(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reload := make(chan os.Signal)
+	done := make(chan error)
+
+	// The interval is long enough for the file change to be found by the signal only.
+	service.Watch(ctx, path, time.Hour, reload, func(_ []string, err error) {
+		done <- err
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("token: t\ncbr_service_url: http://cbr/\nhost: db\nprovider_max_staleness_days: 7\n"), 0o600))
+	reload <- os.Interrupt
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, 7, service.GetProviderMaxStaleness())
+}
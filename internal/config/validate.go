@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// Validate checks the config after the defaults are filled in and lists every problem found.
+func (c *Config) Validate() error {
+	var problems []string
+
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Token != "", "token is required, set token or %sTOKEN", EnvPrefix)
+	check(c.CbrServiceUrl != "", "cbr_service_url is required, set cbr_service_url or %sCBR_SERVICE_URL", EnvPrefix)
+	check(c.Port > 0 && c.Port <= 65535, "port must be from 1 to 65535, got %d", c.Port)
+	check(c.FrequencyCurrencyRateUpdate > 0, "frequency_currency_rate_update must be positive, got %d", c.FrequencyCurrencyRateUpdate)
+	check(c.FrequencyRecurringCheck > 0, "frequency_recurring_check must be positive, got %d", c.FrequencyRecurringCheck)
+	check(c.ProviderMaxStalenessDays > 0, "provider_max_staleness_days must be positive, got %d", c.ProviderMaxStalenessDays)
+
+	check(c.DefaultLimit > 0, "default_limit must be positive, got %d", c.DefaultLimit)
+
+	_, err := zapcore.ParseLevel(c.LogLevel)
+	check(err == nil, "unknown log_level %q, use debug, info, warn or error", c.LogLevel)
+
+	for _, threshold := range c.BudgetThresholds {
+		check(threshold > 0, "budget_thresholds must be positive percents, got %d", threshold)
+	}
+
+	for _, provider := range c.RateProviders {
+		check(provider == "cbr" || provider == "ecb", "unknown rate provider %q, use cbr or ecb", provider)
+	}
+
+	switch c.Cache.Backend {
+	case "redis":
+		check(c.Redis.DB >= 0, "redis.db must not be negative, got %d", c.Redis.DB)
+		check(c.Redis.MaxIdle > 0, "redis.max_idle must be positive, got %d", c.Redis.MaxIdle)
+		check(c.Redis.MaxActive >= 0, "redis.max_active must not be negative, got %d", c.Redis.MaxActive)
+		check(c.Redis.IdleTimeout > 0 && c.Redis.DialTimeout > 0 && c.Redis.ReadTimeout > 0 && c.Redis.WriteTimeout > 0,
+			"redis timeouts must be positive")
+		check(!strings.HasPrefix(c.Redis.Address, ":"), "redis.address or host is required for the redis cache")
+	case "lru":
+		check(c.Cache.Size > 0, "cache.size must be positive, got %d", c.Cache.Size)
+		check(c.Cache.TTL > 0, "cache.ttl must be positive, got %d", c.Cache.TTL)
+	case "none":
+	default:
+		check(false, "unknown cache.backend %q, use redis, lru or none", c.Cache.Backend)
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
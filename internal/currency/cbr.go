@@ -0,0 +1,114 @@
+package currency
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+	"golang.org/x/net/html/charset"
+)
+
+// CbrProvider reads the daily XML feed of the Central Bank of Russia, the rates are in roubles.
+type CbrProvider struct {
+	url    *providerURL // The date DD/MM/YYYY is appended to it.
+	client *http.Client
+}
+
+func NewCbrProvider(url string) *CbrProvider {
+	return &CbrProvider{
+		url:    &providerURL{url: url},
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+// SetURL changes the address of the provider, the rates being fetched now use the old one.
+func (p *CbrProvider) SetURL(url string) {
+	p.url.set(url)
+}
+
+func (p *CbrProvider) Name() string {
+	return "cbr"
+}
+
+func (p *CbrProvider) FetchRates(ctx context.Context, date time.Time) (*Rates, error) {
+	body, err := get(ctx, p.client, p.url.get()+date.Format("02/01/2006"))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get")
+	}
+
+	encoded := types.CurrenciesRate{}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	err = decoder.Decode(&encoded)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Decode")
+	}
+
+	rates := &Rates{
+		Base:  types.RUB,
+		Date:  date,
+		Rates: map[types.Currency]types.Rate{types.RUB: types.UnitRate},
+		Names: make(map[types.Currency]string),
+	}
+
+	// On weekends and holidays the CBR returns the rates of the last business day,
+	// they are saved with the date they were set for.
+	if setDate, err := time.Parse("02.01.2006", encoded.Date); err == nil {
+		rates.Date = setDate
+	}
+
+	for _, rate := range encoded.EncodedCurrencies {
+		value, err := types.ParseRate(rate.Value)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot ParseRate")
+		}
+
+		// Value is the price of Nominal units, e.g. 100 JPY.
+		nominal := rate.Nominal
+		if nominal <= 0 {
+			nominal = 1
+		}
+
+		currency := types.Currency(rate.CharCode)
+		rates.Rates[currency] = value.Div(nominal)
+		rates.Names[currency] = rate.Name
+	}
+
+	return rates, nil
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot NewRequestWithContext")
+	}
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Do")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot ReadAll")
+	}
+
+	return body, nil
+}
@@ -1,76 +1,125 @@
 package currency
 
 import (
-	"bytes"
 	"context"
-	"encoding/xml"
-	"io"
 	"log"
-	"net/http"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
-	"golang.org/x/net/html/charset"
 )
 
-const kopecksInRouble float64 = 100.0
-
 type config interface {
 	GetUrl() string
-	GetUpdateRate() time.Duration
+	GetEcbUrl() string
+	GetRateProviders() []string
+	GetProviderMaxStaleness() int
 }
 
 type ratesDB interface {
-	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (int, error)
+	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (types.Rate, error)
 	SetCurrencyRate(ctx context.Context, currency types.CurrencyRate) error
+	SetCurrency(ctx context.Context, currency types.CurrencyInfo) error
 }
 
-type CbrCurrencyUpdater struct {
-	CurrencyMtx sync.Mutex
-	config      config
-	ratesDB     ratesDB
+// NewProviders returns the rate providers in the order of priority set in the config.
+func NewProviders(config config) ([]Provider, error) {
+	providers := make([]Provider, 0, len(config.GetRateProviders()))
+
+	for _, name := range config.GetRateProviders() {
+		switch name {
+		case "cbr":
+			providers = append(providers, NewCbrProvider(config.GetUrl()))
+		case "ecb":
+			providers = append(providers, NewEcbProvider(config.GetEcbUrl()))
+		default:
+			return nil, errors.Errorf("unknown rate provider %q", name)
+		}
+	}
+
+	if len(providers) == 0 {
+		return nil, errors.New("no rate providers")
+	}
+
+	return providers, nil
 }
 
-func NewCbrCurrencyUpdater(config config, ratesDB ratesDB) *CbrCurrencyUpdater {
-	return &CbrCurrencyUpdater{
-		config:  config,
-		ratesDB: ratesDB,
+// UpdateProviderURLs gives the providers the addresses of the reloaded config.
+func UpdateProviderURLs(providers []Provider, config config) {
+	for _, provider := range providers {
+		switch provider := provider.(type) {
+		case *CbrProvider:
+			provider.SetURL(config.GetUrl())
+		case *EcbProvider:
+			provider.SetURL(config.GetEcbUrl())
+		}
 	}
 }
 
-func (c *CbrCurrencyUpdater) UpdateCurrencyRate(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
+// fetchTimeout limits one provider, so a hanging one leaves time for the next.
+const fetchTimeout = 3 * time.Second
 
-	log.Println("Updating currency rate", time.Now())
+// RateUpdater saves the rates of the first provider that has fresh ones, converted to roubles.
+type RateUpdater struct {
+	CurrencyMtx  sync.Mutex
+	config       config
+	ratesDB      ratesDB
+	providers    []Provider
+	fetchTimeout time.Duration
+}
+
+func NewRateUpdater(config config, ratesDB ratesDB, providers []Provider) *RateUpdater {
+	return &RateUpdater{
+		config:       config,
+		ratesDB:      ratesDB,
+		providers:    providers,
+		fetchTimeout: fetchTimeout,
+	}
+}
+
+func (c *RateUpdater) UpdateCurrencyRate(ctx context.Context) error {
+	return c.UpdateCurrencyRateOnDate(ctx, time.Now())
+}
+
+// UpdateCurrencyRateOnDate saves the rates of the date, used to convert expenses of past dates.
+// A provider that fails or has rates older than the allowed age gives way to the next one;
+// if all of them are stale, the freshest stale rates are saved.
+func (c *RateUpdater) UpdateCurrencyRateOnDate(ctx context.Context, date time.Time) error {
+	log.Println("Updating currency rate", date)
 	c.CurrencyMtx.Lock()
 	defer c.CurrencyMtx.Unlock()
 
-	date := time.Now().Format("02/01/2006")
-	url := c.config.GetUrl() + date
-	log.Println(url)
-	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	maxAge := time.Duration(c.config.GetProviderMaxStaleness()) * 24 * time.Hour
 
-	if err != nil {
-		return errors.Wrap(err, "cannot NewRequestWithContext")
-	}
+	var freshest *Rates
+	for _, provider := range c.providers {
+		rates, err := c.fetchRates(ctx, provider, date)
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+		if err != nil {
+			log.Println("Provider", provider.Name(), "failed:", err)
+			continue
+		}
 
-	if err != nil {
-		return errors.Wrap(err, "cannot Do")
+		if date.Sub(rates.Date) <= maxAge {
+			freshest = rates
+			break
+		}
+
+		log.Println("Provider", provider.Name(), "has stale rates of", rates.Date.Format("2006-01-02"))
+		if freshest == nil || rates.Date.After(freshest.Date) {
+			freshest = rates
+		}
+	}
+
+	if freshest == nil {
+		return errors.New("no provider returned rates")
 	}
-	defer response.Body.Close()
 
-	err = c.encode(ctx, response, string(types.RUB), time.Now())
+	err := c.save(ctx, freshest)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot encode")
+		return errors.Wrap(err, "cannot save")
 	}
 
 	log.Println("Finished updating currency rate", time.Now())
@@ -78,59 +127,64 @@ func (c *CbrCurrencyUpdater) UpdateCurrencyRate(ctx context.Context) error {
 	return nil
 }
 
-func (c *CbrCurrencyUpdater) Close() {
-	log.Println("Closing Cbr currency rate updater")
-}
+func (c *RateUpdater) fetchRates(ctx context.Context, provider Provider, date time.Time) (*Rates, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.fetchTimeout)
+	defer cancel()
 
-func (c *CbrCurrencyUpdater) encode(ctx context.Context, response *http.Response, base string, date time.Time) error {
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return errors.Wrap(err, "cannot ReadAll")
-	}
+	return provider.FetchRates(ctx, date)
+}
 
-	rates := types.CurrenciesRate{}
+func (c *RateUpdater) Close() {
+	log.Println("Closing currency rate updater")
+}
 
-	reader := bytes.NewReader(body)
-	decoder := xml.NewDecoder(reader)
-	decoder.CharsetReader = charset.NewReaderLabel
-	err = decoder.Decode(&rates)
+// save stores the rates in roubles per unit of the currency.
+func (c *RateUpdater) save(ctx context.Context, rates *Rates) error {
+	roublesPerBase, err := c.roublesPerBase(ctx, rates)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot Decode")
+		return errors.Wrap(err, "cannot roublesPerBase")
 	}
 
-	for _, rate := range rates.EncodedCurrencies {
-		switch rate.CharCode {
-		case string(types.USD), string(types.CNY), string(types.EUR):
-			value, err := getIntValueFromCommaFloat(rate.Value)
+	for currency, price := range rates.Rates {
+		if currency == types.RUB {
+			continue
+		}
 
-			if err != nil {
-				return errors.Wrap(err, "cannot getIntValueFromCommaFloat")
-			}
+		value := price.Mul(roublesPerBase)
+		if value == 0 {
+			log.Println("The rate is too small, skipping", currency)
+			continue
+		}
 
-			currency := types.CurrencyRate{
-				CharCode:     rate.CharCode,
-				Rate:         value,
-				BaseCurrency: base,
-				Date:         date,
-			}
+		if name := rates.Names[currency]; name != "" {
+			err = c.ratesDB.SetCurrency(ctx, types.CurrencyInfo{
+				Code: currency,
+				Name: name,
+			})
 
-			err = c.ratesDB.SetCurrencyRate(ctx, currency)
 			if err != nil {
-				return errors.Wrap(err, "cannot SetCurrencyState")
+				return errors.Wrap(err, "cannot SetCurrency")
 			}
 		}
-	}
 
-	if err != nil {
-		return errors.Wrap(err, "currency.encode")
+		err = c.ratesDB.SetCurrencyRate(ctx, types.CurrencyRate{
+			CharCode:     string(currency),
+			Rate:         value,
+			BaseCurrency: string(types.RUB),
+			Date:         rates.Date,
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "cannot SetCurrencyState")
+		}
 	}
 
 	err = c.ratesDB.SetCurrencyRate(ctx, types.CurrencyRate{
 		CharCode:     string(types.RUB),
-		Rate:         int(kopecksInRouble),
+		Rate:         types.UnitRate,
 		BaseCurrency: string(types.RUB),
-		Date:         date,
+		Date:         rates.Date,
 	})
 
 	if err != nil {
@@ -140,16 +194,22 @@ func (c *CbrCurrencyUpdater) encode(ctx context.Context, response *http.Response
 	return nil
 }
 
-func getIntValueFromCommaFloat(value string) (int, error) {
-	separated := strings.Split(value, ",")
+// roublesPerBase returns the price of the base currency of the rates in roubles.
+// When the rates have no rouble, the saved rate of the base currency is used for the cross rate.
+func (c *RateUpdater) roublesPerBase(ctx context.Context, rates *Rates) (types.Rate, error) {
+	if rates.Base == types.RUB {
+		return types.UnitRate, nil
+	}
+
+	if price, ok := rates.Rates[types.RUB]; ok && price > 0 {
+		return price.Inverse(), nil
+	}
 
-	var result int64
-	var err error
+	rate, err := c.ratesDB.GetCurrencyRate(ctx, rates.Base, rates.Date)
 
-	result, err = strconv.ParseInt(separated[0]+separated[1][:2], 10, 64)
 	if err != nil {
-		return 0, errors.Wrap(err, "cannot ParseInt")
+		return 0, errors.Wrap(err, "cannot GetCurrencyRate")
 	}
 
-	return int(result), nil
+	return rate, nil
 }
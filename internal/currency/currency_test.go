@@ -0,0 +1,190 @@
+package currency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const cbrFeed = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs Date="16.10.2026" name="Foreign Currency Market">
+<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal><Name>Dollar</Name><Value>90,5123</Value></Valute>
+<Valute ID="R01820"><NumCode>392</NumCode><CharCode>JPY</CharCode><Nominal>100</Nominal><Name>Yen</Name><Value>60,1234</Value></Valute>
+</ValCurs>`
+
+const ecbFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+<Cube><Cube time="2026-10-16"><Cube currency="USD" rate="1.25"/><Cube currency="GBP" rate="0.8"/></Cube></Cube>
+</gesmes:Envelope>`
+
+type testConfig struct{}
+
+func (testConfig) GetUrl() string               { return "" }
+func (testConfig) GetEcbUrl() string            { return "" }
+func (testConfig) GetRateProviders() []string   { return nil }
+func (testConfig) GetProviderMaxStaleness() int { return 4 }
+
+type testRatesDB struct {
+	rates      map[types.Currency]types.Rate
+	currencies map[types.Currency]string
+}
+
+func newTestRatesDB() *testRatesDB {
+	return &testRatesDB{
+		rates:      make(map[types.Currency]types.Rate),
+		currencies: make(map[types.Currency]string),
+	}
+}
+
+func (db *testRatesDB) GetCurrencyRate(_ context.Context, currency types.Currency, _ time.Time) (types.Rate, error) {
+	rate, ok := db.rates[currency]
+	if !ok {
+		return 0, types.ErrNoCurrency
+	}
+
+	return rate, nil
+}
+
+func (db *testRatesDB) SetCurrencyRate(_ context.Context, rate types.CurrencyRate) error {
+	db.rates[types.Currency(rate.CharCode)] = rate.Rate
+	return nil
+}
+
+func (db *testRatesDB) SetCurrency(_ context.Context, currency types.CurrencyInfo) error {
+	db.currencies[currency.Code] = currency.Name
+	return nil
+}
+
+func serve(t *testing.T, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func Test_UpdateCurrencyRateOnDate_Cbr(t *testing.T) {
+	server := serve(t, http.StatusOK, cbrFeed)
+	db := newTestRatesDB()
+	updater := NewRateUpdater(testConfig{}, db, []Provider{NewCbrProvider(server.URL + "/")})
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "90.5123", db.rates[types.USD].String())
+	assert.Equal(t, "0.601234", db.rates["JPY"].String())
+	assert.Equal(t, types.UnitRate, db.rates[types.RUB])
+	assert.Equal(t, "Yen", db.currencies["JPY"])
+}
+
+func Test_UpdateCurrencyRateOnDate_FallbackOnError(t *testing.T) {
+	broken := serve(t, http.StatusInternalServerError, "")
+	ecb := serve(t, http.StatusOK, ecbFeed)
+	db := newTestRatesDB()
+	// The rouble price of the euro saved earlier is used for the cross rate.
+	db.rates[types.EUR] = types.RateFromFloat(100)
+	updater := NewRateUpdater(testConfig{}, db, []Provider{NewCbrProvider(broken.URL + "/"), NewEcbProvider(ecb.URL)})
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "80", db.rates[types.USD].String())
+	assert.Equal(t, "125", db.rates["GBP"].String())
+	assert.Equal(t, "100", db.rates[types.EUR].String())
+}
+
+func Test_UpdateCurrencyRateOnDate_FallbackOnStale(t *testing.T) {
+	stale := serve(t, http.StatusOK, cbrFeed)
+	db := newTestRatesDB()
+	db.rates[types.EUR] = types.RateFromFloat(100)
+	fresh := &staticProvider{rates: &Rates{
+		Base: types.EUR,
+		Date: time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC),
+		Rates: map[types.Currency]types.Rate{
+			types.EUR: types.UnitRate,
+			types.RUB: types.RateFromFloat(0.0125),
+			types.USD: types.RateFromFloat(0.8),
+		},
+	}}
+	updater := NewRateUpdater(testConfig{}, db, []Provider{NewCbrProvider(stale.URL + "/"), fresh})
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	// The cross rate goes through the rouble of the same feed, not the saved euro rate.
+	assert.Equal(t, "64", db.rates[types.USD].String())
+}
+
+func Test_UpdateCurrencyRateOnDate_AllStale(t *testing.T) {
+	server := serve(t, http.StatusOK, cbrFeed)
+	db := newTestRatesDB()
+	updater := NewRateUpdater(testConfig{}, db, []Provider{NewCbrProvider(server.URL + "/")})
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "90.5123", db.rates[types.USD].String())
+}
+
+func Test_UpdateCurrencyRateOnDate_AllFailed(t *testing.T) {
+	server := serve(t, http.StatusInternalServerError, "")
+	db := newTestRatesDB()
+	updater := NewRateUpdater(testConfig{}, db, []Provider{NewCbrProvider(server.URL + "/"), NewEcbProvider(server.URL)})
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	assert.Error(t, err)
+	assert.Empty(t, db.rates)
+}
+
+func Test_UpdateCurrencyRateOnDate_FallbackOnTimeout(t *testing.T) {
+	ecb := serve(t, http.StatusOK, ecbFeed)
+	db := newTestRatesDB()
+	db.rates[types.EUR] = types.RateFromFloat(100)
+	updater := NewRateUpdater(testConfig{}, db, []Provider{blockingProvider{}, NewEcbProvider(ecb.URL)})
+	updater.fetchTimeout = 50 * time.Millisecond
+
+	err := updater.UpdateCurrencyRateOnDate(context.Background(), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "80", db.rates[types.USD].String())
+}
+
+func Test_EcbProvider_PastDate(t *testing.T) {
+	server := serve(t, http.StatusOK, ecbFeed)
+
+	_, err := NewEcbProvider(server.URL).FetchRates(context.Background(), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Error(t, err)
+}
+
+type staticProvider struct {
+	rates *Rates
+}
+
+func (p *staticProvider) Name() string {
+	return "static"
+}
+
+func (p *staticProvider) FetchRates(context.Context, time.Time) (*Rates, error) {
+	return p.rates, nil
+}
+
+// blockingProvider hangs until the request is cancelled.
+type blockingProvider struct{}
+
+func (blockingProvider) Name() string {
+	return "blocking"
+}
+
+func (blockingProvider) FetchRates(ctx context.Context, _ time.Time) (*Rates, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
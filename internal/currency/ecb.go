@@ -0,0 +1,97 @@
+package currency
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// EcbProvider reads the daily XML feed of the European Central Bank, the rates are in euros.
+// The feed has only the latest rates, so it cannot help with past dates.
+type EcbProvider struct {
+	url    *providerURL
+	client *http.Client
+}
+
+func NewEcbProvider(url string) *EcbProvider {
+	return &EcbProvider{
+		url:    &providerURL{url: url},
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+// SetURL changes the address of the provider, the rates being fetched now use the old one.
+func (p *EcbProvider) SetURL(url string) {
+	p.url.set(url)
+}
+
+func (p *EcbProvider) Name() string {
+	return "ecb"
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func (p *EcbProvider) FetchRates(ctx context.Context, date time.Time) (*Rates, error) {
+	body, err := get(ctx, p.client, p.url.get())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get")
+	}
+
+	envelope := ecbEnvelope{}
+	err = xml.Unmarshal(body, &envelope)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Unmarshal")
+	}
+
+	if len(envelope.Days) == 0 {
+		return nil, errors.New("no rates in the feed")
+	}
+
+	day := envelope.Days[0]
+	setDate, err := time.Parse("2006-01-02", day.Time)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Parse")
+	}
+
+	if setDate.After(date) {
+		return nil, errors.Errorf("the feed has no rates for %s", date.Format("2006-01-02"))
+	}
+
+	rates := &Rates{
+		Base:  types.EUR,
+		Date:  setDate,
+		Rates: map[types.Currency]types.Rate{types.EUR: types.UnitRate},
+	}
+
+	for _, rate := range day.Rates {
+		// The feed has units of the currency per euro.
+		value, err := types.ParseRate(rate.Rate)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot ParseRate")
+		}
+
+		if value == 0 {
+			continue
+		}
+
+		rates.Rates[types.Currency(rate.Currency)] = value.Inverse()
+	}
+
+	return rates, nil
+}
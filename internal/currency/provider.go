@@ -0,0 +1,44 @@
+package currency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// Rates are prices of one unit of the currencies in the base currency.
+type Rates struct {
+	Base  types.Currency
+	Date  time.Time // The date the rates were set for, may be before the requested one.
+	Rates map[types.Currency]types.Rate
+	Names map[types.Currency]string // Empty if the source has no names.
+}
+
+// Provider is a source of exchange rates.
+type Provider interface {
+	Name() string
+	// FetchRates returns the rates in effect on the date.
+	FetchRates(ctx context.Context, date time.Time) (*Rates, error)
+}
+
+// providerURL is the address of a provider which can be changed while the rates are fetched.
+type providerURL struct {
+	mu  sync.RWMutex
+	url string
+}
+
+func (u *providerURL) get() string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return u.url
+}
+
+func (u *providerURL) set(url string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.url = url
+}
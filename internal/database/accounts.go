@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type AccountsDB struct {
+	db *sql.DB
+}
+
+func NewAccountsDB(db *sql.DB) *AccountsDB {
+	return &AccountsDB{
+		db: db,
+	}
+}
+
+// CreateAccount returns false if the user already has an account with the name in any case.
+func (db *AccountsDB) CreateAccount(ctx context.Context, userID int64, account types.Account) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CreateAccount",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO accounts(
+			tg_user_id,
+			name,
+			currency,
+			opening_balance
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT(tg_user_id, lower(name))
+		DO NOTHING
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		account.Name,
+		account.Currency,
+		account.OpeningBalance,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected > 0, nil
+}
+
+func (db *AccountsDB) GetAccounts(ctx context.Context, userID int64) ([]types.Account, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetAccounts",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			account_id,
+			name,
+			currency,
+			opening_balance
+		FROM
+			accounts
+		WHERE
+			tg_user_id = $1
+		ORDER BY
+			account_id
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var accounts []types.Account
+
+	for rows.Next() {
+		var account types.Account
+
+		if err := rows.Scan(&account.ID, &account.Name, &account.Currency, &account.OpeningBalance); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// DeleteAccount deletes the account, its expenses stay without an account. An account with transfers
+// is kept for the balances of the other accounts, types.ErrAccountInUse is returned.
+func (db *AccountsDB) DeleteAccount(ctx context.Context, userID int64, name string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"DeleteAccount",
+	)
+	defer span.Finish()
+
+	const transfersQuery = `
+		SELECT
+			EXISTS(
+				SELECT
+					1
+				FROM
+					transfers t
+					JOIN accounts a ON a.account_id IN (t.from_account_id, t.to_account_id)
+				WHERE
+					a.tg_user_id = $1 AND
+					lower(a.name) = lower($2)
+			)
+	`
+
+	var hasTransfers bool
+
+	err := db.db.QueryRowContext(ctx, transfersQuery,
+		userID,
+		name,
+	).Scan(&hasTransfers)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	if hasTransfers {
+		return false, types.ErrAccountInUse
+	}
+
+	const query = `
+		DELETE FROM
+			accounts
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2)
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		name,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected > 0, nil
+}
+
+func (db *AccountsDB) CreateTransfer(ctx context.Context, userID int64, transfer types.Transfer) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CreateTransfer",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO transfers(
+			tg_user_id,
+			from_account_id,
+			to_account_id,
+			from_sum,
+			to_sum,
+			created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		transfer.FromAccountID,
+		transfer.ToAccountID,
+		transfer.FromSum,
+		transfer.ToSum,
+		transfer.Date,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// GetAccountExpenses returns the sums of the expenses and income of every account by the days
+// and the currencies they were entered in.
+func (db *AccountsDB) GetAccountExpenses(ctx context.Context, userID int64) ([]types.AccountExpenses, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetAccountExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			account_id,
+			kind,
+			created_at,
+			COALESCE(original_currency, 'RUB'),
+			SUM(COALESCE(original_sum, expense_sum)),
+			SUM(expense_sum)
+		FROM
+			expenses
+		WHERE
+			tg_user_id = $1 AND
+			account_id IS NOT NULL
+		GROUP BY
+			account_id,
+			kind,
+			created_at,
+			COALESCE(original_currency, 'RUB')
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var result []types.AccountExpenses
+
+	for rows.Next() {
+		var expenses types.AccountExpenses
+
+		if err := rows.Scan(&expenses.AccountID, &expenses.Kind, &expenses.Date, &expenses.Currency,
+			&expenses.OriginalSum, &expenses.Sum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		result = append(result, expenses)
+	}
+
+	return result, nil
+}
+
+// GetTransferSums returns what came to every account minus what left it, in hundredths of its currency.
+func (db *AccountsDB) GetTransferSums(ctx context.Context, userID int64) (map[int]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetTransferSums",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			account_id,
+			SUM(amount)
+		FROM (
+			SELECT to_account_id AS account_id, to_sum AS amount FROM transfers WHERE tg_user_id = $1
+			UNION ALL
+			SELECT from_account_id, -from_sum FROM transfers WHERE tg_user_id = $1
+		) AS moves
+		GROUP BY
+			account_id
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	sums := make(map[int]int)
+
+	for rows.Next() {
+		var accountID, sum int
+
+		if err := rows.Scan(&accountID, &sum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		sums[accountID] = sum
+	}
+
+	return sums, nil
+}
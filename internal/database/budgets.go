@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+type BudgetsDB struct {
+	db *sql.DB
+}
+
+func NewBudgetsDB(db *sql.DB) *BudgetsDB {
+	return &BudgetsDB{
+		db: db,
+	}
+}
+
+// SetBudget sets the budget of the category from the month of the date, zero limit removes it.
+func (db *BudgetsDB) SetBudget(ctx context.Context, userID int64, category string, date time.Time, limit int) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetBudget",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO budgets(
+			tg_user_id,
+			category,
+			since,
+			budget_limit
+		) VALUES (
+			$1, $2, DATE_TRUNC('month', $3::DATE), $4
+		)
+		ON CONFLICT(tg_user_id, category, since)
+		DO UPDATE
+		SET
+			budget_limit = $4
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		category,
+		date,
+		limit,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *BudgetsDB) GetBudget(ctx context.Context, userID int64, category string, date time.Time) (int, bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetBudget",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			budget_limit
+		FROM
+			budgets
+		WHERE
+			tg_user_id = $1 AND
+			category = $2 AND
+			since <= $3
+		ORDER BY
+			since DESC
+		LIMIT 1
+	`
+
+	var limit int
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+		category,
+		date,
+	).Scan(&limit)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+
+		return 0, false, errors.Wrap(err, "cannot Scan")
+	}
+
+	return limit, limit > 0, nil
+}
+
+// GetBudgets returns budgets of all categories for the month of the date.
+func (db *BudgetsDB) GetBudgets(ctx context.Context, userID int64, date time.Time) (map[string]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetBudgets",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT DISTINCT ON (category)
+			category,
+			budget_limit
+		FROM
+			budgets
+		WHERE
+			tg_user_id = $1 AND
+			since <= $2
+		ORDER BY
+			category,
+			since DESC
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+		date,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	budgets := make(map[string]int)
+
+	for rows.Next() {
+		var category string
+		var limit int
+
+		if err := rows.Scan(&category, &limit); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		if limit > 0 {
+			budgets[category] = limit
+		}
+	}
+
+	return budgets, nil
+}
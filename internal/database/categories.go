@@ -0,0 +1,299 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type CategoriesDB struct {
+	db *sql.DB
+}
+
+func NewCategoriesDB(db *sql.DB) *CategoriesDB {
+	return &CategoriesDB{
+		db: db,
+	}
+}
+
+// GetCategories returns the catalogue of the user, seeding the default categories for a new user.
+func (db *CategoriesDB) GetCategories(ctx context.Context, userID int64) ([]types.Category, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetCategories",
+	)
+	defer span.Finish()
+
+	categories, err := db.getCategories(ctx, userID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getCategories")
+	}
+
+	if len(categories) > 0 {
+		return categories, nil
+	}
+
+	for _, category := range types.DefaultCategories {
+		if err := db.insertCategory(ctx, userID, category); err != nil {
+			return nil, errors.Wrap(err, "cannot insertCategory")
+		}
+	}
+
+	return db.getCategories(ctx, userID)
+}
+
+func (db *CategoriesDB) getCategories(ctx context.Context, userID int64) ([]types.Category, error) {
+	const query = `
+		SELECT
+			category_id,
+			name,
+			emoji,
+			archived,
+			COALESCE(parent_id, 0)
+		FROM categories
+		WHERE
+			tg_user_id = $1
+		ORDER BY
+			category_id
+	`
+
+	rows, err := getExecutor(ctx, db.db).QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var categories []types.Category
+
+	for rows.Next() {
+		var category types.Category
+
+		if err := rows.Scan(&category.ID, &category.Name, &category.Emoji, &category.Archived, &category.ParentID); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func (db *CategoriesDB) insertCategory(ctx context.Context, userID int64, category types.Category) error {
+	const query = `
+		INSERT INTO categories(
+			tg_user_id,
+			name,
+			emoji
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT(tg_user_id, lower(name))
+		DO NOTHING
+	`
+
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		userID,
+		category.Name,
+		category.Emoji,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// AddCategory adds the category to the catalogue, nothing happens if it is already there.
+func (db *CategoriesDB) AddCategory(ctx context.Context, userID int64, name string) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"AddCategory",
+	)
+	defer span.Finish()
+
+	return db.insertCategory(ctx, userID, types.Category{Name: name})
+}
+
+// SetCategoryEmoji returns false if the user has no such category.
+func (db *CategoriesDB) SetCategoryEmoji(ctx context.Context, userID int64, name, emoji string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetCategoryEmoji",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE categories
+		SET
+			emoji = $3
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2)
+	`
+
+	return db.updateCategory(ctx, query, userID, name, emoji)
+}
+
+// SetCategoryArchived returns false if the user has no such category.
+func (db *CategoriesDB) SetCategoryArchived(ctx context.Context, userID int64, name string, archived bool) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetCategoryArchived",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE categories
+		SET
+			archived = $3
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2)
+	`
+
+	return db.updateCategory(ctx, query, userID, name, archived)
+}
+
+// SetCategoryParent makes the category a subcategory of the parent, an empty parent makes it a top level one.
+// It returns false if the user has no such category.
+func (db *CategoriesDB) SetCategoryParent(ctx context.Context, userID int64, name, parent string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetCategoryParent",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE categories
+		SET
+			parent_id = (
+				SELECT
+					p.category_id
+				FROM categories AS p
+				WHERE
+					p.tg_user_id = $1 AND
+					lower(p.name) = lower($3)
+			)
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2)
+	`
+
+	return db.updateCategory(ctx, query, userID, name, parent)
+}
+
+// RenameCategory renames the category in the catalogue and everywhere it is used.
+// It returns false if the user has no such category.
+func (db *CategoriesDB) RenameCategory(ctx context.Context, userID int64, name, newName string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"RenameCategory",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE categories
+		SET
+			name = $3
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2)
+	`
+
+	ok, err := db.updateCategory(ctx, query, userID, name, newName)
+
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	return true, db.moveCategory(ctx, userID, name, newName)
+}
+
+// MergeCategory moves everything of the category to the other one and removes it from the catalogue.
+// Its subcategories become top level ones. It returns false if the user has no such category.
+func (db *CategoriesDB) MergeCategory(ctx context.Context, userID int64, name, into string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"MergeCategory",
+	)
+	defer span.Finish()
+
+	const query = `
+		DELETE FROM categories
+		WHERE
+			tg_user_id = $1 AND
+			lower(name) = lower($2) AND
+			lower(name) <> lower($3)
+	`
+
+	ok, err := db.updateCategory(ctx, query, userID, name, into)
+
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	return true, db.moveCategory(ctx, userID, name, into)
+}
+
+func (db *CategoriesDB) updateCategory(ctx context.Context, query string, userID int64, name string, value interface{}) (bool, error) {
+	result, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		userID,
+		name,
+		value,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected > 0, nil
+}
+
+// moveCategory rewrites the category in the expenses, budgets, recurring expenses, import rules
+// and the history filter. A budget of the month the target category already has is kept.
+func (db *CategoriesDB) moveCategory(ctx context.Context, userID int64, name, newName string) error {
+	queries := []string{
+		`UPDATE expenses SET category = $3 WHERE tg_user_id = $1 AND lower(category) = lower($2)`,
+		`UPDATE recurring_expenses SET category = $3 WHERE tg_user_id = $1 AND lower(category) = lower($2)`,
+		`UPDATE category_rules SET category = $3 WHERE tg_user_id = $1 AND lower(category) = lower($2)`,
+		`UPDATE users SET history_category = $3 WHERE tg_user_id = $1 AND lower(history_category) = lower($2)`,
+		`DELETE FROM budgets AS b
+		USING budgets AS o
+		WHERE
+			b.tg_user_id = $1 AND
+			o.tg_user_id = $1 AND
+			lower(b.category) = lower($2) AND
+			o.category = $3 AND
+			o.since = b.since AND
+			b.category <> o.category`,
+		`UPDATE budgets SET category = $3 WHERE tg_user_id = $1 AND lower(category) = lower($2)`,
+	}
+
+	for _, query := range queries {
+		_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+			userID,
+			name,
+			newName,
+		)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot ExecContent")
+		}
+	}
+
+	return nil
+}
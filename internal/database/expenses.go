@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/gob"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -16,11 +17,21 @@ import (
 type CacheModel interface {
 	Ping() error
 	Get(key string) ([]byte, error)
-	Set(key string, value []byte) error
+	Set(key string, value []byte, ttl time.Duration) error
 	Exists(key string) (bool, error)
 	Delete(key string) error
 }
 
+const (
+	// reportTTL bounds the life of a cached report even if an invalidation is lost.
+	reportTTL = 24 * time.Hour
+	// reportsVersionTTL outlives the reports. A version which has expired or was evicted
+	// is replaced by a new one, so the reports cached under it are not read again.
+	reportsVersionTTL = 7 * 24 * time.Hour
+	// reportRowsKind is the cache key part of GetReportRows, next to the category levels of GetReport.
+	reportRowsKind = "rows"
+)
+
 type expensesDB struct {
 	db    *sql.DB
 	cache CacheModel
@@ -46,25 +57,167 @@ func (db *expensesDB) WriteExpense(ctx context.Context, fromID int64, expense *t
 			expense_id,
 			expense_sum,
 			category,
-			created_at
+			created_at,
+			original_sum,
+			original_currency,
+			account_id,
+			kind,
+			paid_by
 		) values (
-			$1, $2, $3, $4, $5
+			$1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), $9, NULLIF($10, 0)
 		);
 	`
 
-	_, err := db.db.ExecContext(ctx, query,
+	originalSum, originalCurrency := expense.OriginalSum, expense.OriginalCurrency
+	if originalCurrency == "" {
+		originalSum, originalCurrency = expense.Sum, types.RUB
+	}
+
+	kind := expense.Kind
+	if kind == "" {
+		kind = types.KindExpense
+	}
+
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
 		fromID,
 		expense.ExpenseID,
 		expense.Sum,
 		expense.Category,
 		expense.Date,
+		originalSum,
+		originalCurrency,
+		expense.AccountID,
+		kind,
+		expense.PaidBy,
 	)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot ExecContent")
 	}
 
-	return nil
+	return db.invalidateReports(ctx, fromID)
+}
+
+// NextExpenseID returns an id for an expense which has no expense card message.
+func (db *expensesDB) NextExpenseID(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"NextExpenseID",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT -nextval('generated_expense_id_seq')
+	`
+
+	var id int
+	err := getExecutor(ctx, db.db).QueryRowContext(ctx, query).Scan(&id)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return id, nil
+}
+
+// GetReportRows returns sums of the expenses and income by day, category and the currency they were entered in.
+// Unlike GetReport it keeps the days and currencies, so the report can convert each day at its own rate,
+// it is cached and invalidated the same way.
+func (db *expensesDB) GetReportRows(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) ([]types.ReportRow, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetReportRows",
+	)
+	defer span.Finish()
+
+	var report []types.ReportRow
+	ok, err := db.getCachedReport(userID, reportRowsKind, dateBegin, dateEnd, &report)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getCachedReport")
+	}
+
+	if ok {
+		return report, nil
+	}
+
+	const query = `
+		SELECT
+			kind,
+			created_at,
+			category,
+			COALESCE(original_currency, 'RUB'),
+			SUM(expense_sum),
+			SUM(COALESCE(original_sum, expense_sum))
+		FROM expenses
+		WHERE
+			tg_user_id = $1 AND
+			(created_at BETWEEN $2 AND $3)
+		GROUP BY
+			kind,
+			created_at,
+			category,
+			COALESCE(original_currency, 'RUB')
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+		dateBegin,
+		dateEnd,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row types.ReportRow
+
+		if err := rows.Scan(&row.Kind, &row.Date, &row.Category, &row.OriginalCurrency, &row.Sum, &row.OriginalSum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		report = append(report, row)
+	}
+
+	err = db.cacheReport(userID, reportRowsKind, dateBegin, dateEnd, report)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot cacheReport")
+	}
+
+	return report, nil
+}
+
+// CountSameExpenses counts expenses with the same date and sum, used to find duplicates on import.
+func (db *expensesDB) CountSameExpenses(ctx context.Context, userID int64, date time.Time, sum int) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CountSameExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			COUNT(*)
+		FROM expenses
+		WHERE
+			tg_user_id = $1 AND
+			created_at = $2 AND
+			expense_sum = $3
+	`
+
+	var count int
+	err := getExecutor(ctx, db.db).QueryRowContext(ctx, query,
+		userID,
+		date,
+		sum,
+	).Scan(&count)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return count, nil
 }
 
 func (db *expensesDB) GetExpense(ctx context.Context, userID int64, expenseID int) (*types.Expense, error) {
@@ -78,10 +231,21 @@ func (db *expensesDB) GetExpense(ctx context.Context, userID int64, expenseID in
 		SELECT 
 			expense_sum,
 			category,
-			created_at
+			created_at,
+			COALESCE(original_sum, expense_sum),
+			COALESCE(original_currency, 'RUB'),
+			COALESCE(expenses.account_id, 0),
+			COALESCE(accounts.name, ''),
+			kind,
+			COALESCE(paid_by, 0),
+			COALESCE(group_members.name, '')
 		FROM expenses 
+		LEFT JOIN accounts USING (account_id)
+		LEFT JOIN group_members ON
+			group_members.chat_id = expenses.tg_user_id AND
+			group_members.tg_user_id = expenses.paid_by
 		WHERE 
-			tg_user_id = $1 AND expense_id = $2
+			expenses.tg_user_id = $1 AND expense_id = $2
 	`
 
 	expense := types.NewExpense()
@@ -89,7 +253,8 @@ func (db *expensesDB) GetExpense(ctx context.Context, userID int64, expenseID in
 	err := db.db.QueryRowContext(ctx, query,
 		userID,
 		expenseID,
-	).Scan(&expense.Sum, &expense.Category, &expense.Date)
+	).Scan(&expense.Sum, &expense.Category, &expense.Date, &expense.OriginalSum, &expense.OriginalCurrency,
+		&expense.AccountID, &expense.Account, &expense.Kind, &expense.PaidBy, &expense.PaidByName)
 
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -117,7 +282,7 @@ func (db *expensesDB) DeleteExpense(ctx context.Context, userID int64, expenseID
 			tg_user_id = $1 AND
 			expense_id = $2
 	`
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
 		userID,
 		expenseID,
 	)
@@ -126,7 +291,7 @@ func (db *expensesDB) DeleteExpense(ctx context.Context, userID int64, expenseID
 		return errors.Wrap(err, "cannot ExecContent")
 	}
 
-	return nil
+	return db.invalidateReports(ctx, userID)
 }
 
 func (db *expensesDB) EditNewExpense(ctx context.Context, userID int64, expenseID int, expense *types.Expense) error {
@@ -148,7 +313,7 @@ func (db *expensesDB) EditNewExpense(ctx context.Context, userID int64, expenseI
 			expense_id = $5
 	`
 
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
 		expense.Sum,
 		expense.Category,
 		expense.Date,
@@ -160,41 +325,50 @@ func (db *expensesDB) EditNewExpense(ctx context.Context, userID int64, expenseI
 		return errors.Wrap(err, "cannot ExecContent")
 	}
 
-	return nil
+	return db.invalidateReports(ctx, userID)
 }
 
-func (db *expensesDB) GetReport(ctx context.Context, fromID int64, dateBegin time.Time, dateEnd time.Time) (map[string]int, error) {
+// GetReport returns the expenses of the period by the categories of the level.
+func (db *expensesDB) GetReport(ctx context.Context, fromID int64, dateBegin time.Time, dateEnd time.Time,
+	level types.CategoryLevel) (map[string]int, error) {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
 		"GetReport",
 	)
 	defer span.Finish()
 
-	report, err := db.getCachedReport(fromID, dateBegin, dateEnd)
+	var report map[string]int
+	ok, err := db.getCachedReport(fromID, string(level), dateBegin, dateEnd, &report)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot db.getCachedExpense")
 	}
 
-	if report != nil {
+	if ok {
 		return report, nil
 	}
 
 	const query = `
-		SELECT 
-			SUM(expense_sum),
-			category
-		FROM expenses
-		WHERE 
-			tg_user_id = $1 AND
-			(created_at BETWEEN $2 AND $3)
+		SELECT
+			SUM(e.expense_sum),
+			CASE WHEN $4 = 'parent' THEN COALESCE(p.name, e.category) ELSE e.category END AS report_category
+		FROM expenses AS e
+		LEFT JOIN categories AS c
+			ON c.tg_user_id = e.tg_user_id AND c.name = e.category
+		LEFT JOIN categories AS p
+			ON p.category_id = c.parent_id
+		WHERE
+			e.tg_user_id = $1 AND
+			e.kind = 'expense' AND
+			(e.created_at BETWEEN $2 AND $3)
 		GROUP BY
-			category
+			report_category
 	`
 
 	rows, err := db.db.QueryContext(ctx, query,
 		fromID,
 		dateBegin,
 		dateEnd,
+		level,
 	)
 
 	if err != nil {
@@ -215,7 +389,7 @@ func (db *expensesDB) GetReport(ctx context.Context, fromID int64, dateBegin tim
 		report[name] = sum
 	}
 
-	err = db.cacheReport(fromID, dateBegin, dateEnd, report)
+	err = db.cacheReport(fromID, string(level), dateBegin, dateEnd, report)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot cacheReport")
 	}
@@ -223,10 +397,61 @@ func (db *expensesDB) GetReport(ctx context.Context, fromID int64, dateBegin tim
 	return report, nil
 }
 
-func (db *expensesDB) WriteSum(ctx context.Context, sum int, userID int64, expenseID int) error {
+// GetMemberReport returns the expenses of the period by the members who paid them, in kopecks.
+func (db *expensesDB) GetMemberReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) (map[int64]int, error) {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
-		"WriteSum",
+		"GetMemberReport",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			COALESCE(paid_by, 0),
+			SUM(expense_sum)
+		FROM expenses
+		WHERE
+			tg_user_id = $1 AND
+			kind = 'expense' AND
+			(created_at BETWEEN $2 AND $3)
+		GROUP BY
+			COALESCE(paid_by, 0)
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+		dateBegin,
+		dateEnd,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	report := make(map[int64]int)
+
+	for rows.Next() {
+		var (
+			memberID int64
+			sum      int
+		)
+
+		if err := rows.Scan(&memberID, &sum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		report[memberID] = sum
+	}
+
+	return report, nil
+}
+
+// WriteAccount sets the account the expense was paid from, zero clears it.
+func (db *expensesDB) WriteAccount(ctx context.Context, accountID int, userID int64, expenseID int) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"WriteAccount",
 	)
 	defer span.Finish()
 
@@ -234,14 +459,14 @@ func (db *expensesDB) WriteSum(ctx context.Context, sum int, userID int64, expen
 		UPDATE 
 			expenses
 		SET
-			expense_sum = $1
+			account_id = NULLIF($1, 0)
 		WHERE
 			tg_user_id = $2 AND
 			expense_id = $3
 	`
 
-	_, err := db.db.ExecContext(ctx, query,
-		sum,
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		accountID,
 		userID,
 		expenseID,
 	)
@@ -253,6 +478,41 @@ func (db *expensesDB) WriteSum(ctx context.Context, sum int, userID int64, expen
 	return nil
 }
 
+// WriteSum writes the sum in kopecks and the amount as it was entered.
+func (db *expensesDB) WriteSum(ctx context.Context, sum, originalSum int, originalCurrency types.Currency, userID int64, expenseID int) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"WriteSum",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE 
+			expenses
+		SET
+			expense_sum = $1,
+			original_sum = $2,
+			original_currency = $3
+		WHERE
+			tg_user_id = $4 AND
+			expense_id = $5
+	`
+
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		sum,
+		originalSum,
+		originalCurrency,
+		userID,
+		expenseID,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return db.invalidateReports(ctx, userID)
+}
+
 func (db *expensesDB) WriteCategory(ctx context.Context, category string, userID int64, expenseID int) error {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
@@ -270,7 +530,7 @@ func (db *expensesDB) WriteCategory(ctx context.Context, category string, userID
 			expense_id = $3
 	`
 
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
 		category,
 		userID,
 		expenseID,
@@ -280,7 +540,7 @@ func (db *expensesDB) WriteCategory(ctx context.Context, category string, userID
 		return errors.Wrap(err, "cannot ExecContent")
 	}
 
-	return nil
+	return db.invalidateReports(ctx, userID)
 }
 
 func (db *expensesDB) WriteDate(ctx context.Context, date time.Time, userID int64, expenseID int) error {
@@ -300,7 +560,7 @@ func (db *expensesDB) WriteDate(ctx context.Context, date time.Time, userID int6
 			expense_id = $3
 	`
 
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
 		date,
 		userID,
 		expenseID,
@@ -310,7 +570,7 @@ func (db *expensesDB) WriteDate(ctx context.Context, date time.Time, userID int6
 		return errors.Wrap(err, "cannot ExecContent")
 	}
 
-	return nil
+	return db.invalidateReports(ctx, userID)
 }
 
 func (db *expensesDB) GetMonthReport(ctx context.Context, userID int64, date time.Time) (int, error) {
@@ -322,10 +582,11 @@ func (db *expensesDB) GetMonthReport(ctx context.Context, userID int64, date tim
 
 	const query = `
 		SELECT 
-			SUM(expense_sum)
+			COALESCE(SUM(expense_sum), 0)
 		FROM expenses
 		WHERE 
 			tg_user_id = $1 AND
+			kind = 'expense' AND
 			EXTRACT(YEAR FROM created_at) = EXTRACT(YEAR FROM DATE($2)) AND
 			EXTRACT(MONTH FROM created_at) = EXTRACT(MONTH FROM DATE($2))			
 	`
@@ -347,16 +608,438 @@ func (db *expensesDB) GetMonthReport(ctx context.Context, userID int64, date tim
 	return sum, nil
 }
 
-func (db *expensesDB) cacheReport(userID int64, dateBegin time.Time, dateEnd time.Time, report map[string]int) error {
+// GetCategoryMonthReport returns the expenses of the category and its subcategories in the month of the date.
+func (db *expensesDB) GetCategoryMonthReport(ctx context.Context, userID int64, category string, date time.Time) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetCategoryMonthReport",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			COALESCE(SUM(expense_sum), 0)
+		FROM expenses
+		WHERE
+			tg_user_id = $1 AND
+			kind = 'expense' AND
+			(
+				category = $2 OR
+				category IN (
+					SELECT
+						c.name
+					FROM categories AS c
+					JOIN categories AS p
+						ON p.category_id = c.parent_id
+					WHERE
+						p.tg_user_id = $1 AND
+						p.name = $2
+				)
+			) AND
+			DATE_TRUNC('month', created_at) = DATE_TRUNC('month', $3::DATE)
+	`
+
+	var sum int
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+		category,
+		date,
+	).Scan(&sum)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return sum, nil
+}
+
+// GetExpenses returns a page of the expenses of the filter, the category of the filter includes its subcategories.
+func (db *expensesDB) GetExpenses(ctx context.Context, userID int64, filter types.ExpenseFilter, limit, offset int) ([]types.Expense, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			e.expense_id,
+			e.expense_sum,
+			e.category,
+			e.created_at,
+			COALESCE(e.original_sum, e.expense_sum),
+			COALESCE(e.original_currency, 'RUB'),
+			e.kind
+		FROM expenses AS e
+		LEFT JOIN categories AS c
+			ON c.tg_user_id = e.tg_user_id AND c.name = e.category
+		LEFT JOIN categories AS p
+			ON p.category_id = c.parent_id
+		WHERE
+			e.tg_user_id = $1 AND
+			($2 = '' OR e.category = $2 OR p.name = $2) AND
+			(e.created_at BETWEEN $3 AND $4)
+		ORDER BY
+			e.created_at DESC,
+			e.expense_id DESC
+		LIMIT $5
+		OFFSET $6
+	`
+
+	dateBegin, dateEnd := getFilterDates(filter)
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+		filter.Category,
+		dateBegin,
+		dateEnd,
+		limit,
+		offset,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var expenses []types.Expense
+
+	for rows.Next() {
+		var expense types.Expense
+
+		if err := rows.Scan(&expense.ExpenseID, &expense.Sum, &expense.Category, &expense.Date,
+			&expense.OriginalSum, &expense.OriginalCurrency, &expense.Kind); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		expenses = append(expenses, expense)
+	}
+
+	return expenses, nil
+}
+
+// ExportExpenses calls fn for every expense and income of the period in date order without loading them all at once.
+func (db *expensesDB) ExportExpenses(ctx context.Context, userID int64, dateBegin, dateEnd time.Time, fn func(expense *types.Expense) error) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"ExportExpenses",
+	)
+	defer span.Finish()
+
+	// The rate is looked up the same way as when the expense was written.
+	const query = `
+		SELECT
+			e.expense_id,
+			e.expense_sum,
+			e.category,
+			e.created_at,
+			e.kind,
+			COALESCE(e.original_sum, e.expense_sum),
+			COALESCE(e.original_currency, 'RUB'),
+			r.rate
+		FROM expenses e
+		LEFT JOIN LATERAL (
+			SELECT
+				rate
+			FROM
+				currency_rate
+			WHERE
+				char_code = e.original_currency AND
+				base = 'RUB' AND
+				rate_date BETWEEN e.created_at::DATE - $4::INTEGER AND e.created_at::DATE
+			ORDER BY
+				rate_date DESC
+			LIMIT 1
+		) r ON true
+		WHERE
+			e.tg_user_id = $1 AND
+			(e.created_at BETWEEN $2 AND $3)
+		ORDER BY
+			e.created_at,
+			e.expense_id
+	`
+
+	dateBegin, dateEnd = getFilterDates(types.ExpenseFilter{DateBegin: dateBegin, DateEnd: dateEnd})
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+		dateBegin,
+		dateEnd,
+		types.MaxRateAgeDays,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			expense types.Expense
+			rate    sql.NullString
+		)
+
+		err := rows.Scan(&expense.ExpenseID, &expense.Sum, &expense.Category, &expense.Date, &expense.Kind,
+			&expense.OriginalSum, &expense.OriginalCurrency, &rate)
+		if err != nil {
+			return errors.Wrap(err, "cannot Scan")
+		}
+
+		expense.OriginalRate, err = originalRate(&expense, rate)
+		if err != nil {
+			return errors.Wrap(err, "cannot originalRate")
+		}
+
+		if err := fn(&expense); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(rows.Err(), "cannot Next")
+}
+
+// originalRate returns the rate the expense was converted at. The saved rates may be gone,
+// then it is restored from the sums.
+func originalRate(expense *types.Expense, rate sql.NullString) (types.Rate, error) {
+	switch {
+	case expense.OriginalCurrency == types.RUB:
+		return types.UnitRate, nil
+	case rate.Valid:
+		return types.ParseRate(rate.String)
+	case expense.OriginalSum == 0:
+		return 0, nil
+	default:
+		return types.RateFromFloat(float64(expense.Sum) / float64(expense.OriginalSum)), nil
+	}
+}
+
+func (db *expensesDB) CountExpenses(ctx context.Context, userID int64, filter types.ExpenseFilter) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CountExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			COUNT(*)
+		FROM expenses AS e
+		LEFT JOIN categories AS c
+			ON c.tg_user_id = e.tg_user_id AND c.name = e.category
+		LEFT JOIN categories AS p
+			ON p.category_id = c.parent_id
+		WHERE
+			e.tg_user_id = $1 AND
+			($2 = '' OR e.category = $2 OR p.name = $2) AND
+			(e.created_at BETWEEN $3 AND $4)
+	`
+
+	dateBegin, dateEnd := getFilterDates(filter)
+
+	var count int
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+		filter.Category,
+		dateBegin,
+		dateEnd,
+	).Scan(&count)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return count, nil
+}
+
+// ChangeExpenseID moves the expense to another message, e.g. when it is reopened from the history.
+func (db *expensesDB) ChangeExpenseID(ctx context.Context, userID int64, expenseID, newExpenseID int) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"ChangeExpenseID",
+	)
+	defer span.Finish()
+
+	// The split of the expense moves with it.
+	const query = `
+		WITH moved_splits AS (
+			UPDATE
+				expense_splits
+			SET
+				expense_id = $3
+			WHERE
+				tg_user_id = $1 AND
+				expense_id = $2
+		)
+		UPDATE
+			expenses
+		SET
+			expense_id = $3
+		WHERE
+			tg_user_id = $1 AND
+			expense_id = $2
+	`
+
+	_, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		userID,
+		expenseID,
+		newExpenseID,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// MarkIncomeCard remembers that the card was sent by /income, so it is written as an income when first edited.
+func (db *expensesDB) MarkIncomeCard(ctx context.Context, userID int64, cardID int) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"MarkIncomeCard",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO income_cards(
+			tg_user_id,
+			card_id
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		cardID,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// IsIncomeCard tells if the card was sent by /income.
+func (db *expensesDB) IsIncomeCard(ctx context.Context, userID int64, cardID int) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"IsIncomeCard",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			EXISTS(
+				SELECT
+					1
+				FROM
+					income_cards
+				WHERE
+					tg_user_id = $1 AND
+					card_id = $2
+			)
+	`
+
+	var income bool
+
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+		cardID,
+	).Scan(&income)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return income, nil
+}
+
+func getFilterDates(filter types.ExpenseFilter) (time.Time, time.Time) {
+	dateBegin, dateEnd := filter.DateBegin, filter.DateEnd
+
+	if dateBegin.IsZero() {
+		dateBegin = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	if dateEnd.IsZero() {
+		dateEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	return dateBegin, dateEnd
+}
+
+// InvalidateReports makes the cached reports of the user stale: their keys carry
+// the version of the user's reports and the version changes.
+func (db *expensesDB) InvalidateReports(ctx context.Context, userID int64) error {
+	span, _ := opentracing.StartSpanFromContext(
+		ctx,
+		"InvalidateReports",
+	)
+	defer span.Finish()
+
+	_, err := db.newReportsVersion(userID)
+	return err
+}
+
+// newReportsVersion sets a version of the user's reports which has not been used before.
+func (db *expensesDB) newReportsVersion(userID int64) (string, error) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	err := db.cache.Set(getReportsVersionKey(userID), []byte(version), reportsVersionTTL)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot cache.Set")
+	}
+
+	return version, nil
+}
+
+// invalidateReports is called after every change of the expenses the reports are made of.
+// In a transaction the version changes once it is committed: a report read before that
+// would be cached under the new version with the old expenses.
+func (db *expensesDB) invalidateReports(ctx context.Context, userID int64) error {
+	return afterCommit(ctx, func() error {
+		return errors.Wrap(db.InvalidateReports(ctx, userID), "cannot InvalidateReports")
+	})
+}
+
+func (db *expensesDB) reportsVersion(userID int64) (string, error) {
+	ok, err := db.cache.Exists(getReportsVersionKey(userID))
+	if err != nil {
+		return "", errors.Wrap(err, "cannot cache.Exists")
+	}
+
+	if !ok {
+		version, err := db.newReportsVersion(userID)
+		return version, errors.Wrap(err, "cannot newReportsVersion")
+	}
+
+	version, err := db.cache.Get(getReportsVersionKey(userID))
+	if err != nil {
+		return "", errors.Wrap(err, "cannot cache.Get")
+	}
+
+	return string(version), nil
+}
+
+// cacheReport keeps the report of the kind ("leaf", "parent" or "rows") under the current reports version.
+func (db *expensesDB) cacheReport(userID int64, kind string, dateBegin time.Time, dateEnd time.Time,
+	report interface{}) error {
+	version, err := db.reportsVersion(userID)
+	if err != nil {
+		return errors.Wrap(err, "cannot reportsVersion")
+	}
+
 	var buffer bytes.Buffer
 	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(report)
+	err = encoder.Encode(report)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot Encode")
 	}
 
-	err = db.cache.Set(getReportKeyString(userID, dateBegin, dateEnd), buffer.Bytes())
+	err = db.cache.Set(getReportKeyString(userID, version, kind, dateBegin, dateEnd), buffer.Bytes(), reportTTL)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot cache.Set")
@@ -365,39 +1048,44 @@ func (db *expensesDB) cacheReport(userID int64, dateBegin time.Time, dateEnd tim
 	return nil
 }
 
-func (db *expensesDB) getCachedReport(userID int64, dateBegin time.Time, dateEnd time.Time) (map[string]int, error) {
-	ok, err := db.cache.Exists(getReportKeyString(userID, dateBegin, dateEnd))
+// getCachedReport decodes the cached report into the pointer, it returns false if there is none.
+func (db *expensesDB) getCachedReport(userID int64, kind string, dateBegin time.Time,
+	dateEnd time.Time, report interface{}) (bool, error) {
+	version, err := db.reportsVersion(userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot cache.Exists")
+		return false, errors.Wrap(err, "cannot reportsVersion")
 	}
 
-	if ok {
-		obj, err := db.cache.Get(getReportKeyString(userID, dateBegin, dateEnd))
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot cache.Get")
-		}
+	key := getReportKeyString(userID, version, kind, dateBegin, dateEnd)
 
-		var buffer bytes.Buffer
-		_, err = buffer.Write(obj)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot buffer.Write")
-		}
+	ok, err := db.cache.Exists(key)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot cache.Exists")
+	}
 
-		dec := gob.NewDecoder(&buffer)
+	if !ok {
+		return false, nil
+	}
 
-		var report map[string]int
-		err = dec.Decode(&report)
+	obj, err := db.cache.Get(key)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot cache.Get")
+	}
 
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot dec.Decode")
-		}
+	err = gob.NewDecoder(bytes.NewReader(obj)).Decode(report)
 
-		return report, nil
+	if err != nil {
+		return false, errors.Wrap(err, "cannot dec.Decode")
 	}
 
-	return nil, nil
+	return true, nil
+}
+
+func getReportKeyString(userID int64, version string, kind string, dateBegin time.Time, dateEnd time.Time) string {
+	return fmt.Sprintf("%d_%s_%s_{%s}_{%s}", userID, version, kind,
+		dateBegin.Format("2006-01-02"), dateEnd.Format("2006-01-02"))
 }
 
-func getReportKeyString(userID int64, dateBegin time.Time, dateEnd time.Time) string {
-	return fmt.Sprintf("%d_{%s}_{%s}", userID, dateBegin.Format("2006-01-02"), dateEnd.Format("2006-01-02"))
+func getReportsVersionKey(userID int64) string {
+	return fmt.Sprintf("%d_reports_version", userID)
 }
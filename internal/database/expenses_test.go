@@ -0,0 +1,352 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// memoryCache is the CacheModel kept in a map, it remembers the ttl of every key.
+type memoryCache struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		values: make(map[string][]byte),
+		ttls:   make(map[string]time.Duration),
+	}
+}
+
+func (c *memoryCache) Ping() error {
+	return nil
+}
+
+func (c *memoryCache) Get(key string) ([]byte, error) {
+	return c.values[key], nil
+}
+
+func (c *memoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *memoryCache) Exists(key string) (bool, error) {
+	_, ok := c.values[key]
+	return ok, nil
+}
+
+func (c *memoryCache) Delete(key string) error {
+	delete(c.values, key)
+	delete(c.ttls, key)
+	return nil
+}
+
+// reportDriver is the database that answers every query with one report row and counts the report queries.
+type reportDriver struct {
+	mu      sync.Mutex
+	queries int
+}
+
+var testDriver = &reportDriver{}
+
+func init() {
+	sql.Register("report_test", testDriver)
+}
+
+func (d *reportDriver) Open(string) (driver.Conn, error) {
+	return reportConn{d}, nil
+}
+
+func (d *reportDriver) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.queries
+}
+
+type reportConn struct {
+	driver *reportDriver
+}
+
+func (c reportConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c reportConn) Close() error {
+	return nil
+}
+
+func (c reportConn) Begin() (driver.Tx, error) {
+	return reportTx{}, nil
+}
+
+type reportTx struct{}
+
+func (reportTx) Commit() error {
+	return nil
+}
+
+func (reportTx) Rollback() error {
+	return nil
+}
+
+func (c reportConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c reportConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	byDay := strings.Contains(query, "SUM(COALESCE(original_sum, expense_sum))")
+
+	if byDay || strings.Contains(query, "report_category") {
+		c.driver.mu.Lock()
+		c.driver.queries++
+		c.driver.mu.Unlock()
+	}
+
+	return &reportRows{byDay: byDay}, nil
+}
+
+type reportRows struct {
+	byDay bool // The rows of GetReportRows rather than GetReport.
+	done  bool
+}
+
+func (r *reportRows) Columns() []string {
+	if r.byDay {
+		return []string{"kind", "created_at", "category", "currency", "sum", "original_sum"}
+	}
+
+	return []string{"sum", "report_category"}
+}
+
+func (r *reportRows) Close() error {
+	return nil
+}
+
+func (r *reportRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+
+	if r.byDay {
+		dest[0], dest[1], dest[2] = "expense", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), "Кафе"
+		dest[3], dest[4], dest[5] = "RUB", int64(50000), int64(50000)
+		return nil
+	}
+
+	dest[0], dest[1] = int64(50000), "Кафе"
+	return nil
+}
+
+func Test_ExpenseChanges_ShouldInvalidateCachedReports(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	const userID = int64(123)
+	dateBegin := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dateEnd := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	mutations := map[string]func(ctx context.Context, expenses *expensesDB) error{
+		"WriteExpense": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.WriteExpense(ctx, userID, &types.Expense{ExpenseID: 1, Sum: 100, Category: "Кафе"})
+		},
+		"WriteSum": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.WriteSum(ctx, 100, 100, types.RUB, userID, 1)
+		},
+		"WriteCategory": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.WriteCategory(ctx, "Такси", userID, 1)
+		},
+		"WriteDate": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.WriteDate(ctx, dateBegin, userID, 1)
+		},
+		"EditNewExpense": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.EditNewExpense(ctx, userID, 1, &types.Expense{Sum: 100, Category: "Кафе", Date: dateBegin})
+		},
+		"DeleteExpense": func(ctx context.Context, expenses *expensesDB) error {
+			return expenses.DeleteExpense(ctx, userID, 1)
+		},
+	}
+
+	for name, mutate := range mutations {
+		mutate := mutate
+
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := newMemoryCache()
+			expenses := NewExpensesDB(db, cache)
+
+			for _, level := range []types.CategoryLevel{types.CategoryLevelLeaf, types.CategoryLevelParent} {
+				_, err := expenses.GetReport(ctx, userID, dateBegin, dateEnd, level)
+				require.NoError(t, err)
+			}
+			_, err := expenses.GetReportRows(ctx, userID, dateBegin, dateEnd)
+			require.NoError(t, err)
+
+			queries := testDriver.count()
+
+			// The reports of both levels and the rows of the main report are cached now.
+			for _, level := range []types.CategoryLevel{types.CategoryLevelLeaf, types.CategoryLevelParent} {
+				report, err := expenses.GetReport(ctx, userID, dateBegin, dateEnd, level)
+				require.NoError(t, err)
+				assert.Equal(t, map[string]int{"Кафе": 50000}, report)
+			}
+			rows, err := expenses.GetReportRows(ctx, userID, dateBegin, dateEnd)
+			require.NoError(t, err)
+			assert.Equal(t, []types.ReportRow{{
+				Kind:             types.KindExpense,
+				Date:             dateBegin,
+				Category:         "Кафе",
+				Sum:              50000,
+				OriginalSum:      50000,
+				OriginalCurrency: types.RUB,
+			}}, rows)
+			assert.Equal(t, queries, testDriver.count())
+
+			require.NoError(t, mutate(ctx, expenses))
+
+			for _, level := range []types.CategoryLevel{types.CategoryLevelLeaf, types.CategoryLevelParent} {
+				_, err := expenses.GetReport(ctx, userID, dateBegin, dateEnd, level)
+				require.NoError(t, err)
+			}
+			_, err = expenses.GetReportRows(ctx, userID, dateBegin, dateEnd)
+			require.NoError(t, err)
+			assert.Equal(t, queries+3, testDriver.count())
+
+			for key, ttl := range cache.ttls {
+				assert.Positive(t, ttl, key)
+			}
+		})
+	}
+}
+
+func Test_InvalidateReports_ShouldKeepOtherUsersReports(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	dateBegin := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dateEnd := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	expenses := NewExpensesDB(db, newMemoryCache())
+
+	_, err = expenses.GetReport(ctx, 1, dateBegin, dateEnd, types.CategoryLevelLeaf)
+	require.NoError(t, err)
+
+	queries := testDriver.count()
+
+	require.NoError(t, expenses.DeleteExpense(ctx, 2, 1))
+
+	_, err = expenses.GetReport(ctx, 1, dateBegin, dateEnd, types.CategoryLevelLeaf)
+	require.NoError(t, err)
+	assert.Equal(t, queries, testDriver.count())
+}
+
+func Test_WriteExpenseInTx_ShouldInvalidateReportsAfterCommit(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := newMemoryCache()
+	expenses := NewExpensesDB(db, cache)
+	txManager := NewTxManager(db)
+	versionKey := getReportsVersionKey(123)
+
+	require.NoError(t, expenses.InvalidateReports(ctx, 123))
+	version := string(cache.values[versionKey])
+
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		err := expenses.WriteExpense(ctx, 123, &types.Expense{ExpenseID: 1, Sum: 100, Category: "Кафе"})
+		require.NoError(t, err)
+
+		// A report read now is of the expenses before the commit, it is cached under the old version.
+		assert.Equal(t, version, string(cache.values[versionKey]))
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, version, string(cache.values[versionKey]))
+}
+
+func Test_RolledBackTx_ShouldNotInvalidateReports(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := newMemoryCache()
+	expenses := NewExpensesDB(db, cache)
+	versionKey := getReportsVersionKey(123)
+
+	require.NoError(t, expenses.InvalidateReports(ctx, 123))
+	version := string(cache.values[versionKey])
+
+	err = NewTxManager(db).RunInTx(ctx, func(ctx context.Context) error {
+		err := expenses.WriteExpense(ctx, 123, &types.Expense{ExpenseID: 1, Sum: 100, Category: "Кафе"})
+		require.NoError(t, err)
+
+		return errors.New("duplicate")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, version, string(cache.values[versionKey]))
+}
+
+func Test_FailedAfterCommitHook_ShouldNotFailCommittedTx(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ran := false
+	err = NewTxManager(db).RunInTx(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, afterCommit(ctx, func() error { return errors.New("cache is down") }))
+		require.NoError(t, afterCommit(ctx, func() error {
+			ran = true
+			return nil
+		}))
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+}
+
+func Test_EvictedReportsVersion_ShouldNotServeOldReports(t *testing.T) {
+	db, err := sql.Open("report_test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	dateBegin := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dateEnd := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	cache := newMemoryCache()
+	expenses := NewExpensesDB(db, cache)
+
+	_, err = expenses.GetReport(ctx, 123, dateBegin, dateEnd, types.CategoryLevelLeaf)
+	require.NoError(t, err)
+
+	require.NoError(t, expenses.InvalidateReports(ctx, 123))
+	require.NoError(t, cache.Delete(getReportsVersionKey(123)))
+
+	queries := testDriver.count()
+
+	_, err = expenses.GetReport(ctx, 123, dateBegin, dateEnd, types.CategoryLevelLeaf)
+	require.NoError(t, err)
+	assert.Equal(t, queries+1, testDriver.count())
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type GroupsDB struct {
+	db *sql.DB
+}
+
+func NewGroupsDB(db *sql.DB) *GroupsDB {
+	return &GroupsDB{
+		db: db,
+	}
+}
+
+// AddMember remembers the member of the group chat with the current name, creating the ledger of the chat if needed.
+func (db *GroupsDB) AddMember(ctx context.Context, chatID int64, member types.Member) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"AddMember",
+	)
+	defer span.Finish()
+
+	const ledgerQuery = `
+		INSERT INTO users(
+			tg_user_id
+		) VALUES (
+			$1
+		)
+		ON CONFLICT(tg_user_id)
+		DO NOTHING
+	`
+
+	_, err := db.db.ExecContext(ctx, ledgerQuery,
+		chatID,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	const query = `
+		INSERT INTO group_members(
+			chat_id,
+			tg_user_id,
+			name
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT(chat_id, tg_user_id)
+		DO UPDATE
+		SET
+			name = $3
+	`
+
+	_, err = db.db.ExecContext(ctx, query,
+		chatID,
+		member.ID,
+		member.Name,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *GroupsDB) GetMembers(ctx context.Context, chatID int64) ([]types.Member, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetMembers",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			tg_user_id,
+			name
+		FROM
+			group_members
+		WHERE
+			chat_id = $1
+		ORDER BY
+			name
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		chatID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var members []types.Member
+
+	for rows.Next() {
+		var member types.Member
+
+		if err := rows.Scan(&member.ID, &member.Name); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
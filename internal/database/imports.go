@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/statement"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type ImportsDB struct {
+	db *sql.DB
+}
+
+func NewImportsDB(db *sql.DB) *ImportsDB {
+	return &ImportsDB{
+		db: db,
+	}
+}
+
+// GetImportProfile returns the saved column mapping or the default one.
+func (db *ImportsDB) GetImportProfile(ctx context.Context, userID int64) (statement.Profile, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetImportProfile",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			profile
+		FROM
+			import_profiles
+		WHERE
+			tg_user_id = $1
+	`
+
+	var data []byte
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+	).Scan(&data)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return statement.DefaultProfile(), nil
+		}
+
+		return statement.Profile{}, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	profile := statement.DefaultProfile()
+	err = json.Unmarshal(data, &profile)
+
+	if err != nil {
+		return statement.Profile{}, errors.Wrap(err, "cannot Unmarshal")
+	}
+
+	return profile, nil
+}
+
+func (db *ImportsDB) SetImportProfile(ctx context.Context, userID int64, profile statement.Profile) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetImportProfile",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO import_profiles(
+			tg_user_id,
+			profile
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT(tg_user_id)
+		DO UPDATE
+		SET
+			profile = $2
+	`
+
+	data, err := json.Marshal(profile)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Marshal")
+	}
+
+	_, err = db.db.ExecContext(ctx, query,
+		userID,
+		data,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// GetCategoryRules returns the rules with the longest, most specific, patterns first.
+func (db *ImportsDB) GetCategoryRules(ctx context.Context, userID int64) ([]statement.Rule, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetCategoryRules",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			pattern,
+			category
+		FROM
+			category_rules
+		WHERE
+			tg_user_id = $1
+		ORDER BY
+			LENGTH(pattern) DESC,
+			pattern
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var rules []statement.Rule
+
+	for rows.Next() {
+		var rule statement.Rule
+
+		if err := rows.Scan(&rule.Pattern, &rule.Category); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (db *ImportsDB) SetCategoryRule(ctx context.Context, userID int64, rule statement.Rule) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetCategoryRule",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO category_rules(
+			tg_user_id,
+			pattern,
+			category
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT(tg_user_id, pattern)
+		DO UPDATE
+		SET
+			category = $3
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		rule.Pattern,
+		rule.Category,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *ImportsDB) DeleteCategoryRule(ctx context.Context, userID int64, pattern string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"DeleteCategoryRule",
+	)
+	defer span.Finish()
+
+	const query = `
+		DELETE FROM
+			category_rules
+		WHERE
+			tg_user_id = $1 AND
+			pattern = $2
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		pattern,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected > 0, nil
+}
+
+func (db *ImportsDB) CreatePendingImport(ctx context.Context, userID int64, expenses []types.Expense) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CreatePendingImport",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO pending_imports(
+			tg_user_id,
+			expenses
+		) VALUES (
+			$1, $2
+		)
+		RETURNING import_id
+	`
+
+	data, err := json.Marshal(expenses)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot Marshal")
+	}
+
+	var id int
+	err = db.db.QueryRowContext(ctx, query,
+		userID,
+		data,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return id, nil
+}
+
+// TakePendingImport deletes the pending import and returns its expenses, nil if it does not exist.
+func (db *ImportsDB) TakePendingImport(ctx context.Context, userID int64, importID int) ([]types.Expense, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"TakePendingImport",
+	)
+	defer span.Finish()
+
+	const query = `
+		DELETE FROM
+			pending_imports
+		WHERE
+			tg_user_id = $1 AND
+			import_id = $2
+		RETURNING expenses
+	`
+
+	var data []byte
+	err := getExecutor(ctx, db.db).QueryRowContext(ctx, query,
+		userID,
+		importID,
+	).Scan(&data)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	var expenses []types.Expense
+	err = json.Unmarshal(data, &expenses)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Unmarshal")
+	}
+
+	return expenses, nil
+}
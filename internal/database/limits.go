@@ -2,12 +2,16 @@ package database
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
+// Year and month of the default limit which applies to months without their own limit.
+const defaultLimitYear, defaultLimitMonth = 0, 0
+
 type LimitsDB struct {
 	db *sql.DB
 }
@@ -18,7 +22,8 @@ func NewLimitsDB(db *sql.DB) *LimitsDB {
 	}
 }
 
-func (db *LimitsDB) GetLimit(ctx context.Context, userID int64, monthNo int) (int, bool, error) {
+// GetLimit returns the limit of the month of the date, or the default limit if the month has none.
+func (db *LimitsDB) GetLimit(ctx context.Context, userID int64, date time.Time) (int, bool, error) {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
 		"GetLimit",
@@ -31,14 +36,22 @@ func (db *LimitsDB) GetLimit(ctx context.Context, userID int64, monthNo int) (in
 		FROM 
 			limits
 		WHERE
-			tg_user_id = $1 AND
-			month_no = $2
+			tg_user_id = $1 AND (
+				(limit_year = $2 AND limit_month = $3) OR
+				(limit_year = $4 AND limit_month = $5)
+			)
+		ORDER BY
+			limit_year DESC
+		LIMIT 1
 	`
 
 	var limit int
 	err := db.db.QueryRowContext(ctx, query,
 		userID,
-		monthNo,
+		date.Year(),
+		int(date.Month()),
+		defaultLimitYear,
+		defaultLimitMonth,
 	).Scan(&limit)
 
 	if err != nil {
@@ -52,56 +65,33 @@ func (db *LimitsDB) GetLimit(ctx context.Context, userID int64, monthNo int) (in
 	return limit, true, nil
 }
 
-func (db *LimitsDB) SetLimit(ctx context.Context, userID int64, monthNo, limit int) error {
+func (db *LimitsDB) SetLimit(ctx context.Context, userID int64, year, month, limit int) error {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
 		"SetLimit",
 	)
 	defer span.Finish()
 
-	_, ok, err := db.GetLimit(ctx, userID, monthNo)
-
-	if err != nil {
-		return errors.Wrap(err, "cannot GetLimit")
-	}
-
-	if ok {
-		const query = `
-			UPDATE  limits
-			SET
-				user_limit = $2
-			WHERE
-				tg_user_id = $1,
-				month_no = $3
-		`
-
-		_, err := db.db.ExecContext(ctx, query,
-			userID,
-			limit,
-			monthNo,
-		)
-
-		if err != nil {
-			return errors.Wrap(err, "cannot ExecContent")
-		}
-
-		return nil
-	}
-
 	const query = `
 		INSERT INTO limits(
 			tg_user_id,
 			user_limit,
-			month_no
+			limit_year,
+			limit_month
 		) values (
-			$1, $2, $3
+			$1, $2, $3, $4
 		)
+		ON CONFLICT(tg_user_id, limit_year, limit_month)
+		DO UPDATE
+		SET
+			user_limit = $2
 	`
 
-	_, err = db.db.ExecContext(ctx, query,
+	_, err := db.db.ExecContext(ctx, query,
 		userID,
 		limit,
-		monthNo,
+		year,
+		month,
 	)
 
 	if err != nil {
@@ -110,3 +100,8 @@ func (db *LimitsDB) SetLimit(ctx context.Context, userID int64, monthNo, limit i
 
 	return nil
 }
+
+// SetDefaultLimit sets the limit for all months without their own limit.
+func (db *LimitsDB) SetDefaultLimit(ctx context.Context, userID int64, limit int) error {
+	return db.SetLimit(ctx, userID, defaultLimitYear, defaultLimitMonth, limit)
+}
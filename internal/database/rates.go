@@ -36,16 +36,15 @@ func (db *ratesDB) SetCurrencyRate(ctx context.Context, currency types.CurrencyR
 		) values (
 			$1, $2, $3, $4
 		)
-		ON CONFLICT(char_code)
+		ON CONFLICT(char_code, base, rate_date)
 		DO UPDATE 
 		SET
-			rate = $3,
-			rate_date = $4
+			rate = $3
 	`
 	_, err := db.db.ExecContext(ctx, query,
 		currency.CharCode,
 		currency.BaseCurrency,
-		currency.Rate,
+		currency.Rate.Decimal(),
 		currency.Date,
 	)
 
@@ -56,7 +55,9 @@ func (db *ratesDB) SetCurrencyRate(ctx context.Context, currency types.CurrencyR
 	return nil
 }
 
-func (db *ratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (int, error) {
+// GetCurrencyRate returns the rate in effect on the date: the CBR does not set rates on weekends
+// and holidays, so the rate of the most recent prior business day is used then.
+func (db *ratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (types.Rate, error) {
 	span, ctx := opentracing.StartSpanFromContext(
 		ctx,
 		"GetCurrencyRate",
@@ -70,12 +71,18 @@ func (db *ratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency,
 			currency_rate
 		WHERE
 			char_code = $1 AND
-			rate_date = $2
+			base = $2 AND
+			rate_date BETWEEN $3::DATE - $4::INTEGER AND $3::DATE
+		ORDER BY
+			rate_date DESC
+		LIMIT 1
 	`
-	var rate int
+	var rate string
 	err := db.db.QueryRowContext(ctx, query,
 		currency,
+		types.RUB,
 		date,
+		types.MaxRateAgeDays,
 	).Scan(&rate)
 
 	if err != nil {
@@ -86,5 +93,139 @@ func (db *ratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency,
 		}
 	}
 
-	return rate, nil
+	result, err := types.ParseRate(rate)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot ParseRate")
+	}
+
+	return result, nil
+}
+
+// GetCurrencyRates returns the rates of the currency by the dates the CBR set them,
+// including the ones set before the period which may still be in effect on its first days.
+func (db *ratesDB) GetCurrencyRates(ctx context.Context, currency types.Currency, dateBegin, dateEnd time.Time) (map[time.Time]types.Rate, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetCurrencyRates",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			rate_date,
+			rate
+		FROM
+			currency_rate
+		WHERE
+			char_code = $1 AND
+			base = $2 AND
+			(rate_date BETWEEN $3::DATE - $4::INTEGER AND $5)
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		currency,
+		types.RUB,
+		dateBegin,
+		types.MaxRateAgeDays,
+		dateEnd,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	rates := make(map[time.Time]types.Rate)
+
+	for rows.Next() {
+		var (
+			date time.Time
+			rate string
+		)
+
+		if err := rows.Scan(&date, &rate); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		rates[date], err = types.ParseRate(rate)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot ParseRate")
+		}
+	}
+
+	return rates, nil
+}
+
+// SetCurrency saves the currency published by the CBR, so users can choose it.
+func (db *ratesDB) SetCurrency(ctx context.Context, currency types.CurrencyInfo) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetCurrency",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO currencies(
+			char_code,
+			name
+		) values (
+			$1, $2
+		)
+		ON CONFLICT(char_code)
+		DO UPDATE
+		SET
+			name = $2
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		currency.Code,
+		currency.Name,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *ratesDB) GetCurrencies(ctx context.Context) ([]types.CurrencyInfo, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetCurrencies",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			char_code,
+			name
+		FROM
+			currencies
+		ORDER BY
+			char_code
+	`
+
+	rows, err := db.db.QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var currencies []types.CurrencyInfo
+
+	for rows.Next() {
+		var currency types.CurrencyInfo
+
+		if err := rows.Scan(&currency.Code, &currency.Name); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, nil
 }
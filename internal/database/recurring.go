@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type RecurringDB struct {
+	db *sql.DB
+}
+
+func NewRecurringDB(db *sql.DB) *RecurringDB {
+	return &RecurringDB{
+		db: db,
+	}
+}
+
+func (db *RecurringDB) CreateRecurringExpense(ctx context.Context, userID int64, recurring *types.RecurringExpense) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CreateRecurringExpense",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO recurring_expenses(
+			tg_user_id,
+			expense_sum,
+			category,
+			period,
+			day_of_month,
+			next_date
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		RETURNING recurring_id
+	`
+
+	var id int
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+		recurring.Sum,
+		recurring.Category,
+		recurring.Period,
+		recurring.DayOfMonth,
+		recurring.NextDate,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return id, nil
+}
+
+func (db *RecurringDB) GetRecurringExpenses(ctx context.Context, userID int64) ([]types.RecurringExpense, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetRecurringExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			recurring_id,
+			tg_user_id,
+			expense_sum,
+			category,
+			period,
+			day_of_month,
+			next_date,
+			paused
+		FROM recurring_expenses
+		WHERE
+			tg_user_id = $1
+		ORDER BY
+			recurring_id
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		userID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	return scanRecurringExpenses(rows)
+}
+
+// GetDueRecurringExpenses returns active templates which should have been written by the date.
+func (db *RecurringDB) GetDueRecurringExpenses(ctx context.Context, date time.Time) ([]types.RecurringExpense, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetDueRecurringExpenses",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			recurring_id,
+			tg_user_id,
+			expense_sum,
+			category,
+			period,
+			day_of_month,
+			next_date,
+			paused
+		FROM recurring_expenses
+		WHERE
+			next_date <= $1 AND
+			NOT paused
+	`
+
+	rows, err := db.db.QueryContext(ctx, query,
+		date,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	return scanRecurringExpenses(rows)
+}
+
+// AdvanceRecurringExpense moves the template from the occurrence to the next one.
+// It returns false if the occurrence was already handled by someone else.
+func (db *RecurringDB) AdvanceRecurringExpense(ctx context.Context, id int, date, nextDate time.Time) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"AdvanceRecurringExpense",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE
+			recurring_expenses
+		SET
+			next_date = $3
+		WHERE
+			recurring_id = $1 AND
+			next_date = $2 AND
+			NOT paused
+	`
+
+	result, err := getExecutor(ctx, db.db).ExecContext(ctx, query,
+		id,
+		date,
+		nextDate,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected == 1, nil
+}
+
+func (db *RecurringDB) SetRecurringExpensePaused(ctx context.Context, userID int64, id int, paused bool) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetRecurringExpensePaused",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE
+			recurring_expenses
+		SET
+			paused = $3
+		WHERE
+			tg_user_id = $1 AND
+			recurring_id = $2
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		id,
+		paused,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected == 1, nil
+}
+
+// ResumeRecurringExpense unpauses the template and moves it to the next date in the same update,
+// so the occurrences missed while it was paused are not written.
+func (db *RecurringDB) ResumeRecurringExpense(ctx context.Context, userID int64, id int, nextDate time.Time) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"ResumeRecurringExpense",
+	)
+	defer span.Finish()
+
+	const query = `
+		UPDATE
+			recurring_expenses
+		SET
+			paused = false,
+			next_date = $3
+		WHERE
+			tg_user_id = $1 AND
+			recurring_id = $2 AND
+			paused
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		id,
+		nextDate,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected == 1, nil
+}
+
+func (db *RecurringDB) DeleteRecurringExpense(ctx context.Context, userID int64, id int) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"DeleteRecurringExpense",
+	)
+	defer span.Finish()
+
+	const query = `
+		DELETE FROM
+			recurring_expenses
+		WHERE
+			tg_user_id = $1 AND
+			recurring_id = $2
+	`
+
+	result, err := db.db.ExecContext(ctx, query,
+		userID,
+		id,
+	)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot ExecContent")
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot RowsAffected")
+	}
+
+	return affected == 1, nil
+}
+
+func scanRecurringExpenses(rows *sql.Rows) ([]types.RecurringExpense, error) {
+	var result []types.RecurringExpense
+
+	for rows.Next() {
+		var recurring types.RecurringExpense
+
+		err := rows.Scan(
+			&recurring.ID,
+			&recurring.UserID,
+			&recurring.Sum,
+			&recurring.Category,
+			&recurring.Period,
+			&recurring.DayOfMonth,
+			&recurring.NextDate,
+			&recurring.Paused,
+		)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		result = append(result, recurring)
+	}
+
+	return result, nil
+}
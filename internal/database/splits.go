@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type SplitsDB struct {
+	db *sql.DB
+}
+
+func NewSplitsDB(db *sql.DB) *SplitsDB {
+	return &SplitsDB{
+		db: db,
+	}
+}
+
+// WriteSplits replaces the split of the expense among the members. The shares are kept as the
+// proportions of the split, GetBalances allocates the current sum of the expense by them.
+func (db *SplitsDB) WriteSplits(ctx context.Context, chatID int64, expenseID int, shares []types.SplitShare) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"WriteSplits",
+	)
+	defer span.Finish()
+
+	executor := getExecutor(ctx, db.db)
+
+	const deleteQuery = `
+		DELETE FROM expense_splits
+		WHERE
+			tg_user_id = $1 AND
+			expense_id = $2
+	`
+
+	_, err := executor.ExecContext(ctx, deleteQuery,
+		chatID,
+		expenseID,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	const query = `
+		INSERT INTO expense_splits(
+			tg_user_id,
+			expense_id,
+			member_id,
+			share_sum
+		) VALUES (
+			$1, $2, $3, $4
+		)
+	`
+
+	for _, share := range shares {
+		_, err = executor.ExecContext(ctx, query,
+			chatID,
+			expenseID,
+			share.MemberID,
+			share.Sum,
+		)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot ExecContent")
+		}
+	}
+
+	return nil
+}
+
+// CreateSettlement writes that one member gave the sum to another.
+func (db *SplitsDB) CreateSettlement(ctx context.Context, chatID int64, debt types.Debt, date time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"CreateSettlement",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO settlements(
+			tg_user_id,
+			from_member_id,
+			to_member_id,
+			settlement_sum,
+			created_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		chatID,
+		debt.From,
+		debt.To,
+		debt.Sum,
+		date,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+// GetBalances returns what the members are owed (positive) or owe (negative) after the splits
+// and settlements, in kopecks. The shares are allocated from the current sum of the expense,
+// so they follow it when the sum is edited after the split.
+func (db *SplitsDB) GetBalances(ctx context.Context, chatID int64) (map[int64]int, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetBalances",
+	)
+	defer span.Finish()
+
+	const splitsQuery = `
+		SELECT
+			s.expense_id,
+			e.expense_sum,
+			e.paid_by,
+			s.member_id,
+			s.share_sum
+		FROM expense_splits AS s
+		JOIN expenses AS e
+			ON e.tg_user_id = s.tg_user_id AND e.expense_id = s.expense_id
+		WHERE
+			s.tg_user_id = $1 AND
+			e.paid_by IS NOT NULL
+		ORDER BY
+			s.expense_id,
+			s.member_id
+	`
+
+	rows, err := db.db.QueryContext(ctx, splitsQuery,
+		chatID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	var splits []expenseSplit
+
+	for rows.Next() {
+		var (
+			expenseID int
+			split     expenseSplit
+			share     types.SplitShare
+		)
+
+		if err := rows.Scan(&expenseID, &split.Sum, &split.PaidBy, &share.MemberID, &share.Sum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		if len(splits) == 0 || splits[len(splits)-1].ExpenseID != expenseID {
+			split.ExpenseID = expenseID
+			splits = append(splits, split)
+		}
+
+		last := &splits[len(splits)-1]
+		last.Shares = append(last.Shares, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot Next")
+	}
+
+	balances := splitBalances(splits)
+
+	const settlementsQuery = `
+		SELECT
+			from_member_id,
+			to_member_id,
+			settlement_sum
+		FROM settlements
+		WHERE
+			tg_user_id = $1
+	`
+
+	rows, err = db.db.QueryContext(ctx, settlementsQuery,
+		chatID,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var debt types.Debt
+
+		if err := rows.Scan(&debt.From, &debt.To, &debt.Sum); err != nil {
+			return nil, errors.Wrap(err, "cannot Scan")
+		}
+
+		// The member who gave the money is owed it back.
+		balances[debt.From] += debt.Sum
+		balances[debt.To] -= debt.Sum
+	}
+
+	return balances, errors.Wrap(rows.Err(), "cannot Next")
+}
+
+// expenseSplit is a shared expense with the shares written when it was split.
+type expenseSplit struct {
+	ExpenseID int
+	Sum       int
+	PaidBy    int64
+	Shares    []types.SplitShare
+}
+
+// splitBalances allocates the current sum of every expense in proportion to its written shares:
+// the payer is owed the shares of the others, they owe them.
+func splitBalances(splits []expenseSplit) map[int64]int {
+	balances := make(map[int64]int)
+
+	for _, split := range splits {
+		weights := make([]int, len(split.Shares))
+		for i, share := range split.Shares {
+			weights[i] = share.Sum
+		}
+
+		for i, sum := range types.Allocate(split.Sum, weights) {
+			member := split.Shares[i].MemberID
+			if member == split.PaidBy {
+				continue
+			}
+
+			balances[split.PaidBy] += sum
+			balances[member] -= sum
+		}
+	}
+
+	return balances
+}
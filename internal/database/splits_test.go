@@ -0,0 +1,35 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func TestSplitBalances_ShouldFollowEditedSum(t *testing.T) {
+	// 3000 split 1:2 between the payer and the other member, then the sum is edited to 4500.
+	splits := []expenseSplit{{
+		ExpenseID: 456,
+		Sum:       450000,
+		PaidBy:    1,
+		Shares:    []types.SplitShare{{MemberID: 1, Sum: 100000}, {MemberID: 2, Sum: 200000}},
+	}}
+
+	assert.Equal(t, map[int64]int{1: 300000, 2: -300000}, splitBalances(splits))
+}
+
+func TestSplitBalances_ShouldNotLoseKopecks(t *testing.T) {
+	splits := []expenseSplit{{
+		ExpenseID: 456,
+		Sum:       100,
+		PaidBy:    1,
+		Shares:    []types.SplitShare{{MemberID: 1, Sum: 1}, {MemberID: 2, Sum: 1}, {MemberID: 3, Sum: 1}},
+	}}
+
+	// 34, 33 and 33 kopecks, the payer has the extra one.
+	balances := splitBalances(splits)
+
+	assert.Equal(t, 66, balances[1])
+	assert.Equal(t, 0, balances[1]+balances[2]+balances[3])
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+type txKey struct{}
+
+// txState is the transaction of the context with what is to be done when it is committed.
+type txState struct {
+	tx          *sql.Tx
+	afterCommit []func() error
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getExecutor returns the transaction started by TxManager.RunInTx if there is one in the context.
+func getExecutor(ctx context.Context, db *sql.DB) executor {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+
+	return db
+}
+
+// afterCommit runs fn when the transaction of the context is committed, or right away without one.
+// Nothing is run if the transaction is rolled back.
+func afterCommit(ctx context.Context, fn func() error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return nil
+	}
+
+	return fn()
+}
+
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// RunInTx runs fn in a transaction which is committed if fn succeeds.
+// Queries of the repositories made with the given context join the transaction,
+// what they leave for afterCommit is done once it is committed.
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot BeginTx")
+	}
+
+	state := &txState{tx: tx}
+	err = fn(context.WithValue(ctx, txKey{}, state))
+
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Wrap(rollbackErr, "cannot Rollback")
+		}
+
+		return err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Commit")
+	}
+
+	// The changes are already saved, a failed hook must not make them look lost nor stop the others.
+	for _, hook := range state.afterCommit {
+		if err := hook(); err != nil {
+			log.Println(errors.Wrap(err, "cannot run after commit"))
+		}
+	}
+
+	return nil
+}
@@ -32,20 +32,23 @@ func (db *usersDB) SetCurrentState(ctx context.Context, userID int64, state type
 		INSERT INTO users(
 			tg_user_id,
 			expense_id,
-			current_state
+			current_state,
+			state_member_id
 		) VALUES (
-			$1, $2, $3
+			$1, $2, $3, NULLIF($4, 0)
 		)
 		ON CONFLICT (tg_user_id) DO UPDATE
 		SET 
 			expense_id = $2,
-			current_state = $3
+			current_state = $3,
+			state_member_id = NULLIF($4, 0)
 	`
 
 	_, err := db.db.ExecContext(ctx, query,
 		userID,
 		state.ExpenseID,
 		state.State,
+		state.MemberID,
 	)
 
 	if err != nil {
@@ -66,6 +69,7 @@ func (db *usersDB) GetCurrentState(ctx context.Context, userID int64) (*types.Us
 		SELECT
 			expense_id,
 			current_state,
+			COALESCE(state_member_id, 0),
 			current_currency
 		FROM
 			users
@@ -77,7 +81,8 @@ func (db *usersDB) GetCurrentState(ctx context.Context, userID int64) (*types.Us
 
 	err := db.db.QueryRowContext(ctx, query,
 		userID,
-	).Scan(&userState.CurrentState.ExpenseID, &userState.CurrentState.State, &userState.Currency)
+	).Scan(&userState.CurrentState.ExpenseID, &userState.CurrentState.State, &userState.CurrentState.MemberID,
+		&userState.Currency)
 
 	if err != nil {
 		return nil, false
@@ -182,3 +187,212 @@ func (db *usersDB) ToWaitState(ctx context.Context, userID int64) error {
 
 	return nil
 }
+
+func (db *usersDB) SetHistoryFilter(ctx context.Context, userID int64, filter types.ExpenseFilter) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetHistoryFilter",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO users(
+			tg_user_id,
+			history_category,
+			history_begin,
+			history_end
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT(tg_user_id)
+		DO UPDATE
+			SET
+			history_category = $2,
+			history_begin = $3,
+			history_end = $4
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		filter.Category,
+		sql.NullTime{Time: filter.DateBegin, Valid: !filter.DateBegin.IsZero()},
+		sql.NullTime{Time: filter.DateEnd, Valid: !filter.DateEnd.IsZero()},
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *usersDB) GetHistoryFilter(ctx context.Context, userID int64) (types.ExpenseFilter, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetHistoryFilter",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			history_category,
+			history_begin,
+			history_end
+		FROM
+			users
+		WHERE
+			tg_user_id = $1
+	`
+
+	var (
+		category  sql.NullString
+		dateBegin sql.NullTime
+		dateEnd   sql.NullTime
+	)
+
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+	).Scan(&category, &dateBegin, &dateEnd)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.ExpenseFilter{}, nil
+		}
+
+		return types.ExpenseFilter{}, errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return types.ExpenseFilter{
+		Category:  category.String,
+		DateBegin: dateBegin.Time,
+		DateEnd:   dateEnd.Time,
+	}, nil
+}
+
+func (db *usersDB) SetReportMode(ctx context.Context, userID int64, mode types.ReportMode) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetReportMode",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO users(
+			tg_user_id,
+			report_mode
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT(tg_user_id)
+		DO UPDATE
+			SET
+			report_mode = $2
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		mode,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *usersDB) GetReportMode(ctx context.Context, userID int64) (types.ReportMode, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetReportMode",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			report_mode
+		FROM
+			users
+		WHERE
+			tg_user_id = $1
+	`
+
+	var mode types.ReportMode
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+	).Scan(&mode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.ReportText, nil
+		}
+
+		return "", errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return mode, nil
+}
+
+func (db *usersDB) SetReportCurrencyMode(ctx context.Context, userID int64, mode types.ReportCurrencyMode) error {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"SetReportCurrencyMode",
+	)
+	defer span.Finish()
+
+	const query = `
+		INSERT INTO users(
+			tg_user_id,
+			report_currency
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT(tg_user_id)
+		DO UPDATE
+			SET
+			report_currency = $2
+	`
+
+	_, err := db.db.ExecContext(ctx, query,
+		userID,
+		mode,
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExecContent")
+	}
+
+	return nil
+}
+
+func (db *usersDB) GetReportCurrencyMode(ctx context.Context, userID int64) (types.ReportCurrencyMode, error) {
+	span, ctx := opentracing.StartSpanFromContext(
+		ctx,
+		"GetReportCurrencyMode",
+	)
+	defer span.Finish()
+
+	const query = `
+		SELECT
+			report_currency
+		FROM
+			users
+		WHERE
+			tg_user_id = $1
+	`
+
+	var mode types.ReportCurrencyMode
+	err := db.db.QueryRowContext(ctx, query,
+		userID,
+	).Scan(&mode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.ReportExpenseDate, nil
+		}
+
+		return "", errors.Wrap(err, "cannot QueryRowContext")
+	}
+
+	return mode, nil
+}
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func NewCSV(w io.Writer) (Writer, error) {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(header())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Write")
+	}
+
+	return &csvWriter{
+		writer: writer,
+	}, nil
+}
+
+func (w *csvWriter) WriteRow(row Row) error {
+	err := w.writer.Write([]string{
+		row.Date.Format("2006-01-02"),
+		kind(row),
+		row.Category,
+		strconv.Itoa(row.Sum),
+		strconv.FormatFloat(row.OriginalSum, 'f', 2, 64),
+		row.Currency,
+		strconv.FormatFloat(row.Rate, 'f', -1, 64),
+	})
+
+	return errors.Wrap(err, "cannot Write")
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return errors.Wrap(w.writer.Error(), "cannot Flush")
+}
@@ -0,0 +1,40 @@
+package export
+
+import "time"
+
+// Row is an exported expense or income.
+type Row struct {
+	Date        time.Time
+	Income      bool
+	Category    string
+	Sum         int     // In RUB kopecks, as stored.
+	OriginalSum float64 // As entered, in Currency.
+	Currency    string  // The currency the sum was entered in.
+	Rate        float64 // RUB for a unit of Currency on the date, the one the sum was converted at.
+}
+
+// Writer writes rows one by one, so the whole export is never kept in memory.
+type Writer interface {
+	WriteRow(row Row) error
+	Close() error
+}
+
+func kind(row Row) string {
+	if row.Income {
+		return "Доход"
+	}
+
+	return "Расход"
+}
+
+func header() []string {
+	return []string{
+		"Дата",
+		"Тип",
+		"Категория",
+		"Сумма, коп. RUB",
+		"Сумма",
+		"Валюта",
+		"Курс, RUB",
+	}
+}
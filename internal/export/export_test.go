@@ -0,0 +1,59 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A dollar expense of a past date keeps the amount as entered and the rate of that date.
+var testRow = Row{
+	Date:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	Category:    "кафе & бар",
+	Sum:         35049,
+	OriginalSum: 3.5,
+	Currency:    "USD",
+	Rate:        100.14,
+}
+
+func Test_CSV(t *testing.T) {
+	var buffer bytes.Buffer
+
+	writer, err := NewCSV(&buffer)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteRow(testRow))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, "Дата,Тип,Категория,\"Сумма, коп. RUB\",Сумма,Валюта,\"Курс, RUB\"\n"+
+		"2026-10-01,Расход,кафе & бар,35049,3.50,USD,100.14\n", buffer.String())
+}
+
+func Test_XLSX(t *testing.T) {
+	var buffer bytes.Buffer
+
+	writer, err := NewXLSX(&buffer)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteRow(testRow))
+	assert.NoError(t, writer.WriteRow(Row{Date: testRow.Date, Income: true, Category: "зарплата", Sum: 10000000}))
+	assert.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, archive.File, 5)
+
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	assert.NoError(t, err)
+
+	content, err := io.ReadAll(sheet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "<t>кафе &amp; бар</t>")
+	assert.Contains(t, string(content), "<v>35049</v>")
+	assert.Contains(t, string(content), "<v>3.50</v>")
+	assert.Contains(t, string(content), "<t>USD</t>")
+	assert.Contains(t, string(content), "<v>100.14</v>")
+	assert.Contains(t, string(content), "<t>Доход</t>")
+}
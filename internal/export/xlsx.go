@@ -0,0 +1,125 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The minimal set of parts of a workbook with one sheet, strings are stored inline.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Expenses" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+const (
+	sheetBegin = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetEnd = `</sheetData></worksheet>`
+)
+
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+}
+
+func NewXLSX(w io.Writer) (Writer, error) {
+	archive := zip.NewWriter(w)
+
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot Create")
+		}
+
+		_, err = io.WriteString(file, part.content)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot WriteString")
+		}
+	}
+
+	// The sheet is the last part, so its rows can be streamed.
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot Create")
+	}
+
+	_, err = io.WriteString(sheet, sheetBegin)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot WriteString")
+	}
+
+	writer := &xlsxWriter{
+		archive: archive,
+		sheet:   sheet,
+	}
+
+	var cells []string
+	for _, title := range header() {
+		cells = append(cells, stringCell(title))
+	}
+
+	return writer, writer.writeCells(cells)
+}
+
+func (w *xlsxWriter) WriteRow(row Row) error {
+	return w.writeCells([]string{
+		stringCell(row.Date.Format("2006-01-02")),
+		stringCell(kind(row)),
+		stringCell(row.Category),
+		numberCell(strconv.Itoa(row.Sum)),
+		numberCell(strconv.FormatFloat(row.OriginalSum, 'f', 2, 64)),
+		stringCell(row.Currency),
+		numberCell(strconv.FormatFloat(row.Rate, 'f', -1, 64)),
+	})
+}
+
+func (w *xlsxWriter) Close() error {
+	_, err := io.WriteString(w.sheet, sheetEnd)
+	if err != nil {
+		return errors.Wrap(err, "cannot WriteString")
+	}
+
+	return errors.Wrap(w.archive.Close(), "cannot Close")
+}
+
+func (w *xlsxWriter) writeCells(cells []string) error {
+	_, err := fmt.Fprintf(w.sheet, "<row>%s</row>", strings.Join(cells, ""))
+	return errors.Wrap(err, "cannot Fprintf")
+}
+
+func stringCell(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+
+	return fmt.Sprintf(`<c t="inlineStr"><is><t>%s</t></is></c>`, escaped.String())
+}
+
+func numberCell(value string) string {
+	return fmt.Sprintf("<c><v>%s</v></c>", value)
+}
@@ -6,10 +6,12 @@ package mock_messages
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	statement "gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/statement"
 	types "gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
 )
 
@@ -36,26 +38,13 @@ func (m *MockmessageSender) EXPECT() *MockmessageSenderMockRecorder {
 	return m.recorder
 }
 
-// ChangeCurrency mocks base method.
-func (m *MockmessageSender) ChangeCurrency(text string, userID int64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeCurrency", text, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// ChangeCurrency indicates an expected call of ChangeCurrency.
-func (mr *MockmessageSenderMockRecorder) ChangeCurrency(text, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeCurrency", reflect.TypeOf((*MockmessageSender)(nil).ChangeCurrency), text, userID)
-}
-
 // CreateExpense mocks base method.
-func (m *MockmessageSender) CreateExpense(text string, userID int64) error {
+func (m *MockmessageSender) CreateExpense(text string, userID int64) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateExpense", text, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateExpense indicates an expected call of CreateExpense.
@@ -78,6 +67,21 @@ func (mr *MockmessageSenderMockRecorder) DeleteMessage(userID, messageID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockmessageSender)(nil).DeleteMessage), userID, messageID)
 }
 
+// DownloadFile mocks base method.
+func (m *MockmessageSender) DownloadFile(fileID string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadFile", fileID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadFile indicates an expected call of DownloadFile.
+func (mr *MockmessageSenderMockRecorder) DownloadFile(fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockmessageSender)(nil).DownloadFile), fileID)
+}
+
 // EditExpenseMessage mocks base method.
 func (m *MockmessageSender) EditExpenseMessage(text string, userID int64, messageID int) error {
 	m.ctrl.T.Helper()
@@ -106,6 +110,34 @@ func (mr *MockmessageSenderMockRecorder) GetReport(text, userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockmessageSender)(nil).GetReport), text, userID)
 }
 
+// SendDocument mocks base method.
+func (m *MockmessageSender) SendDocument(name string, data io.Reader, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDocument", name, data, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDocument indicates an expected call of SendDocument.
+func (mr *MockmessageSenderMockRecorder) SendDocument(name, data, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDocument", reflect.TypeOf((*MockmessageSender)(nil).SendDocument), name, data, userID)
+}
+
+// SendImportPreview mocks base method.
+func (m *MockmessageSender) SendImportPreview(text string, userID int64, importID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendImportPreview", text, userID, importID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendImportPreview indicates an expected call of SendImportPreview.
+func (mr *MockmessageSenderMockRecorder) SendImportPreview(text, userID, importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendImportPreview", reflect.TypeOf((*MockmessageSender)(nil).SendImportPreview), text, userID, importID)
+}
+
 // SendMessage mocks base method.
 func (m *MockmessageSender) SendMessage(text string, userID int64) error {
 	m.ctrl.T.Helper()
@@ -143,6 +175,35 @@ func (m *MockexpensesDB) EXPECT() *MockexpensesDBMockRecorder {
 	return m.recorder
 }
 
+// ExportExpenses mocks base method.
+func (m *MockexpensesDB) ExportExpenses(ctx context.Context, userID int64, dateBegin, dateEnd time.Time, fn func(*types.Expense) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportExpenses", ctx, userID, dateBegin, dateEnd, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportExpenses indicates an expected call of ExportExpenses.
+func (mr *MockexpensesDBMockRecorder) ExportExpenses(ctx, userID, dateBegin, dateEnd, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportExpenses", reflect.TypeOf((*MockexpensesDB)(nil).ExportExpenses), ctx, userID, dateBegin, dateEnd, fn)
+}
+
+// GetCategoryMonthReport mocks base method.
+func (m *MockexpensesDB) GetCategoryMonthReport(ctx context.Context, userID int64, category string, date time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryMonthReport", ctx, userID, category, date)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryMonthReport indicates an expected call of GetCategoryMonthReport.
+func (mr *MockexpensesDBMockRecorder) GetCategoryMonthReport(ctx, userID, category, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryMonthReport", reflect.TypeOf((*MockexpensesDB)(nil).GetCategoryMonthReport), ctx, userID, category, date)
+}
+
 // GetExpense mocks base method.
 func (m *MockexpensesDB) GetExpense(ctx context.Context, userID int64, expenseID int) (*types.Expense, error) {
 	m.ctrl.T.Helper()
@@ -173,6 +234,49 @@ func (mr *MockexpensesDBMockRecorder) GetMonthReport(ctx, userID, date interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthReport", reflect.TypeOf((*MockexpensesDB)(nil).GetMonthReport), ctx, userID, date)
 }
 
+// InvalidateReports mocks base method.
+func (m *MockexpensesDB) InvalidateReports(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateReports", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateReports indicates an expected call of InvalidateReports.
+func (mr *MockexpensesDBMockRecorder) InvalidateReports(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateReports", reflect.TypeOf((*MockexpensesDB)(nil).InvalidateReports), ctx, userID)
+}
+
+// IsIncomeCard mocks base method.
+func (m *MockexpensesDB) IsIncomeCard(ctx context.Context, userID int64, cardID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsIncomeCard", ctx, userID, cardID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsIncomeCard indicates an expected call of IsIncomeCard.
+func (mr *MockexpensesDBMockRecorder) IsIncomeCard(ctx, userID, cardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIncomeCard", reflect.TypeOf((*MockexpensesDB)(nil).IsIncomeCard), ctx, userID, cardID)
+}
+
+// MarkIncomeCard mocks base method.
+func (m *MockexpensesDB) MarkIncomeCard(ctx context.Context, userID int64, cardID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkIncomeCard", ctx, userID, cardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkIncomeCard indicates an expected call of MarkIncomeCard.
+func (mr *MockexpensesDBMockRecorder) MarkIncomeCard(ctx, userID, cardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkIncomeCard", reflect.TypeOf((*MockexpensesDB)(nil).MarkIncomeCard), ctx, userID, cardID)
+}
+
 // WriteCategory mocks base method.
 func (m *MockexpensesDB) WriteCategory(ctx context.Context, category string, userID int64, expenseID int) error {
 	m.ctrl.T.Helper()
@@ -216,17 +320,17 @@ func (mr *MockexpensesDBMockRecorder) WriteExpense(ctx, fromID, expense interfac
 }
 
 // WriteSum mocks base method.
-func (m *MockexpensesDB) WriteSum(ctx context.Context, sum int, userID int64, expenseID int) error {
+func (m *MockexpensesDB) WriteSum(ctx context.Context, sum, originalSum int, originalCurrency types.Currency, userID int64, expenseID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WriteSum", ctx, sum, userID, expenseID)
+	ret := m.ctrl.Call(m, "WriteSum", ctx, sum, originalSum, originalCurrency, userID, expenseID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WriteSum indicates an expected call of WriteSum.
-func (mr *MockexpensesDBMockRecorder) WriteSum(ctx, sum, userID, expenseID interface{}) *gomock.Call {
+func (mr *MockexpensesDBMockRecorder) WriteSum(ctx, sum, originalSum, originalCurrency, userID, expenseID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSum", reflect.TypeOf((*MockexpensesDB)(nil).WriteSum), ctx, sum, userID, expenseID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSum", reflect.TypeOf((*MockexpensesDB)(nil).WriteSum), ctx, sum, originalSum, originalCurrency, userID, expenseID)
 }
 
 // MockusersDB is a mock of usersDB interface.
@@ -296,6 +400,20 @@ func (mr *MockusersDBMockRecorder) SetCurrentState(ctx, userID, state interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCurrentState", reflect.TypeOf((*MockusersDB)(nil).SetCurrentState), ctx, userID, state)
 }
 
+// SetHistoryFilter mocks base method.
+func (m *MockusersDB) SetHistoryFilter(ctx context.Context, userID int64, filter types.ExpenseFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHistoryFilter", ctx, userID, filter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHistoryFilter indicates an expected call of SetHistoryFilter.
+func (mr *MockusersDBMockRecorder) SetHistoryFilter(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHistoryFilter", reflect.TypeOf((*MockusersDB)(nil).SetHistoryFilter), ctx, userID, filter)
+}
+
 // SetUserCurrency mocks base method.
 func (m *MockusersDB) SetUserCurrency(ctx context.Context, userID int64, currency types.Currency) error {
 	m.ctrl.T.Helper()
@@ -347,11 +465,26 @@ func (m *MockratesDB) EXPECT() *MockratesDBMockRecorder {
 	return m.recorder
 }
 
+// GetCurrencies mocks base method.
+func (m *MockratesDB) GetCurrencies(ctx context.Context) ([]types.CurrencyInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencies", ctx)
+	ret0, _ := ret[0].([]types.CurrencyInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencies indicates an expected call of GetCurrencies.
+func (mr *MockratesDBMockRecorder) GetCurrencies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockratesDB)(nil).GetCurrencies), ctx)
+}
+
 // GetCurrencyRate mocks base method.
-func (m *MockratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (int, error) {
+func (m *MockratesDB) GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (types.Rate, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetCurrencyRate", ctx, currency, date)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(types.Rate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -362,6 +495,21 @@ func (mr *MockratesDBMockRecorder) GetCurrencyRate(ctx, currency, date interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencyRate", reflect.TypeOf((*MockratesDB)(nil).GetCurrencyRate), ctx, currency, date)
 }
 
+// GetCurrencyRates mocks base method.
+func (m *MockratesDB) GetCurrencyRates(ctx context.Context, currency types.Currency, dateBegin, dateEnd time.Time) (map[time.Time]types.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencyRates", ctx, currency, dateBegin, dateEnd)
+	ret0, _ := ret[0].(map[time.Time]types.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencyRates indicates an expected call of GetCurrencyRates.
+func (mr *MockratesDBMockRecorder) GetCurrencyRates(ctx, currency, dateBegin, dateEnd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencyRates", reflect.TypeOf((*MockratesDB)(nil).GetCurrencyRates), ctx, currency, dateBegin, dateEnd)
+}
+
 // MocklimitsDB is a mock of limitsDB interface.
 type MocklimitsDB struct {
 	ctrl     *gomock.Controller
@@ -386,9 +534,9 @@ func (m *MocklimitsDB) EXPECT() *MocklimitsDBMockRecorder {
 }
 
 // GetLimit mocks base method.
-func (m *MocklimitsDB) GetLimit(ctx context.Context, userID int64, monthNo int) (int, bool, error) {
+func (m *MocklimitsDB) GetLimit(ctx context.Context, userID int64, date time.Time) (int, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLimit", ctx, userID, monthNo)
+	ret := m.ctrl.Call(m, "GetLimit", ctx, userID, date)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(bool)
 	ret2, _ := ret[2].(error)
@@ -396,58 +544,873 @@ func (m *MocklimitsDB) GetLimit(ctx context.Context, userID int64, monthNo int)
 }
 
 // GetLimit indicates an expected call of GetLimit.
-func (mr *MocklimitsDBMockRecorder) GetLimit(ctx, userID, monthNo interface{}) *gomock.Call {
+func (mr *MocklimitsDBMockRecorder) GetLimit(ctx, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimit", reflect.TypeOf((*MocklimitsDB)(nil).GetLimit), ctx, userID, date)
+}
+
+// SetDefaultLimit mocks base method.
+func (m *MocklimitsDB) SetDefaultLimit(ctx context.Context, userID int64, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultLimit", ctx, userID, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultLimit indicates an expected call of SetDefaultLimit.
+func (mr *MocklimitsDBMockRecorder) SetDefaultLimit(ctx, userID, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimit", reflect.TypeOf((*MocklimitsDB)(nil).GetLimit), ctx, userID, monthNo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultLimit", reflect.TypeOf((*MocklimitsDB)(nil).SetDefaultLimit), ctx, userID, limit)
 }
 
 // SetLimit mocks base method.
-func (m *MocklimitsDB) SetLimit(ctx context.Context, userID int64, monthNo, limit int) error {
+func (m *MocklimitsDB) SetLimit(ctx context.Context, userID int64, year, month, limit int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetLimit", ctx, userID, monthNo, limit)
+	ret := m.ctrl.Call(m, "SetLimit", ctx, userID, year, month, limit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetLimit indicates an expected call of SetLimit.
-func (mr *MocklimitsDBMockRecorder) SetLimit(ctx, userID, monthNo, limit interface{}) *gomock.Call {
+func (mr *MocklimitsDBMockRecorder) SetLimit(ctx, userID, year, month, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimit", reflect.TypeOf((*MocklimitsDB)(nil).SetLimit), ctx, userID, monthNo, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimit", reflect.TypeOf((*MocklimitsDB)(nil).SetLimit), ctx, userID, year, month, limit)
 }
 
-// MockcurrencyUpdater is a mock of currencyUpdater interface.
-type MockcurrencyUpdater struct {
+// MockbudgetsDB is a mock of budgetsDB interface.
+type MockbudgetsDB struct {
 	ctrl     *gomock.Controller
-	recorder *MockcurrencyUpdaterMockRecorder
+	recorder *MockbudgetsDBMockRecorder
 }
 
-// MockcurrencyUpdaterMockRecorder is the mock recorder for MockcurrencyUpdater.
-type MockcurrencyUpdaterMockRecorder struct {
-	mock *MockcurrencyUpdater
+// MockbudgetsDBMockRecorder is the mock recorder for MockbudgetsDB.
+type MockbudgetsDBMockRecorder struct {
+	mock *MockbudgetsDB
 }
 
-// NewMockcurrencyUpdater creates a new mock instance.
-func NewMockcurrencyUpdater(ctrl *gomock.Controller) *MockcurrencyUpdater {
-	mock := &MockcurrencyUpdater{ctrl: ctrl}
-	mock.recorder = &MockcurrencyUpdaterMockRecorder{mock}
+// NewMockbudgetsDB creates a new mock instance.
+func NewMockbudgetsDB(ctrl *gomock.Controller) *MockbudgetsDB {
+	mock := &MockbudgetsDB{ctrl: ctrl}
+	mock.recorder = &MockbudgetsDBMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockcurrencyUpdater) EXPECT() *MockcurrencyUpdaterMockRecorder {
+func (m *MockbudgetsDB) EXPECT() *MockbudgetsDBMockRecorder {
 	return m.recorder
 }
 
-// UpdateCurrencyRate mocks base method.
-func (m *MockcurrencyUpdater) UpdateCurrencyRate(ctx context.Context) error {
+// GetBudget mocks base method.
+func (m *MockbudgetsDB) GetBudget(ctx context.Context, userID int64, category string, date time.Time) (int, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCurrencyRate", ctx)
+	ret := m.ctrl.Call(m, "GetBudget", ctx, userID, category, date)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBudget indicates an expected call of GetBudget.
+func (mr *MockbudgetsDBMockRecorder) GetBudget(ctx, userID, category, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudget", reflect.TypeOf((*MockbudgetsDB)(nil).GetBudget), ctx, userID, category, date)
+}
+
+// GetBudgets mocks base method.
+func (m *MockbudgetsDB) GetBudgets(ctx context.Context, userID int64, date time.Time) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgets", ctx, userID, date)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgets indicates an expected call of GetBudgets.
+func (mr *MockbudgetsDBMockRecorder) GetBudgets(ctx, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgets", reflect.TypeOf((*MockbudgetsDB)(nil).GetBudgets), ctx, userID, date)
+}
+
+// SetBudget mocks base method.
+func (m *MockbudgetsDB) SetBudget(ctx context.Context, userID int64, category string, date time.Time, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBudget", ctx, userID, category, date, limit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateCurrencyRate indicates an expected call of UpdateCurrencyRate.
-func (mr *MockcurrencyUpdaterMockRecorder) UpdateCurrencyRate(ctx interface{}) *gomock.Call {
+// SetBudget indicates an expected call of SetBudget.
+func (mr *MockbudgetsDBMockRecorder) SetBudget(ctx, userID, category, date, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrencyRate", reflect.TypeOf((*MockcurrencyUpdater)(nil).UpdateCurrencyRate), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBudget", reflect.TypeOf((*MockbudgetsDB)(nil).SetBudget), ctx, userID, category, date, limit)
+}
+
+// MockrecurringDB is a mock of recurringDB interface.
+type MockrecurringDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockrecurringDBMockRecorder
+}
+
+// MockrecurringDBMockRecorder is the mock recorder for MockrecurringDB.
+type MockrecurringDBMockRecorder struct {
+	mock *MockrecurringDB
+}
+
+// NewMockrecurringDB creates a new mock instance.
+func NewMockrecurringDB(ctrl *gomock.Controller) *MockrecurringDB {
+	mock := &MockrecurringDB{ctrl: ctrl}
+	mock.recorder = &MockrecurringDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrecurringDB) EXPECT() *MockrecurringDBMockRecorder {
+	return m.recorder
+}
+
+// CreateRecurringExpense mocks base method.
+func (m *MockrecurringDB) CreateRecurringExpense(ctx context.Context, userID int64, recurring *types.RecurringExpense) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecurringExpense", ctx, userID, recurring)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecurringExpense indicates an expected call of CreateRecurringExpense.
+func (mr *MockrecurringDBMockRecorder) CreateRecurringExpense(ctx, userID, recurring interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecurringExpense", reflect.TypeOf((*MockrecurringDB)(nil).CreateRecurringExpense), ctx, userID, recurring)
+}
+
+// DeleteRecurringExpense mocks base method.
+func (m *MockrecurringDB) DeleteRecurringExpense(ctx context.Context, userID int64, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecurringExpense", ctx, userID, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecurringExpense indicates an expected call of DeleteRecurringExpense.
+func (mr *MockrecurringDBMockRecorder) DeleteRecurringExpense(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecurringExpense", reflect.TypeOf((*MockrecurringDB)(nil).DeleteRecurringExpense), ctx, userID, id)
+}
+
+// GetRecurringExpenses mocks base method.
+func (m *MockrecurringDB) GetRecurringExpenses(ctx context.Context, userID int64) ([]types.RecurringExpense, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecurringExpenses", ctx, userID)
+	ret0, _ := ret[0].([]types.RecurringExpense)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecurringExpenses indicates an expected call of GetRecurringExpenses.
+func (mr *MockrecurringDBMockRecorder) GetRecurringExpenses(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecurringExpenses", reflect.TypeOf((*MockrecurringDB)(nil).GetRecurringExpenses), ctx, userID)
+}
+
+// ResumeRecurringExpense mocks base method.
+func (m *MockrecurringDB) ResumeRecurringExpense(ctx context.Context, userID int64, id int, nextDate time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeRecurringExpense", ctx, userID, id, nextDate)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeRecurringExpense indicates an expected call of ResumeRecurringExpense.
+func (mr *MockrecurringDBMockRecorder) ResumeRecurringExpense(ctx, userID, id, nextDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeRecurringExpense", reflect.TypeOf((*MockrecurringDB)(nil).ResumeRecurringExpense), ctx, userID, id, nextDate)
+}
+
+// SetRecurringExpensePaused mocks base method.
+func (m *MockrecurringDB) SetRecurringExpensePaused(ctx context.Context, userID int64, id int, paused bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRecurringExpensePaused", ctx, userID, id, paused)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRecurringExpensePaused indicates an expected call of SetRecurringExpensePaused.
+func (mr *MockrecurringDBMockRecorder) SetRecurringExpensePaused(ctx, userID, id, paused interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecurringExpensePaused", reflect.TypeOf((*MockrecurringDB)(nil).SetRecurringExpensePaused), ctx, userID, id, paused)
+}
+
+// MockimportsDB is a mock of importsDB interface.
+type MockimportsDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockimportsDBMockRecorder
+}
+
+// MockimportsDBMockRecorder is the mock recorder for MockimportsDB.
+type MockimportsDBMockRecorder struct {
+	mock *MockimportsDB
+}
+
+// NewMockimportsDB creates a new mock instance.
+func NewMockimportsDB(ctrl *gomock.Controller) *MockimportsDB {
+	mock := &MockimportsDB{ctrl: ctrl}
+	mock.recorder = &MockimportsDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockimportsDB) EXPECT() *MockimportsDBMockRecorder {
+	return m.recorder
+}
+
+// CreatePendingImport mocks base method.
+func (m *MockimportsDB) CreatePendingImport(ctx context.Context, userID int64, expenses []types.Expense) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingImport", ctx, userID, expenses)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingImport indicates an expected call of CreatePendingImport.
+func (mr *MockimportsDBMockRecorder) CreatePendingImport(ctx, userID, expenses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingImport", reflect.TypeOf((*MockimportsDB)(nil).CreatePendingImport), ctx, userID, expenses)
+}
+
+// DeleteCategoryRule mocks base method.
+func (m *MockimportsDB) DeleteCategoryRule(ctx context.Context, userID int64, pattern string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", ctx, userID, pattern)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockimportsDBMockRecorder) DeleteCategoryRule(ctx, userID, pattern interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockimportsDB)(nil).DeleteCategoryRule), ctx, userID, pattern)
+}
+
+// GetCategoryRules mocks base method.
+func (m *MockimportsDB) GetCategoryRules(ctx context.Context, userID int64) ([]statement.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRules", ctx, userID)
+	ret0, _ := ret[0].([]statement.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRules indicates an expected call of GetCategoryRules.
+func (mr *MockimportsDBMockRecorder) GetCategoryRules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRules", reflect.TypeOf((*MockimportsDB)(nil).GetCategoryRules), ctx, userID)
+}
+
+// GetImportProfile mocks base method.
+func (m *MockimportsDB) GetImportProfile(ctx context.Context, userID int64) (statement.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportProfile", ctx, userID)
+	ret0, _ := ret[0].(statement.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportProfile indicates an expected call of GetImportProfile.
+func (mr *MockimportsDBMockRecorder) GetImportProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportProfile", reflect.TypeOf((*MockimportsDB)(nil).GetImportProfile), ctx, userID)
+}
+
+// SetCategoryRule mocks base method.
+func (m *MockimportsDB) SetCategoryRule(ctx context.Context, userID int64, rule statement.Rule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCategoryRule", ctx, userID, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCategoryRule indicates an expected call of SetCategoryRule.
+func (mr *MockimportsDBMockRecorder) SetCategoryRule(ctx, userID, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategoryRule", reflect.TypeOf((*MockimportsDB)(nil).SetCategoryRule), ctx, userID, rule)
+}
+
+// SetImportProfile mocks base method.
+func (m *MockimportsDB) SetImportProfile(ctx context.Context, userID int64, profile statement.Profile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImportProfile", ctx, userID, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImportProfile indicates an expected call of SetImportProfile.
+func (mr *MockimportsDBMockRecorder) SetImportProfile(ctx, userID, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImportProfile", reflect.TypeOf((*MockimportsDB)(nil).SetImportProfile), ctx, userID, profile)
+}
+
+// MockaccountsDB is a mock of accountsDB interface.
+type MockaccountsDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockaccountsDBMockRecorder
+}
+
+// MockaccountsDBMockRecorder is the mock recorder for MockaccountsDB.
+type MockaccountsDBMockRecorder struct {
+	mock *MockaccountsDB
+}
+
+// NewMockaccountsDB creates a new mock instance.
+func NewMockaccountsDB(ctrl *gomock.Controller) *MockaccountsDB {
+	mock := &MockaccountsDB{ctrl: ctrl}
+	mock.recorder = &MockaccountsDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockaccountsDB) EXPECT() *MockaccountsDBMockRecorder {
+	return m.recorder
+}
+
+// CreateAccount mocks base method.
+func (m *MockaccountsDB) CreateAccount(ctx context.Context, userID int64, account types.Account) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, userID, account)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockaccountsDBMockRecorder) CreateAccount(ctx, userID, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockaccountsDB)(nil).CreateAccount), ctx, userID, account)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockaccountsDB) CreateTransfer(ctx context.Context, userID int64, transfer types.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, userID, transfer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockaccountsDBMockRecorder) CreateTransfer(ctx, userID, transfer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockaccountsDB)(nil).CreateTransfer), ctx, userID, transfer)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockaccountsDB) DeleteAccount(ctx context.Context, userID int64, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockaccountsDBMockRecorder) DeleteAccount(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockaccountsDB)(nil).DeleteAccount), ctx, userID, name)
+}
+
+// GetAccountExpenses mocks base method.
+func (m *MockaccountsDB) GetAccountExpenses(ctx context.Context, userID int64) ([]types.AccountExpenses, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountExpenses", ctx, userID)
+	ret0, _ := ret[0].([]types.AccountExpenses)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountExpenses indicates an expected call of GetAccountExpenses.
+func (mr *MockaccountsDBMockRecorder) GetAccountExpenses(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountExpenses", reflect.TypeOf((*MockaccountsDB)(nil).GetAccountExpenses), ctx, userID)
+}
+
+// GetAccounts mocks base method.
+func (m *MockaccountsDB) GetAccounts(ctx context.Context, userID int64) ([]types.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts", ctx, userID)
+	ret0, _ := ret[0].([]types.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockaccountsDBMockRecorder) GetAccounts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockaccountsDB)(nil).GetAccounts), ctx, userID)
+}
+
+// GetTransferSums mocks base method.
+func (m *MockaccountsDB) GetTransferSums(ctx context.Context, userID int64) (map[int]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferSums", ctx, userID)
+	ret0, _ := ret[0].(map[int]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferSums indicates an expected call of GetTransferSums.
+func (mr *MockaccountsDBMockRecorder) GetTransferSums(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferSums", reflect.TypeOf((*MockaccountsDB)(nil).GetTransferSums), ctx, userID)
+}
+
+// MockgroupsDB is a mock of groupsDB interface.
+type MockgroupsDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockgroupsDBMockRecorder
+}
+
+// MockgroupsDBMockRecorder is the mock recorder for MockgroupsDB.
+type MockgroupsDBMockRecorder struct {
+	mock *MockgroupsDB
+}
+
+// NewMockgroupsDB creates a new mock instance.
+func NewMockgroupsDB(ctrl *gomock.Controller) *MockgroupsDB {
+	mock := &MockgroupsDB{ctrl: ctrl}
+	mock.recorder = &MockgroupsDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgroupsDB) EXPECT() *MockgroupsDBMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockgroupsDB) AddMember(ctx context.Context, chatID int64, member types.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, chatID, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockgroupsDBMockRecorder) AddMember(ctx, chatID, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockgroupsDB)(nil).AddMember), ctx, chatID, member)
+}
+
+// GetMembers mocks base method.
+func (m *MockgroupsDB) GetMembers(ctx context.Context, chatID int64) ([]types.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, chatID)
+	ret0, _ := ret[0].([]types.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockgroupsDBMockRecorder) GetMembers(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockgroupsDB)(nil).GetMembers), ctx, chatID)
+}
+
+// MocksplitsDB is a mock of splitsDB interface.
+type MocksplitsDB struct {
+	ctrl     *gomock.Controller
+	recorder *MocksplitsDBMockRecorder
+}
+
+// MocksplitsDBMockRecorder is the mock recorder for MocksplitsDB.
+type MocksplitsDBMockRecorder struct {
+	mock *MocksplitsDB
+}
+
+// NewMocksplitsDB creates a new mock instance.
+func NewMocksplitsDB(ctrl *gomock.Controller) *MocksplitsDB {
+	mock := &MocksplitsDB{ctrl: ctrl}
+	mock.recorder = &MocksplitsDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksplitsDB) EXPECT() *MocksplitsDBMockRecorder {
+	return m.recorder
+}
+
+// CreateSettlement mocks base method.
+func (m *MocksplitsDB) CreateSettlement(ctx context.Context, chatID int64, debt types.Debt, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSettlement", ctx, chatID, debt, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSettlement indicates an expected call of CreateSettlement.
+func (mr *MocksplitsDBMockRecorder) CreateSettlement(ctx, chatID, debt, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSettlement", reflect.TypeOf((*MocksplitsDB)(nil).CreateSettlement), ctx, chatID, debt, date)
+}
+
+// GetBalances mocks base method.
+func (m *MocksplitsDB) GetBalances(ctx context.Context, chatID int64) (map[int64]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalances", ctx, chatID)
+	ret0, _ := ret[0].(map[int64]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalances indicates an expected call of GetBalances.
+func (mr *MocksplitsDBMockRecorder) GetBalances(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalances", reflect.TypeOf((*MocksplitsDB)(nil).GetBalances), ctx, chatID)
+}
+
+// WriteSplits mocks base method.
+func (m *MocksplitsDB) WriteSplits(ctx context.Context, chatID int64, expenseID int, shares []types.SplitShare) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSplits", ctx, chatID, expenseID, shares)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSplits indicates an expected call of WriteSplits.
+func (mr *MocksplitsDBMockRecorder) WriteSplits(ctx, chatID, expenseID, shares interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSplits", reflect.TypeOf((*MocksplitsDB)(nil).WriteSplits), ctx, chatID, expenseID, shares)
+}
+
+// MockcategoriesDB is a mock of categoriesDB interface.
+type MockcategoriesDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockcategoriesDBMockRecorder
+}
+
+// MockcategoriesDBMockRecorder is the mock recorder for MockcategoriesDB.
+type MockcategoriesDBMockRecorder struct {
+	mock *MockcategoriesDB
+}
+
+// NewMockcategoriesDB creates a new mock instance.
+func NewMockcategoriesDB(ctrl *gomock.Controller) *MockcategoriesDB {
+	mock := &MockcategoriesDB{ctrl: ctrl}
+	mock.recorder = &MockcategoriesDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcategoriesDB) EXPECT() *MockcategoriesDBMockRecorder {
+	return m.recorder
+}
+
+// AddCategory mocks base method.
+func (m *MockcategoriesDB) AddCategory(ctx context.Context, userID int64, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCategory", ctx, userID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCategory indicates an expected call of AddCategory.
+func (mr *MockcategoriesDBMockRecorder) AddCategory(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockcategoriesDB)(nil).AddCategory), ctx, userID, name)
+}
+
+// GetCategories mocks base method.
+func (m *MockcategoriesDB) GetCategories(ctx context.Context, userID int64) ([]types.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx, userID)
+	ret0, _ := ret[0].([]types.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockcategoriesDBMockRecorder) GetCategories(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockcategoriesDB)(nil).GetCategories), ctx, userID)
+}
+
+// MergeCategory mocks base method.
+func (m *MockcategoriesDB) MergeCategory(ctx context.Context, userID int64, name, into string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCategory", ctx, userID, name, into)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeCategory indicates an expected call of MergeCategory.
+func (mr *MockcategoriesDBMockRecorder) MergeCategory(ctx, userID, name, into interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCategory", reflect.TypeOf((*MockcategoriesDB)(nil).MergeCategory), ctx, userID, name, into)
+}
+
+// RenameCategory mocks base method.
+func (m *MockcategoriesDB) RenameCategory(ctx context.Context, userID int64, name, newName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameCategory", ctx, userID, name, newName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameCategory indicates an expected call of RenameCategory.
+func (mr *MockcategoriesDBMockRecorder) RenameCategory(ctx, userID, name, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameCategory", reflect.TypeOf((*MockcategoriesDB)(nil).RenameCategory), ctx, userID, name, newName)
+}
+
+// SetCategoryArchived mocks base method.
+func (m *MockcategoriesDB) SetCategoryArchived(ctx context.Context, userID int64, name string, archived bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCategoryArchived", ctx, userID, name, archived)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCategoryArchived indicates an expected call of SetCategoryArchived.
+func (mr *MockcategoriesDBMockRecorder) SetCategoryArchived(ctx, userID, name, archived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategoryArchived", reflect.TypeOf((*MockcategoriesDB)(nil).SetCategoryArchived), ctx, userID, name, archived)
+}
+
+// SetCategoryEmoji mocks base method.
+func (m *MockcategoriesDB) SetCategoryEmoji(ctx context.Context, userID int64, name, emoji string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCategoryEmoji", ctx, userID, name, emoji)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCategoryEmoji indicates an expected call of SetCategoryEmoji.
+func (mr *MockcategoriesDBMockRecorder) SetCategoryEmoji(ctx, userID, name, emoji interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategoryEmoji", reflect.TypeOf((*MockcategoriesDB)(nil).SetCategoryEmoji), ctx, userID, name, emoji)
+}
+
+// SetCategoryParent mocks base method.
+func (m *MockcategoriesDB) SetCategoryParent(ctx context.Context, userID int64, name, parent string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCategoryParent", ctx, userID, name, parent)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCategoryParent indicates an expected call of SetCategoryParent.
+func (mr *MockcategoriesDBMockRecorder) SetCategoryParent(ctx, userID, name, parent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategoryParent", reflect.TypeOf((*MockcategoriesDB)(nil).SetCategoryParent), ctx, userID, name, parent)
+}
+
+// MocktxManager is a mock of txManager interface.
+type MocktxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MocktxManagerMockRecorder
+}
+
+// MocktxManagerMockRecorder is the mock recorder for MocktxManager.
+type MocktxManagerMockRecorder struct {
+	mock *MocktxManager
+}
+
+// NewMocktxManager creates a new mock instance.
+func NewMocktxManager(ctrl *gomock.Controller) *MocktxManager {
+	mock := &MocktxManager{ctrl: ctrl}
+	mock.recorder = &MocktxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktxManager) EXPECT() *MocktxManagerMockRecorder {
+	return m.recorder
+}
+
+// RunInTx mocks base method.
+func (m *MocktxManager) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MocktxManagerMockRecorder) RunInTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MocktxManager)(nil).RunInTx), ctx, fn)
+}
+
+// MockcurrencyUpdater is a mock of currencyUpdater interface.
+type MockcurrencyUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockcurrencyUpdaterMockRecorder
+}
+
+// MockcurrencyUpdaterMockRecorder is the mock recorder for MockcurrencyUpdater.
+type MockcurrencyUpdaterMockRecorder struct {
+	mock *MockcurrencyUpdater
+}
+
+// NewMockcurrencyUpdater creates a new mock instance.
+func NewMockcurrencyUpdater(ctrl *gomock.Controller) *MockcurrencyUpdater {
+	mock := &MockcurrencyUpdater{ctrl: ctrl}
+	mock.recorder = &MockcurrencyUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcurrencyUpdater) EXPECT() *MockcurrencyUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateCurrencyRate mocks base method.
+func (m *MockcurrencyUpdater) UpdateCurrencyRate(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCurrencyRate", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCurrencyRate indicates an expected call of UpdateCurrencyRate.
+func (mr *MockcurrencyUpdaterMockRecorder) UpdateCurrencyRate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrencyRate", reflect.TypeOf((*MockcurrencyUpdater)(nil).UpdateCurrencyRate), ctx)
+}
+
+// UpdateCurrencyRateOnDate mocks base method.
+func (m *MockcurrencyUpdater) UpdateCurrencyRateOnDate(ctx context.Context, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCurrencyRateOnDate", ctx, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCurrencyRateOnDate indicates an expected call of UpdateCurrencyRateOnDate.
+func (mr *MockcurrencyUpdaterMockRecorder) UpdateCurrencyRateOnDate(ctx, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrencyRateOnDate", reflect.TypeOf((*MockcurrencyUpdater)(nil).UpdateCurrencyRateOnDate), ctx, date)
+}
+
+// Mockconfig is a mock of config interface.
+type Mockconfig struct {
+	ctrl     *gomock.Controller
+	recorder *MockconfigMockRecorder
+}
+
+// MockconfigMockRecorder is the mock recorder for Mockconfig.
+type MockconfigMockRecorder struct {
+	mock *Mockconfig
+}
+
+// NewMockconfig creates a new mock instance.
+func NewMockconfig(ctrl *gomock.Controller) *Mockconfig {
+	mock := &Mockconfig{ctrl: ctrl}
+	mock.recorder = &MockconfigMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockconfig) EXPECT() *MockconfigMockRecorder {
+	return m.recorder
+}
+
+// GetBudgetThresholds mocks base method.
+func (m *Mockconfig) GetBudgetThresholds() []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetThresholds")
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// GetBudgetThresholds indicates an expected call of GetBudgetThresholds.
+func (mr *MockconfigMockRecorder) GetBudgetThresholds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetThresholds", reflect.TypeOf((*Mockconfig)(nil).GetBudgetThresholds))
+}
+
+// GetDefaultLimit mocks base method.
+func (m *Mockconfig) GetDefaultLimit() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetDefaultLimit indicates an expected call of GetDefaultLimit.
+func (mr *MockconfigMockRecorder) GetDefaultLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultLimit", reflect.TypeOf((*Mockconfig)(nil).GetDefaultLimit))
+}
+
+// Mockreporter is a mock of reporter interface.
+type Mockreporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockreporterMockRecorder
+}
+
+// MockreporterMockRecorder is the mock recorder for Mockreporter.
+type MockreporterMockRecorder struct {
+	mock *Mockreporter
+}
+
+// NewMockreporter creates a new mock instance.
+func NewMockreporter(ctrl *gomock.Controller) *Mockreporter {
+	mock := &Mockreporter{ctrl: ctrl}
+	mock.recorder = &MockreporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockreporter) EXPECT() *MockreporterMockRecorder {
+	return m.recorder
+}
+
+// SendCurrencies mocks base method.
+func (m *Mockreporter) SendCurrencies(ctx context.Context, userID int64, query string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCurrencies", ctx, userID, query)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCurrencies indicates an expected call of SendCurrencies.
+func (mr *MockreporterMockRecorder) SendCurrencies(ctx, userID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCurrencies", reflect.TypeOf((*Mockreporter)(nil).SendCurrencies), ctx, userID, query)
+}
+
+// SendHistory mocks base method.
+func (m *Mockreporter) SendHistory(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHistory", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHistory indicates an expected call of SendHistory.
+func (mr *MockreporterMockRecorder) SendHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHistory", reflect.TypeOf((*Mockreporter)(nil).SendHistory), ctx, userID)
+}
+
+// SendReport mocks base method.
+func (m *Mockreporter) SendReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendReport", ctx, userID, dateBegin, dateEnd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendReport indicates an expected call of SendReport.
+func (mr *MockreporterMockRecorder) SendReport(ctx, userID, dateBegin, dateEnd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReport", reflect.TypeOf((*Mockreporter)(nil).SendReport), ctx, userID, dateBegin, dateEnd)
 }
@@ -0,0 +1,112 @@
+package callbacks
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const noAccountsMsg = "Счетов нет, добавьте их командой /account add"
+
+// chooseExpenseAccount shows the user's accounts on the expense card instead of the edit buttons.
+func (s *Model) chooseExpenseAccount(ctx context.Context, data *CallbackData) error {
+	accounts, err := s.accountsDB.GetAccounts(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetAccounts")
+	}
+
+	if len(accounts) == 0 {
+		return s.tgClient.ShowAlert(noAccountsMsg, data.CallbackID)
+	}
+
+	return s.tgClient.EditExpenseAccounts(data.FromID, data.MessageID, accounts)
+}
+
+// changeExpenseAccount sets the chosen account and brings the edit buttons back.
+func (s *Model) changeExpenseAccount(ctx context.Context, data *CallbackData, arg string) error {
+	accountID, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	account := ""
+	if accountID != 0 {
+		accounts, err := s.accountsDB.GetAccounts(ctx, data.FromID)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot GetAccounts")
+		}
+
+		for _, a := range accounts {
+			if a.ID == accountID {
+				account = a.Name
+			}
+		}
+
+		if account == "" {
+			return s.tgClient.ShowAlert("Счет не найден", data.CallbackID)
+		}
+	}
+
+	expense, err := s.expensesDB.GetExpense(ctx, data.FromID, data.MessageID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetExpense")
+	}
+
+	if expense == nil {
+		// The card has not been edited yet, so the expense is not written.
+		expense, err = s.newCardExpense(ctx, data)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot newCardExpense")
+		}
+
+		expense.AccountID = accountID
+
+		err = s.expensesDB.WriteExpense(ctx, data.FromID, expense)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot WriteExpense")
+		}
+	} else {
+		err = s.expensesDB.WriteAccount(ctx, accountID, data.FromID, data.MessageID)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot WriteAccount")
+		}
+	}
+
+	expense.AccountID, expense.Account = accountID, account
+
+	userModel, err := s.getUserModel(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	return s.tgClient.EditExpenseMessage(expense.ToString(userModel), data.FromID, data.MessageID)
+}
+
+// newCardExpense returns the entry of a card which is not written yet, an income for the cards of /income.
+func (s *Model) newCardExpense(ctx context.Context, data *CallbackData) (*types.Expense, error) {
+	income, err := s.expensesDB.IsIncomeCard(ctx, data.FromID, data.MessageID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot IsIncomeCard")
+	}
+
+	expense := types.NewExpense()
+	if income {
+		expense = types.NewIncome()
+	}
+
+	expense.ExpenseID = data.MessageID
+	expense.PaidBy = data.MemberID
+
+	return expense, nil
+}
@@ -3,6 +3,7 @@ package callbacks
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -14,15 +15,44 @@ const (
 	ChangeExpenseSum      string = "ChangeExpenseSum"
 	ChangeExpenseCategory string = "ChangeExpenseCategory"
 	ChangeExpenseDate     string = "ChangeExpenseDate"
+	ChangeExpenseAccount  string = "ChangeExpenseAccount"
 	ChangeExpenseDone     string = "ChangeExpenseDone"
 	ChangeExpenseCancel   string = "ChangeExpenseCancel"
 
 	// getReportKeyboard
-	GetWeekReport  string = "GetWeekReport"
-	GetMonthReport string = "GetMonthReport"
-	GetYearReport  string = "GetYearReport"
+	GetWeekReport          string = "GetWeekReport"
+	GetMonthReport         string = "GetMonthReport"
+	GetYearReport          string = "GetYearReport"
+	GetCalendarMonthReport string = "GetCalendarMonthReport"
+	GetPreviousMonthReport string = "GetPreviousMonthReport"
+	GetQuarterReport       string = "GetQuarterReport"
+	GetYearToDateReport    string = "GetYearToDateReport"
+	ToggleReportMode       string = "ToggleReportMode"
+	ToggleReportCurrency   string = "ToggleReportCurrency"
 
-	// changeCurrencyKeyboard
+	// reportKeyboard, the data is followed by ":" and the category id, ":", the begin and ":", the end as YYYYMMDD.
+	ReportCategory string = "ReportCategory"
+
+	// historyKeyboard, the data is followed by ":" and the argument.
+	HistoryPage   string = "HistoryPage"
+	HistoryEdit   string = "HistoryEdit"
+	HistoryDelete string = "HistoryDelete"
+
+	// importKeyboard, the data is followed by ":" and the import id.
+	ImportConfirm string = "ImportConfirm"
+	ImportCancel  string = "ImportCancel"
+
+	// accountsKeyboard, the data is followed by ":" and the account id, zero clears the account.
+	SelectExpenseAccount string = "ExpenseAccount"
+
+	// categoriesKeyboard, the data is followed by ":" and the category id, zero goes back.
+	SelectExpenseCategory string = "ExpenseCategory"
+
+	// currencyKeyboard, the data is followed by ":" and the code or by ":", the page, ":" and the query.
+	SelectCurrency string = "Currency"
+	CurrencyPage   string = "CurrencyPage"
+
+	// Buttons of the old fixed currency keyboard which may still be in the chats.
 	USD string = "USD"
 	CNY string = "CNY"
 	EUR string = "EUR"
@@ -31,17 +61,38 @@ const (
 
 type callbackHandler interface {
 	SendMessage(text string, userID int64) error
+	SendReportMessage(text string, userID int64, drillDowns []types.ReportDrillDown) error
 	EditMessage(text string, userID int64, messageID int) error
 	ShowAlert(text string, messageID string) error
 	DoneMessage(userID int64, messageID int) error
 	CancelMessage(userID int64, messageID int) error
+	CreateExpense(text string, userID int64) (int, error)
+	EditExpenseMessage(text string, userID int64, messageID int) error
+	EditExpenseAccounts(userID int64, messageID int, accounts []types.Account) error
+	EditExpenseCategories(userID int64, messageID int, categories []types.Category) error
+	SendHistory(text string, userID int64, page types.HistoryPage) error
+	EditHistory(text string, userID int64, messageID int, page types.HistoryPage) error
+	SendPhoto(name string, data []byte, caption string, userID int64) error
+	SendCurrencies(text string, userID int64, page types.CurrencyPage) error
+	EditCurrencies(text string, userID int64, messageID int, page types.CurrencyPage) error
 }
 
 type expensesDB interface {
 	WriteExpense(ctx context.Context, fromID int64, expense *types.Expense) error
 	DeleteExpense(ctx context.Context, userID int64, expenseID int) error
 	EditNewExpense(ctx context.Context, userID int64, expenseID int, expense *types.Expense) error
-	GetReport(ctx context.Context, fromID int64, dateBegin time.Time, dateEnd time.Time) (map[string]int, error)
+	GetReport(ctx context.Context, fromID int64, dateBegin time.Time, dateEnd time.Time, level types.CategoryLevel) (map[string]int, error)
+	GetExpense(ctx context.Context, userID int64, expenseID int) (*types.Expense, error)
+	GetExpenses(ctx context.Context, userID int64, filter types.ExpenseFilter, limit, offset int) ([]types.Expense, error)
+	CountExpenses(ctx context.Context, userID int64, filter types.ExpenseFilter) (int, error)
+	ChangeExpenseID(ctx context.Context, userID int64, expenseID, newExpenseID int) error
+	NextExpenseID(ctx context.Context) (int, error)
+	CountSameExpenses(ctx context.Context, userID int64, date time.Time, sum int) (int, error)
+	GetReportRows(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) ([]types.ReportRow, error)
+	WriteAccount(ctx context.Context, accountID int, userID int64, expenseID int) error
+	WriteCategory(ctx context.Context, category string, userID int64, expenseID int) error
+	GetMemberReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) (map[int64]int, error)
+	IsIncomeCard(ctx context.Context, userID int64, cardID int) (bool, error)
 }
 
 type usersDB interface {
@@ -50,30 +101,87 @@ type usersDB interface {
 	ToWaitState(ctx context.Context, userID int64) error
 	SetUserCurrency(ctx context.Context, userID int64, currency types.Currency) error
 	GetUserCurrency(ctx context.Context, userID int64) (types.Currency, error)
+	GetHistoryFilter(ctx context.Context, userID int64) (types.ExpenseFilter, error)
+	GetReportMode(ctx context.Context, userID int64) (types.ReportMode, error)
+	SetReportMode(ctx context.Context, userID int64, mode types.ReportMode) error
+	GetReportCurrencyMode(ctx context.Context, userID int64) (types.ReportCurrencyMode, error)
+	SetReportCurrencyMode(ctx context.Context, userID int64, mode types.ReportCurrencyMode) error
 }
 
 type ratesDB interface {
-	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (int, error)
+	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (types.Rate, error)
+	GetCurrencyRates(ctx context.Context, currency types.Currency, dateBegin, dateEnd time.Time) (map[time.Time]types.Rate, error)
+	GetCurrencies(ctx context.Context) ([]types.CurrencyInfo, error)
+}
+
+type budgetsDB interface {
+	GetBudgets(ctx context.Context, userID int64, date time.Time) (map[string]int, error)
+}
+
+type importsDB interface {
+	TakePendingImport(ctx context.Context, userID int64, importID int) ([]types.Expense, error)
+}
+
+type accountsDB interface {
+	GetAccounts(ctx context.Context, userID int64) ([]types.Account, error)
+}
+
+type groupsDB interface {
+	GetMembers(ctx context.Context, chatID int64) ([]types.Member, error)
+}
+
+type categoriesDB interface {
+	GetCategories(ctx context.Context, userID int64) ([]types.Category, error)
+}
+
+// budgetChecker warns about the budget of the category, it is the messages model
+// which is created after this one.
+type budgetChecker interface {
+	CheckCategoryBudget(ctx context.Context, userID int64, expense, previous *types.Expense) error
+}
+
+type txManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Model struct {
-	tgClient   callbackHandler
-	expensesDB expensesDB
-	usersDB    usersDB
-	ratesDB    ratesDB
+	tgClient      callbackHandler
+	expensesDB    expensesDB
+	usersDB       usersDB
+	ratesDB       ratesDB
+	budgetsDB     budgetsDB
+	importsDB     importsDB
+	accountsDB    accountsDB
+	groupsDB      groupsDB
+	categoriesDB  categoriesDB
+	budgetChecker budgetChecker
+	txManager     txManager
 }
 
-func New(tgClient callbackHandler, expensesDB expensesDB, usersDB usersDB, ratesDB ratesDB) *Model {
+func New(tgClient callbackHandler, expensesDB expensesDB, usersDB usersDB, ratesDB ratesDB, budgetsDB budgetsDB,
+	importsDB importsDB, accountsDB accountsDB, groupsDB groupsDB, categoriesDB categoriesDB, txManager txManager) *Model {
 	return &Model{
-		tgClient:   tgClient,
-		expensesDB: expensesDB,
-		usersDB:    usersDB,
-		ratesDB:    ratesDB,
+		tgClient:     tgClient,
+		expensesDB:   expensesDB,
+		usersDB:      usersDB,
+		ratesDB:      ratesDB,
+		budgetsDB:    budgetsDB,
+		importsDB:    importsDB,
+		accountsDB:   accountsDB,
+		groupsDB:     groupsDB,
+		categoriesDB: categoriesDB,
+		txManager:    txManager,
 	}
 }
 
+// SetBudgetChecker sets who warns about the budgets when a category is chosen with the buttons.
+func (s *Model) SetBudgetChecker(checker budgetChecker) {
+	s.budgetChecker = checker
+}
+
 type CallbackData struct {
-	FromID     int64
+	FromID     int64 // The chat, its ledger is shared by the members in a group.
+	MemberID   int64 // Who pressed the button.
 	MessageID  int
 	Data       string
 	CallbackID string
@@ -87,16 +195,30 @@ func (s *Model) IncomingCallback(ctx context.Context, data *CallbackData) error
 	span.SetTag("callback", data.Data)
 	defer span.Finish()
 
-	switch data.Data {
+	action, arg, _ := strings.Cut(data.Data, ":")
+
+	switch action {
 	case ChangeExpenseSum:
 		return s.toWriteSumState(ctx, data)
 
 	case ChangeExpenseCategory:
-		return s.toWriteCategoryState(ctx, data)
+		return s.chooseExpenseCategory(ctx, data)
+
+	case SelectExpenseCategory:
+		return s.changeExpenseCategory(ctx, data, arg)
+
+	case ReportCategory:
+		return s.sendCategoryReport(ctx, data, arg)
 
 	case ChangeExpenseDate:
 		return s.toWriteDateState(ctx, data)
 
+	case ChangeExpenseAccount:
+		return s.chooseExpenseAccount(ctx, data)
+
+	case SelectExpenseAccount:
+		return s.changeExpenseAccount(ctx, data, arg)
+
 	case ChangeExpenseDone:
 		return s.saveExpense(data)
 
@@ -112,8 +234,47 @@ func (s *Model) IncomingCallback(ctx context.Context, data *CallbackData) error
 	case GetYearReport:
 		return s.getYearReport(ctx, data)
 
+	case GetCalendarMonthReport:
+		return s.getCalendarMonthReport(ctx, data)
+
+	case GetPreviousMonthReport:
+		return s.getPreviousMonthReport(ctx, data)
+
+	case GetQuarterReport:
+		return s.getQuarterReport(ctx, data)
+
+	case GetYearToDateReport:
+		return s.getYearToDateReport(ctx, data)
+
+	case ToggleReportMode:
+		return s.toggleReportMode(ctx, data)
+
+	case ToggleReportCurrency:
+		return s.toggleReportCurrency(ctx, data)
+
+	case HistoryPage:
+		return s.showHistoryPage(ctx, data, arg)
+
+	case HistoryEdit:
+		return s.editHistoryExpense(ctx, data, arg)
+
+	case HistoryDelete:
+		return s.deleteHistoryExpense(ctx, data, arg)
+
+	case ImportConfirm:
+		return s.confirmImport(ctx, data, arg)
+
+	case ImportCancel:
+		return s.cancelImport(ctx, data, arg)
+
+	case CurrencyPage:
+		return s.showCurrencyPage(ctx, data, arg)
+
+	case SelectCurrency:
+		return s.changeCurrentCurrency(ctx, data, types.Currency(arg))
+
 	case USD, CNY, EUR, RUB:
-		return s.changeCurrentCurrency(ctx, data)
+		return s.changeCurrentCurrency(ctx, data, types.Currency(data.Data))
 	}
 
 	return errors.New("Callback handler for data '" + data.Data + "' was not found.")
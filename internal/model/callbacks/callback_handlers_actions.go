@@ -3,6 +3,8 @@ package callbacks
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,6 +17,7 @@ func (s *Model) toWriteSumState(ctx context.Context, data *CallbackData) error {
 	err := s.usersDB.SetCurrentState(ctx, data.FromID, types.CurrentState{
 		ExpenseID: data.MessageID,
 		State:     types.EditingSum,
+		MemberID:  data.MemberID,
 	})
 
 	if err != nil {
@@ -25,27 +28,12 @@ func (s *Model) toWriteSumState(ctx context.Context, data *CallbackData) error {
 	return s.tgClient.ShowAlert("Введите сумму", data.CallbackID)
 }
 
-func (s *Model) toWriteCategoryState(ctx context.Context, data *CallbackData) error {
-	// Change state of the user - he is now entering sum for this user and this messageID.
-
-	err := s.usersDB.SetCurrentState(ctx, data.FromID, types.CurrentState{
-		ExpenseID: data.MessageID,
-		State:     types.EditingCategory,
-	})
-
-	if err != nil {
-		return errors.Wrap(err, "cannot SetCurrentState")
-	}
-
-	// Show notification about the action.
-	return s.tgClient.ShowAlert("Введите категорию", data.CallbackID)
-}
-
 func (s *Model) toWriteDateState(ctx context.Context, data *CallbackData) error {
 	// Change state of the user - he is now entering sum for this user and this messageID.
 	err := s.usersDB.SetCurrentState(ctx, data.FromID, types.CurrentState{
 		ExpenseID: data.MessageID,
 		State:     types.EditingDate,
+		MemberID:  data.MemberID,
 	})
 
 	if err != nil {
@@ -88,27 +76,363 @@ func (s *Model) getYearReport(ctx context.Context, data *CallbackData) error {
 	return s.getReport(ctx, data, dateBegin, dateEnd)
 }
 
+func (s *Model) getCalendarMonthReport(ctx context.Context, data *CallbackData) error {
+	dateBegin, dateEnd := calendarMonth(time.Now())
+	return s.getReport(ctx, data, dateBegin, dateEnd)
+}
+
+func (s *Model) getPreviousMonthReport(ctx context.Context, data *CallbackData) error {
+	dateBegin, dateEnd := previousMonth(time.Now())
+	return s.getReport(ctx, data, dateBegin, dateEnd)
+}
+
+func (s *Model) getQuarterReport(ctx context.Context, data *CallbackData) error {
+	dateBegin, dateEnd := quarter(time.Now())
+	return s.getReport(ctx, data, dateBegin, dateEnd)
+}
+
+func (s *Model) getYearToDateReport(ctx context.Context, data *CallbackData) error {
+	dateBegin, dateEnd := yearToDate(time.Now())
+	return s.getReport(ctx, data, dateBegin, dateEnd)
+}
+
 func (s *Model) getReport(ctx context.Context, data *CallbackData, dateBegin, dateEnd time.Time) error {
-	report, err := s.expensesDB.GetReport(ctx, data.FromID, dateBegin, dateEnd)
+	return s.SendReport(ctx, data.FromID, dateBegin, dateEnd)
+}
+
+// SendReport sends the report of the user's expenses for an arbitrary period
+// as text or as charts depending on the user's choice.
+func (s *Model) SendReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) error {
+	dateBegin, dateEnd = getDayBegin(dateBegin), getDayEnd(dateEnd)
+
+	report, err := s.convertedReport(ctx, userID, dateBegin, dateEnd)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot GetReport")
+		return errors.Wrap(err, "cannot convertedReport")
 	}
 
-	reportMessage, err := s.reportMessage(ctx, report, dateBegin, dateEnd, data.FromID)
+	mode, err := s.usersDB.GetReportMode(ctx, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetReportMode")
+	}
+
+	if mode == types.ReportChart {
+		return s.sendChartReport(ctx, report, dateBegin, dateEnd, userID)
+	}
+
+	categories, err := s.categoriesDB.GetCategories(ctx, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	reportMessage, err := s.reportMessage(ctx, report, dateBegin, dateEnd, userID, types.CategoryParents(categories))
 
 	if err != nil {
 		return errors.Wrap(err, "cannot reportMessage")
 	}
 
-	return s.tgClient.SendMessage(reportMessage, data.FromID)
+	return s.tgClient.SendReportMessage(reportMessage, userID, reportDrillDowns(report, categories, dateBegin, dateEnd))
+}
+
+func (s *Model) reportMessage(ctx context.Context, report *convertedReport, dateBegin, dateEnd time.Time, userID int64,
+	parents map[string]string) (string, error) {
+	result := reportHeader(report, dateBegin, dateEnd)
+	result += categoryLines(report.categories, report.originals, parents)
+
+	if len(report.income) > 0 {
+		result += "\nДоходы:\n" + categoryLines(report.income, report.incomeOriginals, parents)
+	}
+
+	result += "\n" + totalLines(report)
+
+	if types.IsGroupChat(userID) {
+		members, err := s.membersMessage(ctx, userID, dateBegin, dateEnd, report.currencyRate)
+
+		if err != nil {
+			return "", errors.Wrap(err, "cannot membersMessage")
+		}
+
+		result += members
+	}
+
+	budgets, err := s.budgetsMessage(ctx, userID, dateEnd, report.currencyRate)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot budgetsMessage")
+	}
+
+	return result + budgets, nil
+}
+
+// membersMessage shows how much each member of the group paid in the period.
+func (s *Model) membersMessage(ctx context.Context, chatID int64, dateBegin, dateEnd time.Time, rate types.Rate) (string, error) {
+	sums, err := s.expensesDB.GetMemberReport(ctx, chatID, dateBegin, dateEnd)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetMemberReport")
+	}
+
+	members, err := s.groupsDB.GetMembers(ctx, chatID)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetMembers")
+	}
+
+	return memberLines(sums, members, rate), nil
+}
+
+// memberLines lists the members from the one who paid the most, the expenses written before
+// the bot knew who paid are shown apart.
+func memberLines(sums map[int64]int, members []types.Member, rate types.Rate) string {
+	if len(sums) == 0 {
+		return ""
+	}
+
+	names := make(map[int64]string, len(members))
+	for _, member := range members {
+		names[member.ID] = member.Name
+	}
+
+	ids := make([]int64, 0, len(sums))
+	for id := range sums {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if sums[ids[i]] != sums[ids[j]] {
+			return sums[ids[i]] > sums[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	result := "\nПо участникам:\n"
+
+	for _, id := range ids {
+		name, ok := names[id]
+
+		switch {
+		case id == 0:
+			name = "Не указан"
+		case !ok || name == "":
+			name = fmt.Sprintf("Участник %d", id)
+		}
+
+		result += fmt.Sprintf("%s: %.2f\n", name, rate.FromKopecks(sums[id]))
+	}
+
+	return result
+}
+
+// categoryLines lists the categories from the most expensive one as a tree: a parent category
+// shows the subtotal of its subcategories, which follow it indented.
+func categoryLines(categories map[string]float64, originals map[string]map[types.Currency]int,
+	parents map[string]string) string {
+	tops := make(map[string]float64)
+	children := make(map[string]map[string]float64)
+
+	for category, sum := range categories {
+		top, ok := parents[category]
+		if !ok {
+			top = category
+		} else {
+			if children[top] == nil {
+				children[top] = make(map[string]float64)
+			}
+			children[top][category] = sum
+		}
+
+		tops[top] += sum
+	}
+
+	result := ""
+
+	for _, top := range sortedCategories(tops) {
+		if len(children[top]) == 0 {
+			result += categoryLine("", top, categories[top], originals, originals[top])
+			continue
+		}
+
+		subtotal := make(map[types.Currency]int)
+		addSums(subtotal, originals[top])
+		for child := range children[top] {
+			addSums(subtotal, originals[child])
+		}
+
+		result += categoryLine("", top, tops[top], originals, subtotal)
+
+		// Expenses written right into the parent category.
+		if sum, ok := categories[top]; ok {
+			children[top][top] = sum
+		}
+
+		for _, child := range sortedCategories(children[top]) {
+			name := child
+			if child == top {
+				name = "без подкатегории"
+			}
+
+			result += categoryLine("  • ", name, children[top][child], originals, originals[child])
+		}
+	}
+
+	return result
+}
+
+// categoryLine shows the sum of the category, or the sums by currency if the report keeps them.
+func categoryLine(indent, category string, sum float64, originals map[string]map[types.Currency]int,
+	sums map[types.Currency]int) string {
+	if originals == nil {
+		return fmt.Sprintf("%s%s: %.2f\n", indent, category, sum)
+	}
+
+	currencies := make([]string, 0, len(sums))
+	for currency := range sums {
+		currencies = append(currencies, string(currency))
+	}
+	sort.Strings(currencies)
+
+	parts := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		parts = append(parts, fmt.Sprintf("%.2f %s", float64(sums[types.Currency(currency)])/100, currency))
+	}
+
+	return fmt.Sprintf("%s%s: %s\n", indent, category, strings.Join(parts, " + "))
+}
+
+func addSums(to, sums map[types.Currency]int) {
+	for currency, sum := range sums {
+		to[currency] += sum
+	}
+}
+
+// totalLines are the sums of the expenses and income of the report and the difference of them.
+func totalLines(report *convertedReport) string {
+	expenses, income := total(report.categories), total(report.income)
+	return fmt.Sprintf("Расходы: %.2f\nДоходы: %.2f\nИтого: %+.2f\n", expenses, income, income-expenses)
 }
 
-func (s *Model) reportMessage(ctx context.Context, report map[string]int, dateBegin, dateEnd time.Time, userID int64) (string, error) {
-	result := fmt.Sprintf("Отчет в период с %s по %s\n\n",
+func total(categories map[string]float64) float64 {
+	result := 0.0
+	for _, sum := range categories {
+		result += sum
+	}
+
+	return result
+}
+
+var reportCurrencyModeNames = map[types.ReportCurrencyMode]string{
+	types.ReportOriginal:    "в валюте трат",
+	types.ReportExpenseDate: "по курсу на дату трат",
+	types.ReportToday:       "по сегодняшнему курсу",
+}
+
+func reportHeader(report *convertedReport, dateBegin, dateEnd time.Time) string {
+	return fmt.Sprintf("Отчет в период с %s по %s, %s %s\n\n",
 		dateBegin.Format("2006-01-02"),
-		dateEnd.Format("2006-01-02"))
+		dateEnd.Format("2006-01-02"),
+		report.currency,
+		reportCurrencyModeNames[report.mode])
+}
+
+// convertedReport is the report in the currency the user works with now.
+type convertedReport struct {
+	mode         types.ReportCurrencyMode
+	currency     types.Currency
+	currencyRate types.Rate // Today's rate of the currency.
+	categories   map[string]float64
+	days         map[time.Time]float64
+	originals    map[string]map[types.Currency]int // Hundredths by currency, only in the original mode.
+
+	// Income is kept apart from the expenses and is not in the days.
+	income          map[string]float64
+	incomeOriginals map[string]map[types.Currency]int
+}
+
+// convertedReport converts the expenses to the user's currency at the rate chosen by the user.
+// Expenses entered in the user's currency are never converted.
+func (s *Model) convertedReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) (*convertedReport, error) {
+	mode, err := s.usersDB.GetReportCurrencyMode(ctx, userID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot GetReportCurrencyMode")
+	}
+
+	currency, currencyRate, err := s.reportCurrency(ctx, userID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot reportCurrency")
+	}
+
+	rows, err := s.expensesDB.GetReportRows(ctx, userID, dateBegin, dateEnd)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot GetReportRows")
+	}
+
+	rates := map[time.Time]types.Rate{}
+	if mode != types.ReportToday {
+		rates, err = s.ratesDB.GetCurrencyRates(ctx, currency, dateBegin, dateEnd)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot GetCurrencyRates")
+		}
+	}
+
+	report := &convertedReport{
+		mode:         mode,
+		currency:     currency,
+		currencyRate: currencyRate,
+		categories:   make(map[string]float64),
+		days:         make(map[time.Time]float64),
+		income:       make(map[string]float64),
+	}
+
+	if mode == types.ReportOriginal {
+		report.originals = make(map[string]map[types.Currency]int)
+		report.incomeOriginals = make(map[string]map[types.Currency]int)
+	}
 
+	for _, row := range rows {
+		sum := convertRow(row, currency, rates, currencyRate)
+
+		categories, originals := report.categories, report.originals
+		if row.Kind == types.KindIncome {
+			categories, originals = report.income, report.incomeOriginals
+		} else {
+			report.days[row.Date] += sum
+		}
+
+		categories[row.Category] += sum
+
+		if originals != nil {
+			if originals[row.Category] == nil {
+				originals[row.Category] = make(map[types.Currency]int)
+			}
+			originals[row.Category][row.OriginalCurrency] += row.OriginalSum
+		}
+	}
+
+	return report, nil
+}
+
+// convertRow returns the sum of the row in the currency, the amount entered in it is taken as is.
+func convertRow(row types.ReportRow, currency types.Currency, rates map[time.Time]types.Rate,
+	currencyRate types.Rate) float64 {
+	if row.OriginalCurrency == currency {
+		return float64(row.OriginalSum) / 100
+	}
+
+	if rate, ok := types.RateOnDate(rates, row.Date); ok {
+		return rate.FromKopecks(row.Sum)
+	}
+
+	// The rate of the date is unknown or today's rate is chosen.
+	return currencyRate.FromKopecks(row.Sum)
+}
+
+// reportCurrency returns the currency the user works with now and its rate.
+func (s *Model) reportCurrency(ctx context.Context, userID int64) (types.Currency, types.Rate, error) {
 	st, ok := s.usersDB.GetCurrentState(ctx, userID)
 	currentCurrency := types.RUB
 	if ok && st.Currency != "" {
@@ -118,40 +442,99 @@ func (s *Model) reportMessage(ctx context.Context, report map[string]int, dateBe
 	currencyRate, err := s.ratesDB.GetCurrencyRate(ctx, currentCurrency, time.Now())
 
 	if err != nil {
-		return "", errors.Wrap(err, "cannot GetCurrencyRate")
+		return "", 0, errors.Wrap(err, "cannot GetCurrencyRate")
 	}
 
-	for category, sum := range report {
-		result += fmt.Sprintf("%s: %.2f\n", category, float64(sum)/float64(currencyRate))
+	return currentCurrency, currencyRate, nil
+}
+
+// sortedCategories returns the categories of the report from the most expensive one.
+func sortedCategories(report map[string]float64) []string {
+	categories := make([]string, 0, len(report))
+	for category := range report {
+		categories = append(categories, category)
 	}
 
-	return result, nil
+	sort.Slice(categories, func(i, j int) bool {
+		if report[categories[i]] != report[categories[j]] {
+			return report[categories[i]] > report[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	return categories
 }
 
-func getDayBegin(date time.Time) time.Time {
-	date.Add(-time.Duration(date.Hour()) * time.Hour)
-	date.Add(-time.Duration(date.Minute()) * time.Minute)
-	date.Add(-time.Duration(date.Second()) * time.Second)
+// budgetsMessage compares budgets with the actual expenses of the month the report ends in.
+func (s *Model) budgetsMessage(ctx context.Context, userID int64, date time.Time, currencyRate types.Rate) (string, error) {
+	budgets, err := s.budgetsDB.GetBudgets(ctx, userID, date)
 
-	return date
-}
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetBudgets")
+	}
 
-func getDayEnd(date time.Time) time.Time {
-	date.Add(time.Duration(23-date.Hour()) * time.Hour)
-	date.Add(time.Duration(59-date.Minute()) * time.Minute)
-	date.Add(time.Duration(59-date.Second()) * time.Second)
+	if len(budgets) == 0 {
+		return "", nil
+	}
 
-	return date
+	monthBegin, monthEnd := calendarMonth(date)
+	spent, err := s.expensesDB.GetReport(ctx, userID, monthBegin, monthEnd, types.CategoryLevelLeaf)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetReport")
+	}
+
+	// The budget of a parent category covers its subcategories.
+	parentSpent, err := s.expensesDB.GetReport(ctx, userID, monthBegin, monthEnd, types.CategoryLevelParent)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetReport")
+	}
+
+	for category, sum := range parentSpent {
+		spent[category] = sum
+	}
+
+	categories := make([]string, 0, len(budgets))
+	for category := range budgets {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	result := fmt.Sprintf("\nБюджеты на %s:\n", monthBegin.Format("2006-01"))
+	for _, category := range categories {
+		result += fmt.Sprintf("%s: %.2f из %.2f (%d%%)\n",
+			category,
+			currencyRate.FromKopecks(spent[category]),
+			currencyRate.FromKopecks(budgets[category]),
+			spent[category]*100/budgets[category],
+		)
+	}
+
+	return result, nil
 }
 
-func (s *Model) changeCurrentCurrency(ctx context.Context, data *CallbackData) error {
-	err := s.usersDB.SetUserCurrency(ctx, data.FromID, types.Currency(data.Data))
+// changeCurrentCurrency sets the currency of the button, the data of which may be forged or outdated.
+func (s *Model) changeCurrentCurrency(ctx context.Context, data *CallbackData, currency types.Currency) error {
+	infos, err := s.ratesDB.GetCurrencies(ctx)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCurrencies")
+	}
+
+	currency, ok := types.NewCurrencies(infos).Parse(string(currency))
+
+	if !ok {
+		return s.tgClient.ShowAlert("Валюта не найдена", data.CallbackID)
+	}
+
+	err = s.usersDB.SetUserCurrency(ctx, data.FromID, currency)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot SetUserCurrency")
 	}
 
-	currency, err := s.usersDB.GetUserCurrency(ctx, data.FromID)
+	currency, err = s.usersDB.GetUserCurrency(ctx, data.FromID)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot GetUserCurrency")
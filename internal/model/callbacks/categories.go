@@ -0,0 +1,249 @@
+package callbacks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// chooseExpenseCategory shows the categories on the expense card instead of the edit buttons.
+// A new category can still be typed.
+func (s *Model) chooseExpenseCategory(ctx context.Context, data *CallbackData) error {
+	err := s.usersDB.SetCurrentState(ctx, data.FromID, types.CurrentState{
+		ExpenseID: data.MessageID,
+		State:     types.EditingCategory,
+		MemberID:  data.MemberID,
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetCurrentState")
+	}
+
+	categories, err := s.categoriesDB.GetCategories(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	err = s.tgClient.EditExpenseCategories(data.FromID, data.MessageID, activeCategories(categories))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot EditExpenseCategories")
+	}
+
+	return s.tgClient.ShowAlert("Выберите категорию или введите новую", data.CallbackID)
+}
+
+// activeCategories are the categories which are not archived.
+func activeCategories(categories []types.Category) []types.Category {
+	result := make([]types.Category, 0, len(categories))
+
+	for _, category := range categories {
+		if !category.Archived {
+			result = append(result, category)
+		}
+	}
+
+	return result
+}
+
+// changeExpenseCategory sets the chosen category and brings the edit buttons back.
+func (s *Model) changeExpenseCategory(ctx context.Context, data *CallbackData, arg string) error {
+	categoryID, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	err = s.usersDB.ToWaitState(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ToWaitState")
+	}
+
+	expense, err := s.expensesDB.GetExpense(ctx, data.FromID, data.MessageID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetExpense")
+	}
+
+	written := expense != nil
+	if !written {
+		// The card has not been edited yet, so the expense is not written.
+		expense, err = s.newCardExpense(ctx, data)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot newCardExpense")
+		}
+	}
+
+	if categoryID != 0 {
+		categories, err := s.categoriesDB.GetCategories(ctx, data.FromID)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot GetCategories")
+		}
+
+		category, ok := findCategoryByID(categories, categoryID)
+		if !ok {
+			return s.tgClient.ShowAlert("Категория не найдена", data.CallbackID)
+		}
+
+		var previous *types.Expense
+		if written {
+			counted := *expense
+			previous = &counted
+		}
+
+		expense.Category = category.Name
+
+		if written {
+			err = s.expensesDB.WriteCategory(ctx, expense.Category, data.FromID, data.MessageID)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot WriteCategory")
+			}
+		} else {
+			err = s.expensesDB.WriteExpense(ctx, data.FromID, expense)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot WriteExpense")
+			}
+		}
+
+		if s.budgetChecker != nil {
+			err = s.budgetChecker.CheckCategoryBudget(ctx, data.FromID, expense, previous)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot CheckCategoryBudget")
+			}
+		}
+	}
+
+	userModel, err := s.getUserModel(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	return s.tgClient.EditExpenseMessage(expense.ToString(userModel), data.FromID, data.MessageID)
+}
+
+func findCategoryByID(categories []types.Category, id int) (types.Category, bool) {
+	for _, category := range categories {
+		if category.ID == id {
+			return category, true
+		}
+	}
+
+	return types.Category{}, false
+}
+
+// reportDrillDowns returns the buttons of the parent categories which have subcategories in the report.
+func reportDrillDowns(report *convertedReport, categories []types.Category, dateBegin, dateEnd time.Time) []types.ReportDrillDown {
+	parents := types.CategoryParents(categories)
+
+	subtotals := make(map[string]float64)
+	for category, sum := range report.categories {
+		if parent, ok := parents[category]; ok {
+			subtotals[parent] += sum
+		}
+	}
+
+	var drillDowns []types.ReportDrillDown
+
+	for _, name := range sortedCategories(subtotals) {
+		category, ok := types.FindCategory(categories, name)
+		if !ok {
+			continue
+		}
+
+		drillDowns = append(drillDowns, types.ReportDrillDown{
+			CategoryID: category.ID,
+			Title:      category.Title(),
+			DateBegin:  dateBegin,
+			DateEnd:    dateEnd,
+		})
+	}
+
+	return drillDowns
+}
+
+// sendCategoryReport shows the subcategories of the parent category for the period of the report.
+func (s *Model) sendCategoryReport(ctx context.Context, data *CallbackData, arg string) error {
+	args := strings.Split(arg, ":")
+	if len(args) != 3 {
+		return errors.New("report category data is incorrect")
+	}
+
+	categoryID, err := strconv.Atoi(args[0])
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	dateBegin, err := time.Parse("20060102", args[1])
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Parse")
+	}
+
+	dateEnd, err := time.Parse("20060102", args[2])
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Parse")
+	}
+
+	dateBegin, dateEnd = getDayBegin(dateBegin), getDayEnd(dateEnd)
+
+	categories, err := s.categoriesDB.GetCategories(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	parent, ok := findCategoryByID(categories, categoryID)
+	if !ok {
+		return s.tgClient.ShowAlert("Категория не найдена", data.CallbackID)
+	}
+
+	report, err := s.convertedReport(ctx, data.FromID, dateBegin, dateEnd)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot convertedReport")
+	}
+
+	return s.tgClient.SendMessage(categoryReportMessage(report, parent, types.CategoryParents(categories), dateBegin, dateEnd), data.FromID)
+}
+
+// categoryReportMessage lists the subcategories of the parent with their shares of its subtotal.
+func categoryReportMessage(report *convertedReport, parent types.Category, parents map[string]string,
+	dateBegin, dateEnd time.Time) string {
+	sums := make(map[string]float64)
+	for category, sum := range report.categories {
+		if category == parent.Name || parents[category] == parent.Name {
+			sums[category] = sum
+		}
+	}
+
+	subtotal := total(sums)
+
+	result := fmt.Sprintf("%s с %s по %s, %s\n\n", parent.Title(),
+		dateBegin.Format("2006-01-02"), dateEnd.Format("2006-01-02"), report.currency)
+
+	for _, category := range sortedCategories(sums) {
+		name := category
+		if category == parent.Name {
+			name = "без подкатегории"
+		}
+
+		result += fmt.Sprintf("%s: %.2f (%.0f%%)\n", name, sums[category], sums[category]*100/subtotal)
+	}
+
+	return result + fmt.Sprintf("\nВсего: %.2f\n", subtotal)
+}
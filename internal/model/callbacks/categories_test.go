@@ -0,0 +1,49 @@
+package callbacks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_CategoryLines_ShouldNestSubcategories(t *testing.T) {
+	sums := map[string]float64{"Такси": 300, "Метро": 100, "Транспорт": 50, "Кафе": 200}
+	parents := map[string]string{"Такси": "Транспорт", "Метро": "Транспорт"}
+
+	result := categoryLines(sums, nil, parents)
+
+	assert.Equal(t, "Транспорт: 450.00\n"+
+		"  • Такси: 300.00\n"+
+		"  • Метро: 100.00\n"+
+		"  • без подкатегории: 50.00\n"+
+		"Кафе: 200.00\n", result)
+}
+
+func Test_CategoryReportMessage_ShouldShowShares(t *testing.T) {
+	categories := []types.Category{
+		{ID: 1, Name: "Транспорт", Emoji: "🚕"},
+		{ID: 2, Name: "Такси", ParentID: 1},
+		{ID: 3, Name: "Кафе"},
+	}
+	report := &convertedReport{
+		currency:   "RUB",
+		categories: map[string]float64{"Такси": 300, "Транспорт": 100, "Кафе": 200},
+	}
+	dateBegin := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dateEnd := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	drillDowns := reportDrillDowns(report, categories, dateBegin, dateEnd)
+
+	assert.Equal(t, []types.ReportDrillDown{
+		{CategoryID: 1, Title: "🚕 Транспорт", DateBegin: dateBegin, DateEnd: dateEnd},
+	}, drillDowns)
+
+	result := categoryReportMessage(report, categories[0], types.CategoryParents(categories), dateBegin, dateEnd)
+
+	assert.Equal(t, "🚕 Транспорт с 2026-10-01 по 2026-10-31, RUB\n\n"+
+		"Такси: 300.00 (75%)\n"+
+		"без подкатегории: 100.00 (25%)\n"+
+		"\nВсего: 400.00\n", result)
+}
@@ -0,0 +1,233 @@
+package callbacks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/charts"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const otherCategories = "Остальное"
+
+func (s *Model) toggleReportMode(ctx context.Context, data *CallbackData) error {
+	mode, err := s.usersDB.GetReportMode(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetReportMode")
+	}
+
+	newMode, alert := types.ReportChart, "Отчеты будут приходить графиками"
+	if mode == types.ReportChart {
+		newMode, alert = types.ReportText, "Отчеты будут приходить текстом"
+	}
+
+	err = s.usersDB.SetReportMode(ctx, data.FromID, newMode)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetReportMode")
+	}
+
+	return s.tgClient.ShowAlert(alert, data.CallbackID)
+}
+
+var nextReportCurrencyMode = map[types.ReportCurrencyMode]types.ReportCurrencyMode{
+	types.ReportExpenseDate: types.ReportToday,
+	types.ReportToday:       types.ReportOriginal,
+	types.ReportOriginal:    types.ReportExpenseDate,
+}
+
+// toggleReportCurrency switches how the reports convert expenses entered in other currencies.
+func (s *Model) toggleReportCurrency(ctx context.Context, data *CallbackData) error {
+	mode, err := s.usersDB.GetReportCurrencyMode(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetReportCurrencyMode")
+	}
+
+	newMode, ok := nextReportCurrencyMode[mode]
+	if !ok {
+		newMode = types.ReportExpenseDate
+	}
+
+	err = s.usersDB.SetReportCurrencyMode(ctx, data.FromID, newMode)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetReportCurrencyMode")
+	}
+
+	return s.tgClient.ShowAlert("Суммы в отчетах: "+reportCurrencyModeNames[newMode], data.CallbackID)
+}
+
+// sendChartReport sends a pie chart of the expenses by category and a bar chart of them by day, week or month.
+// The income is not charted, its sum and the difference with the expenses are in the caption.
+func (s *Model) sendChartReport(ctx context.Context, report *convertedReport, dateBegin, dateEnd time.Time, userID int64) error {
+	header := reportHeader(report, dateBegin, dateEnd)
+
+	if len(report.categories) == 0 {
+		return s.tgClient.SendMessage(header+"Трат нет\n\n"+totalLines(report), userID)
+	}
+
+	categories, sums := pieSlices(report.categories)
+
+	pie, err := charts.Pie(sums)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Pie")
+	}
+
+	err = s.tgClient.SendPhoto("report.png", pie, header+pieCaption(report, categories, sums), userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SendPhoto")
+	}
+
+	starts, bucketSums, unit := bucketDailySums(report.days, dateBegin, dateEnd)
+
+	maxIndex := 0
+	for i, sum := range bucketSums {
+		if sum > bucketSums[maxIndex] {
+			maxIndex = i
+		}
+	}
+
+	bar, err := charts.Bar(bucketSums)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Bar")
+	}
+
+	caption := fmt.Sprintf("Траты по %s, больше всего %s: %.2f %s",
+		unit.name,
+		starts[maxIndex].Format(unit.layout),
+		bucketSums[maxIndex],
+		report.currency,
+	)
+
+	err = s.tgClient.SendPhoto("report_by_date.png", bar, caption, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SendPhoto")
+	}
+
+	budgets, err := s.budgetsMessage(ctx, userID, dateEnd, report.currencyRate)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot budgetsMessage")
+	}
+
+	if budgets == "" {
+		return nil
+	}
+
+	return s.tgClient.SendMessage(budgets, userID)
+}
+
+// pieCaption names the slices of the pie by their colors and adds the totals of the report.
+func pieCaption(report *convertedReport, categories []string, sums []float64) string {
+	expenses := total(report.categories)
+
+	caption := ""
+	for i, category := range categories {
+		caption += fmt.Sprintf("%s %s: %.2f %s (%.0f%%)\n",
+			charts.Palette[i].Emoji,
+			category,
+			sums[i],
+			report.currency,
+			sums[i]*100/expenses,
+		)
+	}
+
+	return caption + "\n" + totalLines(report)
+}
+
+// pieSlices returns the most expensive categories, the ones which do not fit the palette are merged.
+func pieSlices(report map[string]float64) ([]string, []float64) {
+	categories := sortedCategories(report)
+
+	sums := make([]float64, len(categories))
+	for i, category := range categories {
+		sums[i] = report[category]
+	}
+
+	if len(categories) > len(charts.Palette) {
+		last := len(charts.Palette) - 1
+		for _, sum := range sums[last+1:] {
+			sums[last] += sum
+		}
+
+		categories = append(categories[:last], otherCategories)
+		sums = sums[:last+1]
+	}
+
+	return categories, sums
+}
+
+type bucketUnit struct {
+	name   string
+	layout string
+	start  func(date time.Time) time.Time
+	next   func(date time.Time) time.Time
+}
+
+var (
+	dayUnit = bucketUnit{
+		name:   "дням",
+		layout: "2006-01-02",
+		start:  func(date time.Time) time.Time { return date },
+		next:   func(date time.Time) time.Time { return date.AddDate(0, 0, 1) },
+	}
+	weekUnit = bucketUnit{
+		name:   "неделям",
+		layout: "неделя с 2006-01-02",
+		start: func(date time.Time) time.Time {
+			return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+		},
+		next: func(date time.Time) time.Time { return date.AddDate(0, 0, 7) },
+	}
+	monthUnit = bucketUnit{
+		name:   "месяцам",
+		layout: "2006-01",
+		start: func(date time.Time) time.Time {
+			return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		},
+		next: func(date time.Time) time.Time { return date.AddDate(0, 1, 0) },
+	}
+)
+
+// bucketDailySums sums the expenses by day for a month, by week for a quarter and by month otherwise.
+func bucketDailySums(daily map[time.Time]float64, dateBegin, dateEnd time.Time) ([]time.Time, []float64, bucketUnit) {
+	begin := time.Date(dateBegin.Year(), dateBegin.Month(), dateBegin.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(dateEnd.Year(), dateEnd.Month(), dateEnd.Day(), 0, 0, 0, 0, time.UTC)
+
+	unit := monthUnit
+	switch days := end.Sub(begin).Hours() / 24; {
+	case days <= 31:
+		unit = dayUnit
+	case days <= 92:
+		unit = weekUnit
+	}
+
+	var (
+		starts []time.Time
+		sums   []float64
+	)
+
+	index := make(map[time.Time]int)
+	for start := unit.start(begin); !start.After(end); start = unit.next(start) {
+		index[start] = len(starts)
+		starts = append(starts, start)
+		sums = append(sums, 0)
+	}
+
+	for date, sum := range daily {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if i, ok := index[unit.start(day)]; ok {
+			sums[i] += sum
+		}
+	}
+
+	return starts, sums, unit
+}
@@ -0,0 +1,61 @@
+package callbacks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func TestPieSlices_MergesCategoriesOutOfPalette(t *testing.T) {
+	report := map[string]float64{
+		"a": 100, "b": 90, "c": 80, "d": 70, "e": 60, "f": 50, "g": 40, "h": 30, "i": 20,
+	}
+
+	categories, sums := pieSlices(report)
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", otherCategories}, categories)
+	assert.Equal(t, []float64{100, 90, 80, 70, 60, 50, 40, 50}, sums)
+}
+
+func TestPieCaption_ShouldShowIncomeAndNet(t *testing.T) {
+	report := &convertedReport{
+		currency:   types.RUB,
+		categories: map[string]float64{"кафе": 300, "такси": 100},
+		income:     map[string]float64{"зарплата": 1000},
+	}
+
+	categories, sums := pieSlices(report.categories)
+
+	assert.Equal(t, "🟥 кафе: 300.00 RUB (75%)\n🟧 такси: 100.00 RUB (25%)\n\n"+
+		"Расходы: 400.00\nДоходы: 1000.00\nИтого: +600.00\n", pieCaption(report, categories, sums))
+}
+
+func TestBucketDailySums(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	daily := map[time.Time]float64{
+		day(10, 1):  100,
+		day(10, 5):  200,
+		day(10, 6):  300,
+		day(10, 31): 400,
+	}
+
+	starts, sums, unit := bucketDailySums(daily, day(10, 1), day(10, 31))
+	assert.Equal(t, dayUnit.name, unit.name)
+	assert.Len(t, starts, 31)
+	assert.Equal(t, 300.0, sums[5])
+
+	// 2026-10-01 is Thursday, so the first week starts on Monday 2026-09-28.
+	starts, sums, unit = bucketDailySums(daily, day(10, 1), day(12, 1))
+	assert.Equal(t, weekUnit.name, unit.name)
+	assert.Equal(t, day(9, 28), starts[0])
+	assert.Equal(t, []float64{100, 500, 0, 0, 400}, sums[:5])
+
+	starts, sums, unit = bucketDailySums(daily, day(1, 1), day(12, 31))
+	assert.Equal(t, monthUnit.name, unit.name)
+	assert.Len(t, starts, 12)
+	assert.Equal(t, 1000.0, sums[9])
+}
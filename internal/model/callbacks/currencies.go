@@ -0,0 +1,103 @@
+package callbacks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	currencyPageSize = 12
+
+	// The query is kept in the data of the page buttons, which is limited to 64 bytes.
+	maxCurrencyQueryBytes = 40
+)
+
+// SendCurrencies sends the first page of the currencies with the query in the code or the name.
+func (s *Model) SendCurrencies(ctx context.Context, userID int64, query string) error {
+	text, page, err := s.currencyPage(ctx, truncateQuery(query), 0)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot currencyPage")
+	}
+
+	return s.tgClient.SendCurrencies(text, userID, page)
+}
+
+func (s *Model) showCurrencyPage(ctx context.Context, data *CallbackData, arg string) error {
+	pageArg, query, _ := strings.Cut(arg, ":")
+
+	pageNo, err := strconv.Atoi(pageArg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	text, page, err := s.currencyPage(ctx, query, pageNo)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot currencyPage")
+	}
+
+	return s.tgClient.EditCurrencies(text, data.FromID, data.MessageID, page)
+}
+
+func (s *Model) currencyPage(ctx context.Context, query string, pageNo int) (string, types.CurrencyPage, error) {
+	infos, err := s.ratesDB.GetCurrencies(ctx)
+
+	if err != nil {
+		return "", types.CurrencyPage{}, errors.Wrap(err, "cannot GetCurrencies")
+	}
+
+	found := types.NewCurrencies(infos).Search(query)
+
+	page := types.CurrencyPage{
+		Query:      query,
+		PagesCount: (len(found) + currencyPageSize - 1) / currencyPageSize,
+	}
+
+	if pageNo >= page.PagesCount {
+		pageNo = page.PagesCount - 1
+	}
+	if pageNo < 0 {
+		pageNo = 0
+	}
+	page.Page = pageNo
+
+	if len(found) == 0 {
+		return "Валюта «" + query + "» не найдена", page, nil
+	}
+
+	end := (pageNo + 1) * currencyPageSize
+	if end > len(found) {
+		end = len(found)
+	}
+	page.Currencies = found[pageNo*currencyPageSize : end]
+
+	result := "Выберите валюту, для поиска введите /currency и часть названия\n\n"
+	for _, currency := range page.Currencies {
+		result += fmt.Sprintf("%s - %s\n", currency.Code, currency.Name)
+	}
+
+	if page.PagesCount > 1 {
+		result += fmt.Sprintf("\nСтраница %d из %d", page.Page+1, page.PagesCount)
+	}
+
+	return result, page, nil
+}
+
+func truncateQuery(query string) string {
+	query = strings.TrimSpace(query)
+
+	for len(query) > maxCurrencyQueryBytes {
+		_, size := utf8.DecodeLastRuneInString(query)
+		query = query[:len(query)-size]
+	}
+
+	return query
+}
@@ -0,0 +1,207 @@
+package callbacks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const historyPageSize = 5
+
+// SendHistory sends the first page of the user's expenses matching the last /history filter.
+func (s *Model) SendHistory(ctx context.Context, userID int64) error {
+	text, page, err := s.historyPage(ctx, userID, 0)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot historyPage")
+	}
+
+	return s.tgClient.SendHistory(text, userID, page)
+}
+
+func (s *Model) showHistoryPage(ctx context.Context, data *CallbackData, arg string) error {
+	pageNo, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	text, page, err := s.historyPage(ctx, data.FromID, pageNo)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot historyPage")
+	}
+
+	return s.tgClient.EditHistory(text, data.FromID, data.MessageID, page)
+}
+
+// editHistoryExpense reopens the expense in a new card with the usual edit buttons.
+func (s *Model) editHistoryExpense(ctx context.Context, data *CallbackData, arg string) error {
+	expenseID, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	expense, err := s.expensesDB.GetExpense(ctx, data.FromID, expenseID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetExpense")
+	}
+
+	if expense == nil {
+		return s.tgClient.ShowAlert("Трата не найдена", data.CallbackID)
+	}
+
+	userModel, err := s.getUserModel(ctx, data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	// The card message id becomes the new id of the expense,
+	// so the card buttons work the same way as for a new expense.
+	cardID, err := s.tgClient.CreateExpense(expense.ToString(userModel), data.FromID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateExpense")
+	}
+
+	err = s.expensesDB.ChangeExpenseID(ctx, data.FromID, expenseID, cardID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ChangeExpenseID")
+	}
+
+	return nil
+}
+
+// deleteHistoryExpense deletes the expense and redraws the page it was on.
+func (s *Model) deleteHistoryExpense(ctx context.Context, data *CallbackData, arg string) error {
+	expenseArg, pageArg, _ := strings.Cut(arg, ":")
+
+	expenseID, err := strconv.Atoi(expenseArg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	err = s.expensesDB.DeleteExpense(ctx, data.FromID, expenseID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot DeleteExpense")
+	}
+
+	return s.showHistoryPage(ctx, data, pageArg)
+}
+
+func (s *Model) historyPage(ctx context.Context, userID int64, pageNo int) (string, types.HistoryPage, error) {
+	filter, err := s.usersDB.GetHistoryFilter(ctx, userID)
+
+	if err != nil {
+		return "", types.HistoryPage{}, errors.Wrap(err, "cannot GetHistoryFilter")
+	}
+
+	count, err := s.expensesDB.CountExpenses(ctx, userID, filter)
+
+	if err != nil {
+		return "", types.HistoryPage{}, errors.Wrap(err, "cannot CountExpenses")
+	}
+
+	page := types.HistoryPage{
+		PagesCount: (count + historyPageSize - 1) / historyPageSize,
+	}
+
+	// The page might disappear after deleting its last expense.
+	if pageNo >= page.PagesCount {
+		pageNo = page.PagesCount - 1
+	}
+	if pageNo < 0 {
+		pageNo = 0
+	}
+	page.Page = pageNo
+
+	page.Expenses, err = s.expensesDB.GetExpenses(ctx, userID, filter, historyPageSize, pageNo*historyPageSize)
+
+	if err != nil {
+		return "", types.HistoryPage{}, errors.Wrap(err, "cannot GetExpenses")
+	}
+
+	userModel, err := s.getUserModel(ctx, userID)
+
+	if err != nil {
+		return "", types.HistoryPage{}, errors.Wrap(err, "cannot getUserModel")
+	}
+
+	return historyMessage(filter, page, userModel), page, nil
+}
+
+func historyMessage(filter types.ExpenseFilter, page types.HistoryPage, userModel *types.UserModel) string {
+	result := "История трат"
+	if filter.Category != "" {
+		result += " в категории " + filter.Category
+	}
+	if !filter.DateBegin.IsZero() || !filter.DateEnd.IsZero() {
+		result += fmt.Sprintf(" с %s по %s", formatFilterDate(filter.DateBegin), formatFilterDate(filter.DateEnd))
+	}
+	result += "\n\n"
+
+	if len(page.Expenses) == 0 {
+		return result + "Трат не найдено"
+	}
+
+	for i, expense := range page.Expenses {
+		// Income is marked with a plus.
+		sign := ""
+		if expense.Kind == types.KindIncome {
+			sign = "+"
+		}
+
+		result += fmt.Sprintf("%d. %s %s: %s%.2f %s\n",
+			i+1,
+			expense.Date.Format("2006-01-02"),
+			expense.Category,
+			sign,
+			userModel.CurrencyRate.FromKopecks(expense.Sum),
+			userModel.Currency,
+		)
+	}
+
+	return result + fmt.Sprintf("\nСтраница %d из %d", page.Page+1, page.PagesCount)
+}
+
+func formatFilterDate(date time.Time) string {
+	if date.IsZero() {
+		return "..."
+	}
+
+	return date.Format("2006-01-02")
+}
+
+func (s *Model) getUserModel(ctx context.Context, userID int64) (*types.UserModel, error) {
+	currency, err := s.usersDB.GetUserCurrency(ctx, userID)
+
+	if err != nil {
+		if err != types.ErrNoCurrency {
+			return nil, errors.Wrap(err, "cannot GetUserCurrency")
+		}
+
+		currency = types.RUB
+	}
+
+	rate, err := s.ratesDB.GetCurrencyRate(ctx, currency, time.Now())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot GetCurrencyRate")
+	}
+
+	return &types.UserModel{
+		Currency:     string(currency),
+		CurrencyRate: rate,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package callbacks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const importNotFoundMsg = "Импорт не найден или уже обработан"
+
+// confirmImport writes the pending expenses in one transaction skipping the ones already written.
+func (s *Model) confirmImport(ctx context.Context, data *CallbackData, arg string) error {
+	importID, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	var (
+		found               bool
+		written, duplicates int
+	)
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		expenses, err := s.importsDB.TakePendingImport(ctx, data.FromID, importID)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot TakePendingImport")
+		}
+
+		found = expenses != nil
+
+		newExpenses, err := s.skipDuplicates(ctx, data.FromID, expenses)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot skipDuplicates")
+		}
+
+		for i := range newExpenses {
+			newExpenses[i].ExpenseID, err = s.expensesDB.NextExpenseID(ctx)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot NextExpenseID")
+			}
+
+			err = s.expensesDB.WriteExpense(ctx, data.FromID, &newExpenses[i])
+
+			if err != nil {
+				return errors.Wrap(err, "cannot WriteExpense")
+			}
+		}
+
+		written, duplicates = len(newExpenses), len(expenses)-len(newExpenses)
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "cannot RunInTx")
+	}
+
+	if !found {
+		return s.tgClient.ShowAlert(importNotFoundMsg, data.CallbackID)
+	}
+
+	return s.tgClient.EditMessage(
+		fmt.Sprintf("Импортировано трат: %d, пропущено повторов: %d", written, duplicates),
+		data.FromID, data.MessageID)
+}
+
+func (s *Model) cancelImport(ctx context.Context, data *CallbackData, arg string) error {
+	importID, err := strconv.Atoi(arg)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot Atoi")
+	}
+
+	expenses, err := s.importsDB.TakePendingImport(ctx, data.FromID, importID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot TakePendingImport")
+	}
+
+	if expenses == nil {
+		return s.tgClient.ShowAlert(importNotFoundMsg, data.CallbackID)
+	}
+
+	return s.tgClient.EditMessage("Импорт отменен", data.FromID, data.MessageID)
+}
+
+type sameExpenseKey struct {
+	date time.Time
+	sum  int
+}
+
+// skipDuplicates drops expenses with the date and sum already written.
+// Equal rows of one statement are counted, so two equal purchases in a day
+// are both imported unless both of them are already written.
+func (s *Model) skipDuplicates(ctx context.Context, userID int64, expenses []types.Expense) ([]types.Expense, error) {
+	existing := make(map[sameExpenseKey]int)
+	result := make([]types.Expense, 0, len(expenses))
+
+	for _, expense := range expenses {
+		key := sameExpenseKey{date: expense.Date.UTC(), sum: expense.Sum}
+
+		count, ok := existing[key]
+		if !ok {
+			var err error
+			count, err = s.expensesDB.CountSameExpenses(ctx, userID, expense.Date, expense.Sum)
+
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot CountSameExpenses")
+			}
+		}
+
+		if count > 0 {
+			existing[key] = count - 1
+			continue
+		}
+
+		existing[key] = 0
+		result = append(result, expense)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,18 @@
+package callbacks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_MemberLines(t *testing.T) {
+	sums := map[int64]int{1: 50000, 2: 120050, 0: 1000}
+	members := []types.Member{{ID: 1, Name: "Анна"}, {ID: 2, Name: "Борис"}}
+
+	result := memberLines(sums, members, types.UnitRate)
+
+	assert.Equal(t, "\nПо участникам:\nБорис: 1200.50\nАнна: 500.00\nНе указан: 10.00\n", result)
+	assert.Equal(t, "", memberLines(map[int64]int{}, members, types.UnitRate))
+}
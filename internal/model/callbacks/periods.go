@@ -0,0 +1,34 @@
+package callbacks
+
+import "time"
+
+func getDayBegin(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
+
+func getDayEnd(date time.Time) time.Time {
+	return getDayBegin(date).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// calendarMonth returns the whole calendar month containing the date.
+func calendarMonth(date time.Time) (time.Time, time.Time) {
+	begin := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	return begin, getDayEnd(begin.AddDate(0, 1, -1))
+}
+
+func previousMonth(date time.Time) (time.Time, time.Time) {
+	begin, _ := calendarMonth(date)
+	return calendarMonth(begin.AddDate(0, -1, 0))
+}
+
+// quarter returns the whole calendar quarter containing the date.
+func quarter(date time.Time) (time.Time, time.Time) {
+	firstMonth := time.Month((int(date.Month())-1)/3*3 + 1)
+	begin := time.Date(date.Year(), firstMonth, 1, 0, 0, 0, 0, date.Location())
+	return begin, getDayEnd(begin.AddDate(0, 3, -1))
+}
+
+func yearToDate(date time.Time) (time.Time, time.Time) {
+	begin := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+	return begin, getDayEnd(date)
+}
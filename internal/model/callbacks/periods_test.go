@@ -0,0 +1,34 @@
+package callbacks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReportPeriods(t *testing.T) {
+	now := time.Date(2026, 2, 17, 15, 30, 0, 0, time.UTC)
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		period func(time.Time) (time.Time, time.Time)
+		begin  time.Time
+		end    time.Time
+	}{
+		{"calendar month", calendarMonth, day(2026, 2, 1), day(2026, 2, 28)},
+		{"previous month", previousMonth, day(2026, 1, 1), day(2026, 1, 31)},
+		{"quarter", quarter, day(2026, 1, 1), day(2026, 3, 31)},
+		{"year to date", yearToDate, day(2026, 1, 1), day(2026, 2, 17)},
+	}
+
+	for _, test := range tests {
+		begin, end := test.period(now)
+
+		assert.Equal(t, test.begin, begin, test.name)
+		assert.Equal(t, getDayEnd(test.end), end, test.name)
+	}
+}
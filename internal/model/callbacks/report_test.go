@@ -0,0 +1,24 @@
+package callbacks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_ConvertRow_ShouldKeepAmountEnteredInReportCurrency(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	today := types.RateFromFloat(100)
+
+	// 10 USD entered at 95, today's rate does not change it.
+	entered := types.ReportRow{Date: date, Sum: 95000, OriginalSum: 1000, OriginalCurrency: "USD"}
+	assert.InDelta(t, 10.0, convertRow(entered, "USD", nil, today), 1e-9)
+
+	// 100 RUB at the rate of the date, or at today's rate without it.
+	roubles := types.ReportRow{Date: date, Sum: 10000, OriginalSum: 10000, OriginalCurrency: types.RUB}
+	rates := map[time.Time]types.Rate{date: types.RateFromFloat(50)}
+	assert.InDelta(t, 2.0, convertRow(roubles, "USD", rates, today), 1e-9)
+	assert.InDelta(t, 1.0, convertRow(roubles, "USD", nil, today), 1e-9)
+}
@@ -0,0 +1,320 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	accountHelpMsg = "Счета:\n" +
+		"/account - список\n" +
+		"/account add карта 15000 [RUB] - добавить счет с начальным остатком, название одним словом\n" +
+		"/account delete карта - удалить счет без переводов, его траты останутся без счета\n" +
+		"/transfer 5000 карта наличные [сумма в валюте второго счета] - перевод между счетами\n" +
+		"/balance - остатки на счетах\n" +
+		"Счет траты выбирается кнопкой на ее карточке"
+	accountsEmptyMsg   = "Счетов нет"
+	accountNotFoundMsg = "Счет не найден: "
+	accountInUseMsg    = "По счету есть переводы, его нельзя удалить: "
+)
+
+func (s *Model) accountCommand(ctx context.Context, msg *Message, args string) error {
+	action, args, _ := strings.Cut(args, " ")
+
+	switch action {
+	case "", "list":
+		return s.listAccounts(ctx, msg)
+	case "add":
+		return s.addAccount(ctx, msg, args)
+	case "delete":
+		name := strings.TrimSpace(args)
+		if name == "" {
+			return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+		}
+
+		ok, err := s.accountsDB.DeleteAccount(ctx, msg.UserID, name)
+
+		if errors.Is(err, types.ErrAccountInUse) {
+			return s.tgClient.SendMessage(accountInUseMsg+name, msg.UserID)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "cannot DeleteAccount")
+		}
+
+		if !ok {
+			return s.tgClient.SendMessage(accountNotFoundMsg+name, msg.UserID)
+		}
+
+		return s.tgClient.SendMessage("Счет удален", msg.UserID)
+	}
+
+	return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+}
+
+// addAccount parses "<name> [opening balance] [currency]", the balance may be negative for a credit card.
+func (s *Model) addAccount(ctx context.Context, msg *Message, args string) error {
+	words := strings.Fields(args)
+	if len(words) == 0 || len(words) > 3 {
+		return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+	}
+
+	account := types.Account{Name: words[0]}
+
+	if len(words) > 1 {
+		balance, err := strconv.ParseFloat(strings.ReplaceAll(words[1], ",", "."), 64)
+		if err != nil {
+			return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+		}
+
+		account.OpeningBalance = int(math.Round(balance * kopecksInRouble))
+	}
+
+	if len(words) > 2 {
+		currencies := s.getCurrencies(ctx)
+
+		var ok bool
+		if account.Currency, ok = currencies.Parse(words[2]); !ok {
+			return s.tgClient.SendMessage("Неизвестная валюта: "+words[2], msg.UserID)
+		}
+	} else {
+		currency, err := s.getUserCurrency(ctx, msg.UserID)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot getUserCurrency")
+		}
+
+		account.Currency = currency
+	}
+
+	ok, err := s.accountsDB.CreateAccount(ctx, msg.UserID, account)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateAccount")
+	}
+
+	if !ok {
+		return s.tgClient.SendMessage("Счет с таким названием уже есть", msg.UserID)
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Счет «%s» добавлен, остаток %.2f %s",
+		account.Name, float64(account.OpeningBalance)/100, account.Currency), msg.UserID)
+}
+
+func (s *Model) listAccounts(ctx context.Context, msg *Message) error {
+	accounts, err := s.accountsDB.GetAccounts(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetAccounts")
+	}
+
+	if len(accounts) == 0 {
+		return s.tgClient.SendMessage(accountsEmptyMsg+"\n\n"+accountHelpMsg, msg.UserID)
+	}
+
+	result := "Счета:\n\n"
+	for _, account := range accounts {
+		result += fmt.Sprintf("%s, %s, начальный остаток %.2f\n",
+			account.Name, account.Currency, float64(account.OpeningBalance)/100)
+	}
+
+	return s.tgClient.SendMessage(result, msg.UserID)
+}
+
+// transferCommand parses "<amount> <from> <to> [amount in the currency of the second account]".
+// Without the second amount it is converted at today's rates.
+func (s *Model) transferCommand(ctx context.Context, msg *Message, args string) error {
+	words := strings.Fields(args)
+	if len(words) != 3 && len(words) != 4 {
+		return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+	}
+
+	amount, err := parseAmount(words[0])
+	if err != nil {
+		return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+	}
+
+	accounts, err := s.accountsDB.GetAccounts(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetAccounts")
+	}
+
+	from, ok := findAccount(accounts, words[1])
+	if !ok {
+		return s.tgClient.SendMessage(accountNotFoundMsg+words[1], msg.UserID)
+	}
+
+	to, ok := findAccount(accounts, words[2])
+	if !ok {
+		return s.tgClient.SendMessage(accountNotFoundMsg+words[2], msg.UserID)
+	}
+
+	if from.ID == to.ID {
+		return s.tgClient.SendMessage("Счета перевода должны различаться", msg.UserID)
+	}
+
+	transfer := types.Transfer{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		FromSum:       int(math.Round(amount * kopecksInRouble)),
+		Date:          getDay(time.Now()),
+	}
+
+	switch {
+	case len(words) == 4:
+		toAmount, err := parseAmount(words[3])
+		if err != nil {
+			return s.tgClient.SendMessage(accountHelpMsg, msg.UserID)
+		}
+
+		transfer.ToSum = int(math.Round(toAmount * kopecksInRouble))
+	case from.Currency == to.Currency:
+		transfer.ToSum = transfer.FromSum
+	default:
+		fromRate, err := s.getCurrentCurrencyRate(ctx, from.Currency)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+		}
+
+		toRate, err := s.getCurrentCurrencyRate(ctx, to.Currency)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+		}
+
+		transfer.ToSum = int(math.Round(toRate.FromKopecks(fromRate.ToKopecks(transfer.FromSum)) * kopecksInRouble))
+	}
+
+	err = s.accountsDB.CreateTransfer(ctx, msg.UserID, transfer)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateTransfer")
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Перевод %.2f %s со счета «%s» на счет «%s»: %.2f %s",
+		float64(transfer.FromSum)/100, from.Currency, from.Name,
+		to.Name, float64(transfer.ToSum)/100, to.Currency), msg.UserID)
+}
+
+func (s *Model) balanceCommand(ctx context.Context, msg *Message) error {
+	accounts, err := s.accountsDB.GetAccounts(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetAccounts")
+	}
+
+	if len(accounts) == 0 {
+		return s.tgClient.SendMessage(accountsEmptyMsg+"\n\n"+accountHelpMsg, msg.UserID)
+	}
+
+	expenses, err := s.accountsDB.GetAccountExpenses(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetAccountExpenses")
+	}
+
+	transfers, err := s.accountsDB.GetTransferSums(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetTransferSums")
+	}
+
+	result := "Остатки на счетах:\n\n"
+	for _, account := range accounts {
+		rate, err := s.getCurrentCurrencyRate(ctx, account.Currency)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+		}
+
+		rates, err := s.accountRates(ctx, account, expenses)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot accountRates")
+		}
+
+		balance := accountBalance(account, expenses, transfers[account.ID], rates, rate)
+		result += fmt.Sprintf("%s: %.2f %s\n", account.Name, float64(balance)/100, account.Currency)
+	}
+
+	return s.tgClient.SendMessage(result, msg.UserID)
+}
+
+// accountRates returns the rates of the account currency on the days of its expenses entered in other currencies.
+func (s *Model) accountRates(ctx context.Context, account types.Account,
+	expenses []types.AccountExpenses) (map[time.Time]types.Rate, error) {
+	var dateBegin time.Time
+
+	for _, expense := range expenses {
+		if expense.AccountID != account.ID || expense.Currency == account.Currency {
+			continue
+		}
+
+		if dateBegin.IsZero() || expense.Date.Before(dateBegin) {
+			dateBegin = expense.Date
+		}
+	}
+
+	if dateBegin.IsZero() || account.Currency == types.RUB {
+		return nil, nil
+	}
+
+	rates, err := s.ratesDB.GetCurrencyRates(ctx, account.Currency, dateBegin, time.Now())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot GetCurrencyRates")
+	}
+
+	return rates, nil
+}
+
+// accountBalance returns the balance in hundredths of the account currency. Expenses and income entered in the
+// account currency are taken as is. The kopecks of the others were counted at the rate of their day, so they are
+// converted at the rate of the account currency of the same day, or at today's one if it is not known.
+func accountBalance(account types.Account, expenses []types.AccountExpenses, transfers int,
+	rates map[time.Time]types.Rate, rate types.Rate) int {
+	balance := account.OpeningBalance + transfers
+
+	for _, expense := range expenses {
+		if expense.AccountID != account.ID {
+			continue
+		}
+
+		sum := expense.OriginalSum
+		if expense.Currency != account.Currency {
+			dayRate, ok := types.RateOnDate(rates, expense.Date)
+			if !ok {
+				dayRate = rate
+			}
+
+			sum = int(math.Round(dayRate.FromKopecks(expense.Sum) * kopecksInRouble))
+		}
+
+		if expense.Kind == types.KindIncome {
+			balance += sum
+		} else {
+			balance -= sum
+		}
+	}
+
+	return balance
+}
+
+func findAccount(accounts []types.Account, name string) (types.Account, bool) {
+	for _, account := range accounts {
+		if strings.EqualFold(account.Name, name) {
+			return account, true
+		}
+	}
+
+	return types.Account{}, false
+}
@@ -0,0 +1,56 @@
+package messages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_AccountBalance(t *testing.T) {
+	card := types.Account{ID: 1, Name: "карта", Currency: types.USD, OpeningBalance: 100000}
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []types.AccountExpenses{
+		{AccountID: 1, Date: day, Currency: types.USD, OriginalSum: 2550, Sum: 229500},
+		// 900 RUB spent from the dollar card when a dollar was 90 RUB, it is 100 RUB today.
+		{AccountID: 1, Date: day, Currency: types.RUB, OriginalSum: 90000, Sum: 90000},
+		{AccountID: 2, Date: day, Currency: types.USD, OriginalSum: 500000, Sum: 45000000},
+		{AccountID: 1, Date: day, Kind: types.KindIncome, Currency: types.USD, OriginalSum: 20000, Sum: 1800000},
+		// The rate of this day is unknown, today's one is used.
+		{AccountID: 1, Date: day.AddDate(0, 0, 30), Currency: types.EUR, OriginalSum: 1000, Sum: 100000},
+	}
+	rates := map[time.Time]types.Rate{day: types.RateFromFloat(90)}
+
+	balance := accountBalance(card, expenses, -5000, rates, types.RateFromFloat(100))
+
+	// 1000 - 25.50 - 10 + 200 - 50 - 10.
+	assert.Equal(t, 110450, balance)
+}
+
+func Test_FindAccount(t *testing.T) {
+	accounts := []types.Account{{ID: 1, Name: "Наличные"}, {ID: 2, Name: "Карта"}}
+
+	account, ok := findAccount(accounts, "карта")
+	assert.True(t, ok)
+	assert.Equal(t, 2, account.ID)
+
+	_, ok = findAccount(accounts, "кредитка")
+	assert.False(t, ok)
+}
+
+func Test_OnAccountDeleteCommand_ShouldKeepAccountWithTransfers(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	deps.accountsDB.EXPECT().DeleteAccount(gomock.Any(), int64(123), "карта").Return(false, types.ErrAccountInUse)
+	deps.sender.EXPECT().SendMessage(accountInUseMsg+"карта", int64(123))
+
+	err := model.IncomingMessage(context.Background(), &Message{
+		Text:   "/account delete карта",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
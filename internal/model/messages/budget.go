@@ -0,0 +1,233 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	budgetHelpMsg  = "Введите бюджет категории на месяц: /budget кафе 15000 [YYYY-MM]\nБюджет действует и в следующих месяцах, 0 убирает его"
+	budgetEmptyMsg = "Бюджеты на этот месяц не заданы"
+)
+
+func (s *Model) budgetCommand(ctx context.Context, msg *Message, args string) error {
+	if args == "" {
+		return s.listBudgets(ctx, msg)
+	}
+
+	words := strings.Fields(args)
+	month := time.Now()
+
+	if len(words) > 0 {
+		if date, err := time.Parse("2006-01", words[len(words)-1]); err == nil {
+			month = date
+			words = words[:len(words)-1]
+		}
+	}
+
+	if len(words) < 2 {
+		return s.tgClient.SendMessage(budgetHelpMsg, msg.UserID)
+	}
+
+	amount, err := parseAmount(words[len(words)-1])
+	if err != nil && words[len(words)-1] != "0" {
+		return s.tgClient.SendMessage(budgetHelpMsg, msg.UserID)
+	}
+
+	category, err := s.resolveCategory(ctx, msg.UserID, strings.Join(words[:len(words)-1], " "))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot resolveCategory")
+	}
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	err = s.budgetsDB.SetBudget(ctx, msg.UserID, category, month, userModel.CurrencyRate.ToKopecks(int(math.Round(amount*kopecksInRouble))))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetBudget")
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Бюджет категории «%s» с %s: %.2f %s",
+		category, month.Format("2006-01"), amount, userModel.Currency), msg.UserID)
+}
+
+func (s *Model) listBudgets(ctx context.Context, msg *Message) error {
+	now := time.Now()
+
+	budgets, err := s.budgetsDB.GetBudgets(ctx, msg.UserID, now)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetBudgets")
+	}
+
+	if len(budgets) == 0 {
+		return s.tgClient.SendMessage(budgetEmptyMsg+"\n\n"+budgetHelpMsg, msg.UserID)
+	}
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	categories := make([]string, 0, len(budgets))
+	for category := range budgets {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	result := fmt.Sprintf("Бюджеты на %s:\n\n", now.Format("2006-01"))
+	for _, category := range categories {
+		spent, err := s.expensesDB.GetCategoryMonthReport(ctx, msg.UserID, category, now)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot GetCategoryMonthReport")
+		}
+
+		result += fmt.Sprintf("%s: %.2f из %.2f %s (%d%%)\n",
+			category,
+			userModel.CurrencyRate.FromKopecks(spent),
+			userModel.CurrencyRate.FromKopecks(budgets[category]),
+			userModel.Currency,
+			spent*100/budgets[category],
+		)
+	}
+
+	return s.tgClient.SendMessage(result, msg.UserID)
+}
+
+// checkCategorySum warns the user when the expense makes its category or the parent category
+// cross one of the budget thresholds. The previous state of an edited expense is what was counted
+// before the change, nil for a new one.
+func (s *Model) checkCategorySum(ctx context.Context, userID int64, expense, previous *types.Expense) error {
+	if expense.Kind == types.KindIncome {
+		return nil
+	}
+
+	categories, err := s.categoriesDB.GetCategories(ctx, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	parents := types.CategoryParents(categories)
+
+	err = s.checkBudget(ctx, userID, expense.Category, expense, previousSum(expense.Category, expense, previous, parents))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot checkBudget")
+	}
+
+	// The budget of the parent category covers its subcategories.
+	if parent, ok := parents[expense.Category]; ok {
+		return s.checkBudget(ctx, userID, parent, expense, previousSum(parent, expense, previous, parents))
+	}
+
+	return nil
+}
+
+// previousSum returns the part of the category month sum which was the expense before the change.
+func previousSum(category string, expense, previous *types.Expense, parents map[string]string) int {
+	if previous == nil || previous.Kind == types.KindIncome {
+		return 0
+	}
+
+	if previous.Date.Year() != expense.Date.Year() || previous.Date.Month() != expense.Date.Month() {
+		return 0
+	}
+
+	if previous.Category != category && parents[previous.Category] != category {
+		return 0
+	}
+
+	return previous.Sum
+}
+
+func (s *Model) checkBudget(ctx context.Context, userID int64, category string, expense *types.Expense, previous int) error {
+	budget, ok, err := s.budgetsDB.GetBudget(ctx, userID, category, expense.Date)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetBudget")
+	}
+
+	if !ok {
+		return nil
+	}
+
+	spent, err := s.expensesDB.GetCategoryMonthReport(ctx, userID, category, expense.Date)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategoryMonthReport")
+	}
+
+	threshold, crossed := crossedThreshold(s.config.GetBudgetThresholds(), budget, spent-expense.Sum+previous, spent)
+	if !crossed {
+		return nil
+	}
+
+	userModel, err := s.getUserModel(ctx, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	message := fmt.Sprintf("Внимание, по категории «%s» потрачено %d%% бюджета: %.2f из %.2f %s",
+		category,
+		threshold,
+		userModel.CurrencyRate.FromKopecks(spent),
+		userModel.CurrencyRate.FromKopecks(budget),
+		userModel.Currency,
+	)
+
+	// The budgets of a group chat are shared, so the members see whose expense crossed it.
+	if expense.PaidByName != "" {
+		message += ", трата: " + expense.PaidByName
+	}
+
+	return s.tgClient.SendMessage(message, userID)
+}
+
+// crossedThreshold returns the highest threshold (in percent of the budget) between the sums.
+func crossedThreshold(thresholds []int, budget, before, after int) (int, bool) {
+	result, crossed := 0, false
+
+	for _, threshold := range thresholds {
+		edge := budget * threshold
+		if before*100 < edge && after*100 >= edge && threshold > result {
+			result, crossed = threshold, true
+		}
+	}
+
+	return result, crossed
+}
+
+func (s *Model) getUserModel(ctx context.Context, userID int64) (*types.UserModel, error) {
+	currency, err := s.getUserCurrency(ctx, userID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getUserCurrency")
+	}
+
+	rate, err := s.getCurrentCurrencyRate(ctx, currency)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getCurrentCurrencyRate")
+	}
+
+	return &types.UserModel{
+		Currency:     string(currency),
+		CurrencyRate: rate,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package messages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_CrossedThreshold(t *testing.T) {
+	thresholds := []int{80, 100}
+
+	tests := []struct {
+		before, after int
+		threshold     int
+		crossed       bool
+	}{
+		{0, 500, 0, false},
+		{700, 800, 80, true},
+		{850, 900, 0, false},
+		{900, 1000, 100, true},
+		{500, 1200, 100, true},
+		{1100, 1200, 0, false},
+	}
+
+	for _, test := range tests {
+		threshold, crossed := crossedThreshold(thresholds, 1000, test.before, test.after)
+
+		assert.Equal(t, test.crossed, crossed, test)
+		assert.Equal(t, test.threshold, threshold, test)
+	}
+}
+
+func Test_OnSumEdited_ShouldCompareWithPreviousSum(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	date := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	expense := &types.Expense{ExpenseID: 456, Sum: 85000, Category: "Кафе", Date: date, Kind: types.KindExpense}
+
+	// 850 of 1000 were spent before the sum was corrected to 900, the 80% were crossed earlier.
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(123)).Return(&types.UserStateType{
+		CurrentState: types.CurrentState{ExpenseID: 456, State: types.EditingSum},
+	}, true)
+	deps.expensesDB.EXPECT().GetExpense(gomock.Any(), int64(123), 456).Return(expense, nil)
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil).AnyTimes()
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).AnyTimes()
+	deps.expensesDB.EXPECT().WriteSum(gomock.Any(), 90000, 90000, types.RUB, int64(123), 456).Return(nil)
+	deps.limitsDB.EXPECT().GetLimit(gomock.Any(), int64(123), date).Return(10000000, true, nil)
+	deps.expensesDB.EXPECT().GetMonthReport(gomock.Any(), int64(123), date).Return(90000, nil)
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(nil, nil)
+	deps.budgetsDB.EXPECT().GetBudget(gomock.Any(), int64(123), "Кафе", date).Return(100000, true, nil)
+	deps.expensesDB.EXPECT().GetCategoryMonthReport(gomock.Any(), int64(123), "Кафе", date).Return(90000, nil)
+	deps.config.EXPECT().GetBudgetThresholds().Return([]int{80, 100})
+	deps.sender.EXPECT().DeleteMessage(int64(123), 789).Return(nil)
+	deps.usersDB.EXPECT().ToWaitState(gomock.Any(), int64(123)).Return(nil)
+	deps.sender.EXPECT().EditExpenseMessage(gomock.Any(), int64(123), 456).Return(nil)
+
+	err := model.IncomingMessage(context.Background(), &Message{Text: "900", UserID: 123, MessageID: 789})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnDateEntered_ShouldCheckBudgetOfNewMonth(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	date := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	expense := &types.Expense{
+		ExpenseID: 456,
+		Sum:       85000,
+		Category:  "Кафе",
+		Date:      time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC),
+		Kind:      types.KindExpense,
+	}
+
+	// The expense moved to November makes it 850 of the 1000 there.
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(123)).Return(&types.UserStateType{
+		CurrentState: types.CurrentState{ExpenseID: 456, State: types.EditingDate},
+	}, true)
+	deps.expensesDB.EXPECT().GetExpense(gomock.Any(), int64(123), 456).Return(expense, nil)
+	deps.expensesDB.EXPECT().WriteDate(gomock.Any(), date, int64(123), 456).Return(nil)
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(nil, nil)
+	deps.budgetsDB.EXPECT().GetBudget(gomock.Any(), int64(123), "Кафе", date).Return(100000, true, nil)
+	deps.expensesDB.EXPECT().GetCategoryMonthReport(gomock.Any(), int64(123), "Кафе", date).Return(85000, nil)
+	deps.config.EXPECT().GetBudgetThresholds().Return([]int{80, 100})
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil).AnyTimes()
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).AnyTimes()
+	deps.sender.EXPECT().SendMessage("Внимание, по категории «Кафе» потрачено 80% бюджета: 850.00 из 1000.00 RUB", int64(123))
+	deps.sender.EXPECT().DeleteMessage(int64(123), 789).Return(nil)
+	deps.usersDB.EXPECT().ToWaitState(gomock.Any(), int64(123)).Return(nil)
+	deps.sender.EXPECT().EditExpenseMessage(gomock.Any(), int64(123), 456).Return(nil)
+
+	err := model.IncomingMessage(context.Background(), &Message{Text: "2026-11-02", UserID: 123, MessageID: 789})
+
+	assert.NoError(t, err)
+}
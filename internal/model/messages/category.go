@@ -0,0 +1,369 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	categoriesHelpMsg = "Категории:\n" +
+		"/categories - список\n" +
+		"/categories add Такси - добавить\n" +
+		"/categories add Транспорт > Такси - добавить подкатегорию, отчеты покажут ее внутри родительской\n" +
+		"/categories parent Такси = Транспорт - сделать подкатегорией, без родительской - вынести на верхний уровень\n" +
+		"/categories rename Кафе = Рестораны - переименовать, траты и бюджеты переносятся\n" +
+		"/categories merge Кафе = Рестораны - перенести все траты в другую категорию и удалить эту\n" +
+		"/categories archive Кафе - убрать из кнопок, траты остаются в отчетах\n" +
+		"/categories unarchive Кафе - вернуть в кнопки\n" +
+		"/categories emoji Кафе ☕ - значок на кнопке"
+	categoryNotFoundMsg = "Категория не найдена: "
+)
+
+var (
+	errCategorySelfParent = errors.New("category cannot be its own parent")
+	errCategoryDepth      = errors.New("subcategories cannot have subcategories")
+)
+
+// findCategory returns the name of the category from the catalogue, or the normalized name if it is not there.
+// The path "Транспорт > Такси" means the subcategory.
+func (s *Model) findCategory(ctx context.Context, userID int64, path string) (string, bool, error) {
+	categories, err := s.categoriesDB.GetCategories(ctx, userID)
+
+	if err != nil {
+		return "", false, errors.Wrap(err, "cannot GetCategories")
+	}
+
+	_, name := types.SplitCategoryPath(path)
+
+	if category, ok := types.FindCategory(categories, name); ok {
+		return category.Name, true, nil
+	}
+
+	return name, false, nil
+}
+
+// resolveCategory returns the name of the category from the catalogue, adding a new category to it.
+// The path "Транспорт > Такси" also puts the subcategory into the parent if the levels allow it.
+func (s *Model) resolveCategory(ctx context.Context, userID int64, path string) (string, error) {
+	categories, err := s.categoriesDB.GetCategories(ctx, userID)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot GetCategories")
+	}
+
+	parentName, name := types.SplitCategoryPath(path)
+
+	category, err := s.addCategory(ctx, userID, categories, name)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot addCategory")
+	}
+
+	if parentName == "" || category == "" {
+		return category, nil
+	}
+
+	parent, err := s.addCategory(ctx, userID, categories, parentName)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot addCategory")
+	}
+
+	if checkCategoryParent(categories, category, parent) == nil {
+		_, err = s.categoriesDB.SetCategoryParent(ctx, userID, category, parent)
+
+		if err != nil {
+			return "", errors.Wrap(err, "cannot SetCategoryParent")
+		}
+	}
+
+	return category, nil
+}
+
+// addCategory returns the name from the catalogue, adding the category if it is not there.
+func (s *Model) addCategory(ctx context.Context, userID int64, categories []types.Category, name string) (string, error) {
+	if category, ok := types.FindCategory(categories, name); ok {
+		return category.Name, nil
+	}
+
+	name = types.NormalizeCategory(name)
+	if name == "" {
+		return "", nil
+	}
+
+	err := s.categoriesDB.AddCategory(ctx, userID, name)
+
+	if err != nil {
+		return "", errors.Wrap(err, "cannot AddCategory")
+	}
+
+	return name, nil
+}
+
+// checkCategoryParent keeps the catalogue two levels deep.
+func checkCategoryParent(categories []types.Category, name, parentName string) error {
+	if strings.EqualFold(name, parentName) {
+		return errCategorySelfParent
+	}
+
+	if parent, ok := types.FindCategory(categories, parentName); ok && parent.ParentID != 0 {
+		return errCategoryDepth
+	}
+
+	if category, ok := types.FindCategory(categories, name); ok {
+		for _, child := range categories {
+			if child.ParentID == category.ID {
+				return errCategoryDepth
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Model) categoriesCommand(ctx context.Context, msg *Message, args string) error {
+	action, args, _ := strings.Cut(strings.TrimSpace(args), " ")
+	args = strings.TrimSpace(args)
+
+	switch action {
+	case "", "list":
+		return s.listCategories(ctx, msg)
+	case "add":
+		parent, name := types.SplitCategoryPath(args)
+		if name == "" {
+			return s.tgClient.SendMessage(categoriesHelpMsg, msg.UserID)
+		}
+
+		category, err := s.resolveCategory(ctx, msg.UserID, name)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot resolveCategory")
+		}
+
+		if parent != "" {
+			parent, err = s.resolveCategory(ctx, msg.UserID, parent)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot resolveCategory")
+			}
+
+			return s.setCategoryParent(ctx, msg, category, parent)
+		}
+
+		return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» добавлена", category), msg.UserID)
+	case "parent":
+		name, parent, ok := strings.Cut(args, "=")
+		name, parent = types.NormalizeCategory(name), types.NormalizeCategory(parent)
+
+		if !ok || name == "" {
+			return s.tgClient.SendMessage(categoriesHelpMsg, msg.UserID)
+		}
+
+		return s.setCategoryParent(ctx, msg, name, parent)
+	case "rename", "merge":
+		name, newName, ok := strings.Cut(args, "=")
+		name, newName = types.NormalizeCategory(name), types.NormalizeCategory(newName)
+
+		if !ok || name == "" || newName == "" {
+			return s.tgClient.SendMessage(categoriesHelpMsg, msg.UserID)
+		}
+
+		return s.moveCategory(ctx, msg, name, newName, action == "merge")
+	case "archive", "unarchive":
+		ok, err := s.categoriesDB.SetCategoryArchived(ctx, msg.UserID, types.NormalizeCategory(args), action == "archive")
+
+		if err != nil {
+			return errors.Wrap(err, "cannot SetCategoryArchived")
+		}
+
+		if !ok {
+			return s.tgClient.SendMessage(categoryNotFoundMsg+args, msg.UserID)
+		}
+
+		if action == "archive" {
+			return s.tgClient.SendMessage("Категория убрана из кнопок", msg.UserID)
+		}
+
+		return s.tgClient.SendMessage("Категория возвращена в кнопки", msg.UserID)
+	case "emoji":
+		// The emoji is the last word, the name may have spaces.
+		words := strings.Fields(args)
+		if len(words) < 2 {
+			return s.tgClient.SendMessage(categoriesHelpMsg, msg.UserID)
+		}
+
+		name := strings.Join(words[:len(words)-1], " ")
+
+		ok, err := s.categoriesDB.SetCategoryEmoji(ctx, msg.UserID, types.NormalizeCategory(name), words[len(words)-1])
+
+		if err != nil {
+			return errors.Wrap(err, "cannot SetCategoryEmoji")
+		}
+
+		if !ok {
+			return s.tgClient.SendMessage(categoryNotFoundMsg+name, msg.UserID)
+		}
+
+		return s.tgClient.SendMessage("Значок категории изменен", msg.UserID)
+	}
+
+	return s.tgClient.SendMessage(categoriesHelpMsg, msg.UserID)
+}
+
+func (s *Model) listCategories(ctx context.Context, msg *Message) error {
+	categories, err := s.categoriesDB.GetCategories(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	return s.tgClient.SendMessage(categoriesMessage(categories)+"\n"+categoriesHelpMsg, msg.UserID)
+}
+
+// categoriesMessage lists the categories with their subcategories indented.
+func categoriesMessage(categories []types.Category) string {
+	result := "Категории:\n"
+	archived := ""
+
+	for _, parent := range categories {
+		if parent.ParentID != 0 {
+			continue
+		}
+
+		lines := parent.Title() + "\n"
+		for _, child := range categories {
+			if child.ParentID == parent.ID {
+				lines += "  • " + child.Title() + "\n"
+			}
+		}
+
+		if parent.Archived {
+			archived += lines
+		} else {
+			result += lines
+		}
+	}
+
+	if archived != "" {
+		result += "\nВ архиве:\n" + archived
+	}
+
+	return result
+}
+
+// setCategoryParent makes the category a subcategory of the parent, an empty parent makes it a top level one.
+func (s *Model) setCategoryParent(ctx context.Context, msg *Message, name, parent string) error {
+	categories, err := s.categoriesDB.GetCategories(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	if parent != "" {
+		parentCategory, ok := types.FindCategory(categories, parent)
+		if !ok {
+			return s.tgClient.SendMessage(categoryNotFoundMsg+parent, msg.UserID)
+		}
+		parent = parentCategory.Name
+
+		switch checkCategoryParent(categories, name, parent) {
+		case errCategorySelfParent:
+			return s.tgClient.SendMessage("Категория не может быть подкатегорией самой себя", msg.UserID)
+		case errCategoryDepth:
+			return s.tgClient.SendMessage("Подкатегории не могут содержать подкатегории", msg.UserID)
+		}
+	}
+
+	ok, err := s.categoriesDB.SetCategoryParent(ctx, msg.UserID, name, parent)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetCategoryParent")
+	}
+
+	if !ok {
+		return s.tgClient.SendMessage(categoryNotFoundMsg+name, msg.UserID)
+	}
+
+	// The reports roll the expenses up into the parents now.
+	err = s.expensesDB.InvalidateReports(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot InvalidateReports")
+	}
+
+	if parent == "" {
+		return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» теперь верхнего уровня", name), msg.UserID)
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» теперь внутри «%s»", name, parent), msg.UserID)
+}
+
+// moveCategory renames the category or merges it into another one, rewriting the expenses,
+// budgets, recurring expenses and import rules of the category in one transaction.
+func (s *Model) moveCategory(ctx context.Context, msg *Message, name, newName string, merge bool) error {
+	categories, err := s.categoriesDB.GetCategories(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategories")
+	}
+
+	target, targetExists := types.FindCategory(categories, newName)
+
+	switch {
+	case merge && !targetExists:
+		return s.tgClient.SendMessage(categoryNotFoundMsg+newName, msg.UserID)
+	case merge && strings.EqualFold(name, newName):
+		return s.tgClient.SendMessage("Нельзя объединить категорию саму с собой", msg.UserID)
+	case !merge && targetExists && !strings.EqualFold(name, newName):
+		return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» уже есть, объедините категории: "+
+			"/categories merge %s = %s", target.Name, name, target.Name), msg.UserID)
+	}
+
+	if merge {
+		newName = target.Name
+	}
+
+	var ok bool
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if merge {
+			ok, err = s.categoriesDB.MergeCategory(ctx, msg.UserID, name, newName)
+			return errors.Wrap(err, "cannot MergeCategory")
+		}
+
+		ok, err = s.categoriesDB.RenameCategory(ctx, msg.UserID, name, newName)
+		return errors.Wrap(err, "cannot RenameCategory")
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "cannot RunInTx")
+	}
+
+	if !ok {
+		return s.tgClient.SendMessage(categoryNotFoundMsg+name, msg.UserID)
+	}
+
+	err = s.expensesDB.InvalidateReports(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot InvalidateReports")
+	}
+
+	if merge {
+		return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» объединена с «%s»", name, newName), msg.UserID)
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Категория «%s» переименована в «%s»", name, newName), msg.UserID)
+}
+
+// CheckCategoryBudget warns about the budget of the category chosen with the buttons of the expense card,
+// previous is the expense before the category was changed or nil if it was not written.
+func (s *Model) CheckCategoryBudget(ctx context.Context, userID int64, expense, previous *types.Expense) error {
+	return s.checkCategorySum(ctx, userID, expense, previous)
+}
@@ -0,0 +1,28 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_CategoriesMessage(t *testing.T) {
+	categories := []types.Category{
+		{ID: 1, Name: "Кафе", Emoji: "☕"},
+		{ID: 2, Name: "Такси", Archived: true},
+		{ID: 3, Name: "Дом"},
+	}
+
+	assert.Equal(t, "Категории:\n☕ Кафе\nДом\n\nВ архиве:\nТакси\n", categoriesMessage(categories))
+}
+
+func Test_CategoriesMessage_ShouldIndentSubcategories(t *testing.T) {
+	categories := []types.Category{
+		{ID: 1, Name: "Транспорт"},
+		{ID: 2, Name: "Кафе"},
+		{ID: 3, Name: "Такси", ParentID: 1},
+	}
+
+	assert.Equal(t, "Категории:\nТранспорт\n  • Такси\nКафе\n", categoriesMessage(categories))
+}
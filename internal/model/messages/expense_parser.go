@@ -0,0 +1,144 @@
+package messages
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// parsedExpense is an expense entered in one line, e.g. "350.50 кафе вчера USD".
+type parsedExpense struct {
+	Amount   float64
+	Category string
+	Date     time.Time
+	Currency types.Currency // Empty if the user did not specify it.
+}
+
+var (
+	errNoAmount   = errors.New("amount is not specified")
+	errNoCategory = errors.New("category is not specified")
+)
+
+var weekdays = map[string]time.Weekday{
+	"понедельник": time.Monday,
+	"пн":          time.Monday,
+	"вторник":     time.Tuesday,
+	"вт":          time.Tuesday,
+	"среда":       time.Wednesday,
+	"среду":       time.Wednesday,
+	"ср":          time.Wednesday,
+	"четверг":     time.Thursday,
+	"чт":          time.Thursday,
+	"пятница":     time.Friday,
+	"пятницу":     time.Friday,
+	"пт":          time.Friday,
+	"суббота":     time.Saturday,
+	"субботу":     time.Saturday,
+	"сб":          time.Saturday,
+	"воскресенье": time.Sunday,
+	"вс":          time.Sunday,
+}
+
+// parseExpense parses "<amount> [currency] <category...> [date] [currency]".
+// The date may be absolute (YYYY-MM-DD, DD.MM.YYYY, DD.MM), relative
+// (сегодня, вчера, позавчера) or a weekday name meaning the last such day.
+func parseExpense(text string, now time.Time, currencies types.Currencies) (*parsedExpense, error) {
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 {
+		return nil, errNoAmount
+	}
+
+	amount, err := parseAmount(tokens[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parseAmount")
+	}
+
+	expense := &parsedExpense{
+		Amount: amount,
+		Date:   getDay(now),
+	}
+	tokens = tokens[1:]
+
+	// The currency can go right after the amount: "350 USD такси".
+	if len(tokens) > 0 {
+		if currency, ok := currencies.Parse(tokens[0]); ok {
+			expense.Currency = currency
+			tokens = tokens[1:]
+		}
+	}
+
+	// Date and currency can close the line in any order.
+	dateFound := false
+	for len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+
+		if date, ok := parseDate(last, now); ok && !dateFound {
+			expense.Date = date
+			dateFound = true
+		} else if currency, ok := currencies.Parse(last); ok && expense.Currency == "" {
+			expense.Currency = currency
+		} else {
+			break
+		}
+
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	expense.Category = strings.Join(tokens, " ")
+	if expense.Category == "" {
+		return nil, errNoCategory
+	}
+
+	return expense, nil
+}
+
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot ParseFloat")
+	}
+
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+
+	return amount, nil
+}
+
+func parseDate(value string, now time.Time) (time.Time, bool) {
+	today := getDay(now)
+	value = strings.ToLower(value)
+
+	switch value {
+	case "сегодня":
+		return today, true
+	case "вчера":
+		return today.AddDate(0, 0, -1), true
+	case "позавчера":
+		return today.AddDate(0, 0, -2), true
+	}
+
+	if weekday, ok := weekdays[value]; ok {
+		daysAgo := (int(today.Weekday()) - int(weekday) + 7) % 7
+		return today.AddDate(0, 0, -daysAgo), true
+	}
+
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if date, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return date, true
+		}
+	}
+
+	if date, err := time.ParseInLocation("02.01", value, now.Location()); err == nil {
+		return date.AddDate(now.Year(), 0, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+func getDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
@@ -0,0 +1,48 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_ParseExpense(t *testing.T) {
+	// Saturday.
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	currencies := types.NewCurrencies([]types.CurrencyInfo{{Code: types.USD}, {Code: types.EUR}, {Code: "KZT"}})
+
+	tests := []struct {
+		text     string
+		expected *parsedExpense
+	}{
+		{"350.50 кафе вчера", &parsedExpense{350.5, "кафе", today.AddDate(0, 0, -1), ""}},
+		{"350 такси 2026-10-01", &parsedExpense{350, "такси", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), ""}},
+		{"12,5 USD кофе с собой", &parsedExpense{12.5, "кофе с собой", today, types.USD}},
+		{"100 продукты пятницу eur", &parsedExpense{100, "продукты", today.AddDate(0, 0, -1), types.EUR}},
+		{"100 продукты сб", &parsedExpense{100, "продукты", today, ""}},
+		{"100 продукты понедельник", &parsedExpense{100, "продукты", today.AddDate(0, 0, -5), ""}},
+		{"100 продукты 01.09", &parsedExpense{100, "продукты", time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), ""}},
+		{"5000 kzt шашлык", &parsedExpense{5000, "шашлык", today, "KZT"}},
+		{"5 gbp сувенир", &parsedExpense{5, "gbp сувенир", today, ""}},
+	}
+
+	for _, test := range tests {
+		expense, err := parseExpense(test.text, now, currencies)
+
+		assert.NoError(t, err, test.text)
+		assert.Equal(t, test.expected, expense, test.text)
+	}
+}
+
+func Test_ParseExpense_IncorrectInput(t *testing.T) {
+	now := time.Now()
+
+	for _, text := range []string{"", "кафе 350", "350", "350 вчера", "-5 кафе"} {
+		_, err := parseExpense(text, now, types.NewCurrencies(nil))
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,89 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/export"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const exportHelpMsg = "Введите период и формат: /export [YYYY-MM-DD] [YYYY-MM-DD] [csv|xlsx]"
+
+// exportCommand sends the expenses of the period as a CSV or XLSX document.
+func (s *Model) exportCommand(ctx context.Context, msg *Message, args string) error {
+	format := "csv"
+	var dates []time.Time
+
+	for _, word := range strings.Fields(args) {
+		switch strings.ToLower(word) {
+		case "csv", "xlsx":
+			format = strings.ToLower(word)
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", word)
+		if err != nil || len(dates) == 2 {
+			return s.tgClient.SendMessage(exportHelpMsg, msg.UserID)
+		}
+		dates = append(dates, date)
+	}
+
+	var dateBegin, dateEnd time.Time
+	if len(dates) > 0 {
+		dateBegin = dates[0]
+	}
+	if len(dates) > 1 {
+		dateEnd = dates[1]
+	}
+
+	reader, writer := io.Pipe()
+	// Unblocks the export if the upload stopped reading.
+	defer reader.Close()
+
+	go func() {
+		writer.CloseWithError(s.writeExport(ctx, writer, format, msg.UserID, dateBegin, dateEnd))
+	}()
+
+	name := fmt.Sprintf("expenses_%s.%s", time.Now().Format("2006-01-02"), format)
+	return s.tgClient.SendDocument(name, reader, msg.UserID)
+}
+
+func (s *Model) writeExport(ctx context.Context, w io.Writer, format string, userID int64, dateBegin, dateEnd time.Time) error {
+	var (
+		writer export.Writer
+		err    error
+	)
+
+	if format == "xlsx" {
+		writer, err = export.NewXLSX(w)
+	} else {
+		writer, err = export.NewCSV(w)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "cannot create export writer")
+	}
+
+	err = s.expensesDB.ExportExpenses(ctx, userID, dateBegin, dateEnd, func(expense *types.Expense) error {
+		return writer.WriteRow(export.Row{
+			Date:        expense.Date,
+			Income:      expense.Kind == types.KindIncome,
+			Category:    expense.Category,
+			Sum:         expense.Sum,
+			OriginalSum: float64(expense.OriginalSum) / kopecksInRouble,
+			Currency:    string(expense.OriginalCurrency),
+			Rate:        expense.OriginalRate.Float(),
+		})
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "cannot ExportExpenses")
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,295 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/statement"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	importPreviewSize = 5
+	importCategory    = "Прочее"
+	importProfileHelp = "Настройте формат выписки: /import_profile date=1 amount=2 description=3 currency=0 " +
+		"delimiter=; date_format=DD.MM.YYYY skip_header=yes encoding=windows-1251 sign=negative\n" +
+		"Колонки нумеруются с 1, 0 - колонки нет. sign: negative - траты со знаком минус, positive - без, any - все строки"
+	importRuleHelp = "Правила категорий: /import_rule пятерочка = продукты - строки с «пятерочка» в описании " +
+		"попадут в категорию «продукты»\n/import_rule delete пятерочка - удалить правило"
+	importEmptyMsg = "В выписке не найдено трат. Проверьте формат: /import_profile"
+)
+
+// Document is a file sent to the bot.
+type Document struct {
+	FileID   string
+	FileName string
+}
+
+// importStatement parses the bank statement and asks the user to confirm the import.
+func (s *Model) importStatement(ctx context.Context, msg *Message) error {
+	data, err := s.tgClient.DownloadFile(msg.Document.FileID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot DownloadFile")
+	}
+
+	profile, err := s.importsDB.GetImportProfile(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetImportProfile")
+	}
+
+	rows, err := statement.Parse(data, profile)
+
+	if err != nil {
+		return s.tgClient.SendMessage(fmt.Sprintf("Не удалось прочитать выписку: %s\n\n%s", err, importProfileHelp), msg.UserID)
+	}
+
+	if len(rows) == 0 {
+		return s.tgClient.SendMessage(importEmptyMsg, msg.UserID)
+	}
+
+	rules, err := s.importsDB.GetCategoryRules(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategoryRules")
+	}
+
+	userCurrency, err := s.getUserCurrency(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserCurrency")
+	}
+
+	currencies := s.getCurrencies(ctx)
+
+	type rateKey struct {
+		currency types.Currency
+		date     time.Time
+	}
+	rates := make(map[rateKey]types.Rate)
+	expenses := make([]types.Expense, 0, len(rows))
+
+	for _, row := range rows {
+		currency := userCurrency
+		if row.Currency != "" {
+			var ok bool
+			if currency, ok = currencies.Parse(row.Currency); !ok {
+				return s.tgClient.SendMessage("Неизвестная валюта в выписке: "+row.Currency, msg.UserID)
+			}
+		}
+
+		key := rateKey{currency: currency, date: row.Date}
+		rate, ok := rates[key]
+		if !ok {
+			rate, err = s.getCurrencyRate(ctx, currency, row.Date)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot getCurrencyRate")
+			}
+
+			rates[key] = rate
+		}
+
+		originalSum := int(math.Round(row.Amount * kopecksInRouble))
+		expenses = append(expenses, types.Expense{
+			Sum:              rate.ToKopecks(originalSum),
+			Category:         statement.Categorize(row.Description, rules, importCategory),
+			Date:             row.Date,
+			OriginalSum:      originalSum,
+			OriginalCurrency: currency,
+			PaidBy:           msg.MemberID,
+		})
+	}
+
+	importID, err := s.importsDB.CreatePendingImport(ctx, msg.UserID, expenses)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreatePendingImport")
+	}
+
+	return s.tgClient.SendImportPreview(importPreview(rows, expenses), msg.UserID, importID)
+}
+
+func importPreview(rows []statement.Row, expenses []types.Expense) string {
+	result := fmt.Sprintf("Найдено трат: %d. Первые из них:\n\n", len(rows))
+
+	for i := 0; i < len(rows) && i < importPreviewSize; i++ {
+		result += fmt.Sprintf("%s %s: %.2f %s (%s)\n",
+			rows[i].Date.Format("2006-01-02"),
+			expenses[i].Category,
+			rows[i].Amount,
+			rows[i].Currency,
+			rows[i].Description,
+		)
+	}
+
+	return result + "\nУже записанные траты с той же датой и суммой будут пропущены."
+}
+
+func (s *Model) importProfileCommand(ctx context.Context, msg *Message, args string) error {
+	profile, err := s.importsDB.GetImportProfile(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetImportProfile")
+	}
+
+	if args == "" {
+		return s.tgClient.SendMessage(profileMessage(profile)+"\n\n"+importProfileHelp, msg.UserID)
+	}
+
+	for _, field := range strings.Fields(args) {
+		key, value, _ := strings.Cut(field, "=")
+
+		if err := setProfileField(&profile, strings.ToLower(key), value); err != nil {
+			return s.tgClient.SendMessage(fmt.Sprintf("Неверный параметр %s: %s\n\n%s", key, err, importProfileHelp), msg.UserID)
+		}
+	}
+
+	err = s.importsDB.SetImportProfile(ctx, msg.UserID, profile)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetImportProfile")
+	}
+
+	return s.tgClient.SendMessage(profileMessage(profile), msg.UserID)
+}
+
+func setProfileField(profile *statement.Profile, key, value string) error {
+	column := func() (int, error) {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return 0, errors.New("ожидается номер колонки")
+		}
+		return number - 1, nil
+	}
+
+	var err error
+
+	switch key {
+	case "date":
+		profile.DateColumn, err = column()
+	case "amount":
+		profile.AmountColumn, err = column()
+	case "description":
+		profile.DescriptionColumn, err = column()
+	case "currency":
+		profile.CurrencyColumn, err = column()
+	case "delimiter":
+		if value == "tab" {
+			value = "\t"
+		}
+		if len([]rune(value)) != 1 {
+			return errors.New("ожидается один символ или tab")
+		}
+		profile.Delimiter = value
+	case "date_format":
+		profile.DateFormat = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(value)
+	case "skip_header":
+		profile.SkipHeader = value == "yes" || value == "да" || value == "true"
+	case "encoding":
+		profile.Encoding = value
+	case "sign":
+		if value != statement.NegativeSign && value != statement.PositiveSign && value != statement.AnySign {
+			return errors.New("ожидается negative, positive или any")
+		}
+		profile.ExpenseSign = value
+	default:
+		return errors.New("неизвестный параметр")
+	}
+
+	return err
+}
+
+func profileMessage(profile statement.Profile) string {
+	delimiter := profile.Delimiter
+	if delimiter == "\t" {
+		delimiter = "tab"
+	}
+
+	dateFormat := strings.NewReplacer("2006", "YYYY", "06", "YY", "01", "MM", "02", "DD").Replace(profile.DateFormat)
+
+	return fmt.Sprintf("Формат выписки:\nдата: %d, сумма: %d, описание: %d, валюта: %d\n"+
+		"разделитель: %s, формат даты: %s, заголовок: %t, кодировка: %s, траты: %s",
+		profile.DateColumn+1,
+		profile.AmountColumn+1,
+		profile.DescriptionColumn+1,
+		profile.CurrencyColumn+1,
+		delimiter,
+		dateFormat,
+		profile.SkipHeader,
+		profile.Encoding,
+		profile.ExpenseSign,
+	)
+}
+
+func (s *Model) importRuleCommand(ctx context.Context, msg *Message, args string) error {
+	if strings.HasPrefix(args, "delete ") {
+		pattern := strings.TrimSpace(strings.TrimPrefix(args, "delete "))
+		deleted, err := s.importsDB.DeleteCategoryRule(ctx, msg.UserID, pattern)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot DeleteCategoryRule")
+		}
+
+		if !deleted {
+			return s.tgClient.SendMessage("Правило не найдено", msg.UserID)
+		}
+
+		return s.tgClient.SendMessage("Правило удалено", msg.UserID)
+	}
+
+	if args == "" {
+		return s.listCategoryRules(ctx, msg)
+	}
+
+	pattern, category, _ := strings.Cut(args, "=")
+	rule := statement.Rule{
+		Pattern:  strings.TrimSpace(pattern),
+		Category: strings.TrimSpace(category),
+	}
+
+	if rule.Pattern == "" || rule.Category == "" {
+		return s.tgClient.SendMessage(importRuleHelp, msg.UserID)
+	}
+
+	var err error
+	rule.Category, err = s.resolveCategory(ctx, msg.UserID, rule.Category)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot resolveCategory")
+	}
+
+	err = s.importsDB.SetCategoryRule(ctx, msg.UserID, rule)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetCategoryRule")
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Траты с «%s» в описании попадут в категорию «%s»",
+		rule.Pattern, rule.Category), msg.UserID)
+}
+
+func (s *Model) listCategoryRules(ctx context.Context, msg *Message) error {
+	rules, err := s.importsDB.GetCategoryRules(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetCategoryRules")
+	}
+
+	if len(rules) == 0 {
+		return s.tgClient.SendMessage("Правил нет\n\n"+importRuleHelp, msg.UserID)
+	}
+
+	result := "Правила категорий:\n\n"
+	for _, rule := range rules {
+		result += fmt.Sprintf("%s → %s\n", rule.Pattern, rule.Category)
+	}
+
+	return s.tgClient.SendMessage(result, msg.UserID)
+}
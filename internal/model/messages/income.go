@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+// newIncome sends the card of a new income. Like an expense, the income is written when the card
+// is first edited, the card is marked to be written as an income then.
+func (s *Model) newIncome(ctx context.Context, msg *Message) error {
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	income := types.NewIncome()
+	income.ExpenseID, err = s.tgClient.CreateExpense(income.ToString(userModel), msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateExpense")
+	}
+
+	err = s.expensesDB.MarkIncomeCard(ctx, msg.UserID, income.ExpenseID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot MarkIncomeCard")
+	}
+
+	return nil
+}
@@ -2,30 +2,41 @@ package messages
 
 import (
 	"context"
+	"io"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/statement"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
 )
 
 type messageSender interface {
 	SendMessage(text string, userID int64) error
-	CreateExpense(text string, userID int64) error
+	CreateExpense(text string, userID int64) (int, error)
 	EditExpenseMessage(text string, userID int64, messageID int) error
 	DeleteMessage(userID int64, messageID int) error
 	GetReport(text string, userID int64) error
-	ChangeCurrency(text string, userID int64) error
+	SendDocument(name string, data io.Reader, userID int64) error
+	DownloadFile(fileID string) ([]byte, error)
+	SendImportPreview(text string, userID int64, importID int) error
 }
 
 type expensesDB interface {
 	WriteExpense(ctx context.Context, fromID int64, expense *types.Expense) error
 	GetExpense(ctx context.Context, userID int64, expenseID int) (*types.Expense, error)
-	WriteSum(ctx context.Context, sum int, userID int64, expenseID int) error
+	WriteSum(ctx context.Context, sum, originalSum int, originalCurrency types.Currency, userID int64, expenseID int) error
 	WriteCategory(ctx context.Context, category string, userID int64, expenseID int) error
 	WriteDate(ctx context.Context, date time.Time, userID int64, expenseID int) error
 	GetMonthReport(ctx context.Context, userID int64, date time.Time) (int, error)
+	GetCategoryMonthReport(ctx context.Context, userID int64, category string, date time.Time) (int, error)
+	ExportExpenses(ctx context.Context, userID int64, dateBegin, dateEnd time.Time, fn func(expense *types.Expense) error) error
+	InvalidateReports(ctx context.Context, userID int64) error
+	MarkIncomeCard(ctx context.Context, userID int64, cardID int) error
+	IsIncomeCard(ctx context.Context, userID int64, cardID int) (bool, error)
 }
 
 type usersDB interface {
@@ -34,19 +45,92 @@ type usersDB interface {
 	GetUserCurrency(ctx context.Context, userID int64) (types.Currency, error)
 	SetCurrentState(ctx context.Context, userID int64, state types.CurrentState) error
 	GetCurrentState(ctx context.Context, userID int64) (*types.UserStateType, bool)
+	SetHistoryFilter(ctx context.Context, userID int64, filter types.ExpenseFilter) error
 }
 
 type ratesDB interface {
-	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (int, error)
+	GetCurrencyRate(ctx context.Context, currency types.Currency, date time.Time) (types.Rate, error)
+	GetCurrencyRates(ctx context.Context, currency types.Currency, dateBegin, dateEnd time.Time) (map[time.Time]types.Rate, error)
+	GetCurrencies(ctx context.Context) ([]types.CurrencyInfo, error)
 }
 
 type limitsDB interface {
-	GetLimit(ctx context.Context, userID int64, monthNo int) (int, bool, error)
-	SetLimit(ctx context.Context, userID int64, monthNo, limit int) error
+	GetLimit(ctx context.Context, userID int64, date time.Time) (int, bool, error)
+	SetLimit(ctx context.Context, userID int64, year, month, limit int) error
+	SetDefaultLimit(ctx context.Context, userID int64, limit int) error
+}
+
+type budgetsDB interface {
+	SetBudget(ctx context.Context, userID int64, category string, date time.Time, limit int) error
+	GetBudget(ctx context.Context, userID int64, category string, date time.Time) (int, bool, error)
+	GetBudgets(ctx context.Context, userID int64, date time.Time) (map[string]int, error)
+}
+
+type recurringDB interface {
+	CreateRecurringExpense(ctx context.Context, userID int64, recurring *types.RecurringExpense) (int, error)
+	GetRecurringExpenses(ctx context.Context, userID int64) ([]types.RecurringExpense, error)
+	SetRecurringExpensePaused(ctx context.Context, userID int64, id int, paused bool) (bool, error)
+	ResumeRecurringExpense(ctx context.Context, userID int64, id int, nextDate time.Time) (bool, error)
+	DeleteRecurringExpense(ctx context.Context, userID int64, id int) (bool, error)
+}
+
+type importsDB interface {
+	GetImportProfile(ctx context.Context, userID int64) (statement.Profile, error)
+	SetImportProfile(ctx context.Context, userID int64, profile statement.Profile) error
+	GetCategoryRules(ctx context.Context, userID int64) ([]statement.Rule, error)
+	SetCategoryRule(ctx context.Context, userID int64, rule statement.Rule) error
+	DeleteCategoryRule(ctx context.Context, userID int64, pattern string) (bool, error)
+	CreatePendingImport(ctx context.Context, userID int64, expenses []types.Expense) (int, error)
+}
+
+type accountsDB interface {
+	CreateAccount(ctx context.Context, userID int64, account types.Account) (bool, error)
+	GetAccounts(ctx context.Context, userID int64) ([]types.Account, error)
+	DeleteAccount(ctx context.Context, userID int64, name string) (bool, error)
+	CreateTransfer(ctx context.Context, userID int64, transfer types.Transfer) error
+	GetAccountExpenses(ctx context.Context, userID int64) ([]types.AccountExpenses, error)
+	GetTransferSums(ctx context.Context, userID int64) (map[int]int, error)
+}
+
+type groupsDB interface {
+	AddMember(ctx context.Context, chatID int64, member types.Member) error
+	GetMembers(ctx context.Context, chatID int64) ([]types.Member, error)
+}
+
+type splitsDB interface {
+	WriteSplits(ctx context.Context, chatID int64, expenseID int, shares []types.SplitShare) error
+	CreateSettlement(ctx context.Context, chatID int64, debt types.Debt, date time.Time) error
+	GetBalances(ctx context.Context, chatID int64) (map[int64]int, error)
+}
+
+type categoriesDB interface {
+	GetCategories(ctx context.Context, userID int64) ([]types.Category, error)
+	AddCategory(ctx context.Context, userID int64, name string) error
+	SetCategoryEmoji(ctx context.Context, userID int64, name, emoji string) (bool, error)
+	SetCategoryArchived(ctx context.Context, userID int64, name string, archived bool) (bool, error)
+	SetCategoryParent(ctx context.Context, userID int64, name, parent string) (bool, error)
+	RenameCategory(ctx context.Context, userID int64, name, newName string) (bool, error)
+	MergeCategory(ctx context.Context, userID int64, name, into string) (bool, error)
+}
+
+type txManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type currencyUpdater interface {
 	UpdateCurrencyRate(ctx context.Context) error
+	UpdateCurrencyRateOnDate(ctx context.Context, date time.Time) error
+}
+
+type config interface {
+	GetBudgetThresholds() []int
+	GetDefaultLimit() int
+}
+
+type reporter interface {
+	SendReport(ctx context.Context, userID int64, dateBegin, dateEnd time.Time) error
+	SendHistory(ctx context.Context, userID int64) error
+	SendCurrencies(ctx context.Context, userID int64, query string) error
 }
 
 type Model struct {
@@ -55,34 +139,70 @@ type Model struct {
 	usersDB         usersDB
 	ratesDB         ratesDB
 	limitsDB        limitsDB
+	budgetsDB       budgetsDB
+	recurringDB     recurringDB
+	importsDB       importsDB
+	accountsDB      accountsDB
+	groupsDB        groupsDB
+	splitsDB        splitsDB
+	categoriesDB    categoriesDB
+	txManager       txManager
 	currencyUpdater currencyUpdater
+	reporter        reporter
+	config          config
+
+	currenciesMtx      sync.Mutex
+	currencies         types.Currencies
+	currenciesLoadedAt time.Time
 }
 
-func New(tgClient messageSender, expensesDB expensesDB, usersDB usersDB, ratesDB ratesDB, limitsDB limitsDB, updater currencyUpdater) *Model {
+func New(tgClient messageSender, expensesDB expensesDB, usersDB usersDB, ratesDB ratesDB, limitsDB limitsDB,
+	budgetsDB budgetsDB, recurringDB recurringDB, importsDB importsDB, accountsDB accountsDB, groupsDB groupsDB,
+	splitsDB splitsDB, categoriesDB categoriesDB, txManager txManager, updater currencyUpdater,
+	reporter reporter, config config) *Model {
 	return &Model{
 		tgClient:        tgClient,
 		expensesDB:      expensesDB,
 		usersDB:         usersDB,
 		ratesDB:         ratesDB,
 		limitsDB:        limitsDB,
+		budgetsDB:       budgetsDB,
+		recurringDB:     recurringDB,
+		importsDB:       importsDB,
+		accountsDB:      accountsDB,
+		groupsDB:        groupsDB,
+		splitsDB:        splitsDB,
+		categoriesDB:    categoriesDB,
+		txManager:       txManager,
 		currencyUpdater: updater,
+		reporter:        reporter,
+		config:          config,
 	}
 }
 
 type Message struct {
-	Text      string
-	UserID    int64
-	MessageID int
+	Text string
+	// UserID is the chat: the user in a private chat or the group whose ledger its members share.
+	UserID     int64
+	MemberID   int64 // Who wrote the message, the same as UserID in a private chat.
+	MemberName string
+	MessageID  int
+	Document   *Document // Nil if the message has no file.
 }
 
 const (
-	defaultLimit = 1000000 // in kopecks
+	kopecksInRouble = 100.0
+	currenciesTTL   = time.Hour
 
-	getReportMsg      = "Запросить отчет за:"
-	changeCurrencyMsg = "Выберите валюту"
-	setLimitMsg       = "Введите два числа через пробел - номер месяца (от 1 до 12) и новый лимит на данный месяц"
+	getReportMsg = "Запросить отчет за:"
+	setLimitMsg  = "Введите месяц в формате YYYY-MM (или номер месяца этого года) и лимит через пробел, " +
+		"например 2026-11 50000. Чтобы задать лимит для всех месяцев без своего лимита, введите default 50000"
 	incorrectLimitMsg = "Ошибка при обновлении лимита. Проверьте корректность введенных данных"
 	limitExceededMsg  = "Внимание, лимит трат в этом месяце исчерпан!"
+	addExpenseMsg     = "Введите трату в формате: /add 350.50 кафе вчера [USD]"
+	reportPeriodMsg   = "Введите период в формате: /report YYYY-MM-DD YYYY-MM-DD"
+	groupStartMsg     = "Общий бюджет группы создан: траты всех участников попадают в него, " +
+		"у каждой траты отмечено, кто ее оплатил, а отчеты показывают траты по участникам"
 )
 
 func (s *Model) newExpenseMsg(ctx context.Context, userID int64) string {
@@ -117,22 +237,77 @@ func (s *Model) IncomingMessage(ctx context.Context, msg *Message) error {
 	span.SetTag("message", msg.Text)
 	defer span.Finish()
 
+	if types.IsGroupChat(msg.UserID) {
+		err := s.groupsDB.AddMember(ctx, msg.UserID, types.Member{ID: msg.MemberID, Name: msg.MemberName})
+
+		if err != nil {
+			return errors.Wrap(err, "cannot AddMember")
+		}
+	}
+
+	// A file sent to the bot can only be a bank statement to import.
+	// The files of a group are the members' own, the statements are imported in the private chat.
+	if msg.Document != nil {
+		if types.IsGroupChat(msg.UserID) {
+			return nil
+		}
+
+		return s.importStatement(ctx, msg)
+	}
+
 	// Trying to recognize the command.
-	switch msg.Text {
+	command, args := splitCommand(msg.Text)
+	switch command {
 	case "/start":
+		if types.IsGroupChat(msg.UserID) {
+			return s.tgClient.SendMessage(groupStartMsg+"\n\n"+splitHelpMsg, msg.UserID)
+		}
+
 		return s.tgClient.SendMessage("hello", msg.UserID)
 	case "/new_expense":
-		return s.tgClient.CreateExpense(s.newExpenseMsg(ctx, msg.UserID), msg.UserID)
-	case "/change_currency":
-		return s.tgClient.ChangeCurrency(changeCurrencyMsg, msg.UserID)
+		_, err := s.tgClient.CreateExpense(s.newExpenseMsg(ctx, msg.UserID), msg.UserID)
+		return err
+	case "/income":
+		return s.newIncome(ctx, msg)
+	case "/add":
+		return s.addExpenseCommand(ctx, msg, args)
+	case "/currency", "/change_currency":
+		return s.reporter.SendCurrencies(ctx, msg.UserID, args)
 	case "/get_report":
 		return s.tgClient.GetReport(getReportMsg, msg.UserID)
+	case "/report":
+		return s.reportCommand(ctx, msg, args)
+	case "/history":
+		return s.historyCommand(ctx, msg, args)
+	case "/recurring":
+		return s.recurringCommand(ctx, msg, args)
+	case "/budget":
+		return s.budgetCommand(ctx, msg, args)
+	case "/export":
+		return s.exportCommand(ctx, msg, args)
+	case "/import_profile":
+		return s.importProfileCommand(ctx, msg, args)
+	case "/import_rule":
+		return s.importRuleCommand(ctx, msg, args)
+	case "/account":
+		return s.accountCommand(ctx, msg, args)
+	case "/transfer":
+		return s.transferCommand(ctx, msg, args)
+	case "/balance":
+		return s.balanceCommand(ctx, msg)
+	case "/split":
+		return s.splitCommand(ctx, msg, args)
+	case "/debts":
+		return s.debtsCommand(ctx, msg, args)
+	case "/categories":
+		return s.categoriesCommand(ctx, msg, args)
 	case "/set_limit":
-		return s.setLimit(ctx, msg)
+		return s.setLimit(ctx, msg, args)
 	}
 
 	// It is not a known command - maybe it is message to change the state.
-	if userState, ok := s.usersDB.GetCurrentState(ctx, msg.UserID); ok {
+	// In a group only the member who has pressed the button is waited for, the others write as usual.
+	if userState, ok := s.usersDB.GetCurrentState(ctx, msg.UserID); ok && userState.CurrentState.IsOf(msg.UserID, msg.MemberID) {
 		switch userState.CurrentState.State {
 		case types.EditingSum:
 			return s.sumEntered(ctx, msg, userState.CurrentState)
@@ -155,5 +330,34 @@ func (s *Model) IncomingMessage(ctx context.Context, msg *Message) error {
 		}
 	}
 
+	// The members of a group talk to each other, only commands are meant for the bot.
+	if types.IsGroupChat(msg.UserID) {
+		if strings.HasPrefix(command, "/") {
+			return s.tgClient.SendMessage("не знаю эту команду", msg.UserID)
+		}
+
+		return nil
+	}
+
+	// Maybe it is an expense written in one line.
+	currencies := s.getCurrencies(ctx)
+
+	if expense, err := parseExpense(msg.Text, time.Now(), currencies); err == nil {
+		return s.addExpense(ctx, msg, expense)
+	}
+
 	return s.tgClient.SendMessage("не знаю эту команду", msg.UserID)
 }
+
+// splitCommand separates "/command" from its arguments.
+// In groups the command may be addressed to the bot as "/command@BotName".
+func splitCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return text, ""
+	}
+
+	command, args, _ := strings.Cut(text, " ")
+	command, _, _ = strings.Cut(command, "@")
+	return command, strings.TrimSpace(args)
+}
@@ -2,6 +2,8 @@ package messages
 
 import (
 	"context"
+	"log"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -43,14 +45,21 @@ func (s *Model) sumEntered(ctx context.Context, msg *Message, userState types.Cu
 		return errors.Wrap(err, "cannot GetExpense")
 	}
 
+	var previous *types.Expense
+
 	if expense == nil {
 		// Then we work with this expense for the first time.
-		expense = types.NewExpense()
-		expense.ExpenseID = userState.ExpenseID
-		err := s.initializeExpense(ctx, msg, expense)
+		expense, err = s.newCardExpense(ctx, msg.UserID, userState.ExpenseID)
+		if err != nil {
+			return errors.Wrap(err, "cannot newCardExpense")
+		}
+
+		err = s.initializeExpense(ctx, msg, expense)
 		if err != nil {
 			return errors.Wrap(err, "cannot InitializeExpense")
 		}
+	} else {
+		previous = copyExpense(expense)
 	}
 
 	currency, err := s.getUserCurrency(ctx, msg.UserID)
@@ -59,29 +68,31 @@ func (s *Model) sumEntered(ctx context.Context, msg *Message, userState types.Cu
 		return errors.Wrap(err, "cannot GetUserCurrency")
 	}
 
-	rate, err := s.getCurrentCurrencyRate(ctx, currency)
+	// Convert at the rate of the expense date, so the sum does not depend on when it was entered.
+	rate, err := s.getCurrencyRate(ctx, currency, expense.Date)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+		return errors.Wrap(err, "cannot getCurrencyRate")
 	}
 
 	// Change value of the expense.
-	int_sum := int(sum * float64(rate))
-	expense.Sum = int_sum
-	err = s.expensesDB.WriteSum(ctx, int_sum, msg.UserID, userState.ExpenseID)
+	expense.OriginalSum = int(math.Round(sum * kopecksInRouble))
+	expense.Sum = rate.ToKopecks(expense.OriginalSum)
+	expense.OriginalCurrency = currency
+	err = s.expensesDB.WriteSum(ctx, expense.Sum, expense.OriginalSum, currency, msg.UserID, userState.ExpenseID)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot WriteSum")
 	}
 
-	err = s.checkCategorySum(ctx, msg.UserID, expense)
+	err = s.checkLimits(ctx, msg.UserID, expense, previous)
 	if err != nil {
-		return errors.Wrap(err, "cannot checkCategorySum")
+		return errors.Wrap(err, "cannot checkLimits")
 	}
 
-	err = s.tgClient.DeleteMessage(msg.UserID, msg.MessageID)
+	err = s.deleteEnteredMessage(msg)
 	if err != nil {
-		return errors.Wrap(err, "cannot DeleteMessage")
+		return errors.Wrap(err, "cannot deleteEnteredMessage")
 	}
 
 	// Changing state to the waiting one.
@@ -94,19 +105,36 @@ func (s *Model) sumEntered(ctx context.Context, msg *Message, userState types.Cu
 	return s.editExpenseAfterEditing(ctx, expense, msg.UserID, userState.ExpenseID)
 }
 
-func (s *Model) checkCategorySum(ctx context.Context, userID int64, expense *types.Expense) error {
-	limit, ok, err := s.limitsDB.GetLimit(ctx, userID, int(expense.Date.Month()))
+// copyExpense keeps the expense as it was before it is edited.
+func copyExpense(expense *types.Expense) *types.Expense {
+	previous := *expense
+	return &previous
+}
+
+// checkLimits checks both the monthly limit and the budget of the expense category, income has no limits.
+// The previous state of an edited expense is nil for a new one.
+func (s *Model) checkLimits(ctx context.Context, userID int64, expense, previous *types.Expense) error {
+	if expense.Kind == types.KindIncome {
+		return nil
+	}
+
+	err := s.checkMonthLimit(ctx, userID, expense)
+	if err != nil {
+		return errors.Wrap(err, "cannot checkMonthLimit")
+	}
+
+	return s.checkCategorySum(ctx, userID, expense, previous)
+}
+
+func (s *Model) checkMonthLimit(ctx context.Context, userID int64, expense *types.Expense) error {
+	limit, ok, err := s.limitsDB.GetLimit(ctx, userID, expense.Date)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot GetLimit")
 	}
 
 	if !ok {
-		limit = defaultLimit
-		err := s.limitsDB.SetLimit(ctx, userID, int(expense.Date.Month()), limit)
-		if err != nil {
-			return errors.Wrap(err, "cannot SetLimit")
-		}
+		limit = s.config.GetDefaultLimit()
 	}
 
 	currentMonthExpenses, err := s.expensesDB.GetMonthReport(ctx, userID, expense.Date)
@@ -130,27 +158,43 @@ func (s *Model) categoryEntered(ctx context.Context, msg *Message, userState typ
 		return errors.Wrap(err, "cannot GetExpense")
 	}
 
+	var previous *types.Expense
+
 	if expense == nil {
 		// Then we work with this expense for the first time.
-		expense = types.NewExpense()
-		expense.ExpenseID = userState.ExpenseID
-		err := s.initializeExpense(ctx, msg, expense)
+		expense, err = s.newCardExpense(ctx, msg.UserID, userState.ExpenseID)
+		if err != nil {
+			return errors.Wrap(err, "cannot newCardExpense")
+		}
+
+		err = s.initializeExpense(ctx, msg, expense)
 		if err != nil {
 			return errors.Wrap(err, "cannot initializeExpense")
 		}
+	} else {
+		previous = copyExpense(expense)
+	}
+
+	expense.Category, err = s.resolveCategory(ctx, msg.UserID, msg.Text)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot resolveCategory")
 	}
 
-	expense.Category = msg.Text
-	err = s.expensesDB.WriteCategory(ctx, msg.Text, msg.UserID, userState.ExpenseID)
+	err = s.expensesDB.WriteCategory(ctx, expense.Category, msg.UserID, userState.ExpenseID)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot WriteCategory")
 	}
 
-	err = s.tgClient.DeleteMessage(msg.UserID, msg.MessageID)
+	err = s.checkCategorySum(ctx, msg.UserID, expense, previous)
+	if err != nil {
+		return errors.Wrap(err, "cannot checkCategorySum")
+	}
 
+	err = s.deleteEnteredMessage(msg)
 	if err != nil {
-		return errors.Wrap(err, "cannot DeleteMessage")
+		return errors.Wrap(err, "cannot deleteEnteredMessage")
 	}
 
 	err = s.usersDB.ToWaitState(ctx, msg.UserID)
@@ -175,14 +219,21 @@ func (s *Model) dateEntered(ctx context.Context, msg *Message, userState types.C
 		return errors.Wrap(err, "cannot GetExpense")
 	}
 
+	var previous *types.Expense
+
 	if expense == nil {
 		// Then we work with this expense for the first time.
-		expense = types.NewExpense()
-		expense.ExpenseID = userState.ExpenseID
-		err := s.initializeExpense(ctx, msg, expense)
+		expense, err = s.newCardExpense(ctx, msg.UserID, userState.ExpenseID)
+		if err != nil {
+			return errors.Wrap(err, "cannot newCardExpense")
+		}
+
+		err = s.initializeExpense(ctx, msg, expense)
 		if err != nil {
 			return errors.Wrap(err, "cannot initializeExpense")
 		}
+	} else {
+		previous = copyExpense(expense)
 	}
 
 	expense.Date = date
@@ -192,10 +243,32 @@ func (s *Model) dateEntered(ctx context.Context, msg *Message, userState types.C
 		return errors.Wrap(err, "cannot WriteDate")
 	}
 
-	err = s.tgClient.DeleteMessage(msg.UserID, msg.MessageID)
+	// The sum in kopecks follows the rate of the new date.
+	if expense.OriginalCurrency != "" && expense.OriginalCurrency != types.RUB {
+		rate, err := s.getCurrencyRate(ctx, expense.OriginalCurrency, date)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot getCurrencyRate")
+		}
+
+		expense.Sum = rate.ToKopecks(expense.OriginalSum)
+		err = s.expensesDB.WriteSum(ctx, expense.Sum, expense.OriginalSum, expense.OriginalCurrency,
+			msg.UserID, userState.ExpenseID)
 
+		if err != nil {
+			return errors.Wrap(err, "cannot WriteSum")
+		}
+	}
+
+	// The expense may move to the month of another budget.
+	err = s.checkCategorySum(ctx, msg.UserID, expense, previous)
+	if err != nil {
+		return errors.Wrap(err, "cannot checkCategorySum")
+	}
+
+	err = s.deleteEnteredMessage(msg)
 	if err != nil {
-		return errors.Wrap(err, "cannot DeleteMessage")
+		return errors.Wrap(err, "cannot deleteEnteredMessage")
 	}
 
 	err = s.usersDB.ToWaitState(ctx, msg.UserID)
@@ -206,47 +279,193 @@ func (s *Model) dateEntered(ctx context.Context, msg *Message, userState types.C
 	return s.editExpenseAfterEditing(ctx, expense, msg.UserID, userState.ExpenseID)
 }
 
+// limitEntered parses "YYYY-MM limit", "MM limit" for the current year or "default limit".
 func (s *Model) limitEntered(ctx context.Context, msg *Message) error {
-	numbers := strings.Split(msg.Text, " ")
+	words := strings.Fields(msg.Text)
 
-	if len(numbers) != 2 {
+	if len(words) != 2 {
 		return errors.New("incorrect input")
 	}
 
-	month, err := strconv.ParseInt(numbers[0], 10, 64)
+	limit, err := strconv.ParseInt(words[1], 10, 64)
 
 	if err != nil {
 		return errors.Wrap(err, "cannot ParseInt")
 	}
 
-	limit, err := strconv.ParseInt(numbers[1], 10, 64)
+	currency, err := s.getUserCurrency(ctx, msg.UserID)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot ParseInt")
+		return errors.Wrap(err, "cannot getUserCurrency")
+	}
+
+	rate, err := s.getCurrentCurrencyRate(ctx, currency)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+	}
+
+	if words[0] == "default" {
+		err = s.limitsDB.SetDefaultLimit(ctx, msg.UserID, rate.ToKopecks(int(limit)*int(kopecksInRouble)))
+		return errors.Wrap(err, "cannot SetDefaultLimit")
+	}
+
+	month, err := parseLimitMonth(words[0], time.Now())
+
+	if err != nil {
+		return errors.Wrap(err, "cannot parseLimitMonth")
+	}
+
+	err = s.limitsDB.SetLimit(ctx, msg.UserID, month.Year(), int(month.Month()), rate.ToKopecks(int(limit)*int(kopecksInRouble)))
+
+	return errors.Wrap(err, "cannot SetLimit")
+}
+
+func parseLimitMonth(value string, now time.Time) (time.Time, error) {
+	if month, err := time.Parse("2006-01", value); err == nil {
+		return month, nil
+	}
+
+	month, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "cannot ParseInt")
 	}
 
 	if month < 1 || month > 12 {
-		return errors.New("month is incorrect")
+		return time.Time{}, errors.New("month is incorrect")
 	}
 
-	currency, err := s.getUserCurrency(ctx, msg.UserID)
+	return time.Date(now.Year(), time.Month(month), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func (s *Model) addExpenseCommand(ctx context.Context, msg *Message, args string) error {
+	currencies := s.getCurrencies(ctx)
+
+	expense, err := parseExpense(args, time.Now(), currencies)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot getUserCurrency")
+		err = s.tgClient.SendMessage(addExpenseMsg, msg.UserID)
+		if err != nil {
+			return errors.Wrap(err, "cannot SendMessage")
+		}
+
+		return nil
 	}
 
-	rate, err := s.getCurrentCurrencyRate(ctx, currency)
+	return s.addExpense(ctx, msg, expense)
+}
+
+// addExpense saves the expense entered in one line and answers with the usual
+// expense card, so it can be corrected with the buttons.
+func (s *Model) addExpense(ctx context.Context, msg *Message, parsed *parsedExpense) error {
+	expense, err := s.createExpense(ctx, msg, parsed)
 
 	if err != nil {
-		return errors.Wrap(err, "cannot getCurrentCurrencyRate")
+		return errors.Wrap(err, "cannot createExpense")
 	}
 
-	err = s.limitsDB.SetLimit(ctx, msg.UserID, int(month), int(limit)*rate)
+	return s.checkLimits(ctx, msg.UserID, expense, nil)
+}
 
-	return errors.Wrap(err, "cannot SetLimit")
+// createExpense writes the parsed expense and sends its card.
+func (s *Model) createExpense(ctx context.Context, msg *Message, parsed *parsedExpense) (*types.Expense, error) {
+	expense, err := s.sendExpenseCard(ctx, msg, parsed)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot sendExpenseCard")
+	}
+
+	err = s.expensesDB.WriteExpense(ctx, msg.UserID, expense)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot WriteExpense")
+	}
+
+	return expense, nil
+}
+
+// sendExpenseCard sends the card of the parsed expense and returns the expense with the id of the card,
+// it is left to the caller to write it.
+func (s *Model) sendExpenseCard(ctx context.Context, msg *Message, parsed *parsedExpense) (*types.Expense, error) {
+	userCurrency, err := s.getUserCurrency(ctx, msg.UserID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getUserCurrency")
+	}
+
+	userRate, err := s.getCurrentCurrencyRate(ctx, userCurrency)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getCurrentCurrencyRate")
+	}
+
+	currency := userCurrency
+	if parsed.Currency != "" {
+		currency = parsed.Currency
+	}
+
+	category, err := s.resolveCategory(ctx, msg.UserID, parsed.Category)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot resolveCategory")
+	}
+
+	rate, err := s.getCurrencyRate(ctx, currency, parsed.Date)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot getCurrencyRate")
+	}
+
+	originalSum := int(math.Round(parsed.Amount * kopecksInRouble))
+	expense := &types.Expense{
+		Sum:              rate.ToKopecks(originalSum),
+		Category:         category,
+		Date:             parsed.Date,
+		OriginalSum:      originalSum,
+		OriginalCurrency: currency,
+		PaidBy:           msg.MemberID,
+	}
+
+	if types.IsGroupChat(msg.UserID) {
+		expense.PaidByName = msg.MemberName
+	}
+
+	message := expense.ToString(&types.UserModel{
+		Currency:     string(userCurrency),
+		CurrencyRate: userRate,
+	})
+
+	expense.ExpenseID, err = s.tgClient.CreateExpense(message, msg.UserID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot CreateExpense")
+	}
+
+	return expense, nil
+}
+
+// newCardExpense returns the entry of a card which is not written yet, an income for the cards of /income.
+func (s *Model) newCardExpense(ctx context.Context, userID int64, cardID int) (*types.Expense, error) {
+	income, err := s.expensesDB.IsIncomeCard(ctx, userID, cardID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot IsIncomeCard")
+	}
+
+	expense := types.NewExpense()
+	if income {
+		expense = types.NewIncome()
+	}
+
+	expense.ExpenseID = cardID
+
+	return expense, nil
 }
 
 func (s *Model) initializeExpense(ctx context.Context, msg *Message, expense *types.Expense) error {
+	expense.PaidBy = msg.MemberID
+
 	err := s.expensesDB.WriteExpense(ctx, msg.UserID, expense)
 	if err != nil {
 		return errors.Wrap(err, "cannot WriteExpense")
@@ -255,27 +474,61 @@ func (s *Model) initializeExpense(ctx context.Context, msg *Message, expense *ty
 	return nil
 }
 
-func (s *Model) getCurrentCurrencyRate(ctx context.Context, c types.Currency) (int, error) {
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
+// getCurrencies returns the currencies the rates are known for. The list changes only when
+// the rates are updated, so it is kept for currenciesTTL; if it cannot be read, the last one
+// or only the rouble is used, for the message to be parsed anyway.
+func (s *Model) getCurrencies(ctx context.Context) types.Currencies {
+	s.currenciesMtx.Lock()
+	defer s.currenciesMtx.Unlock()
 
-	rate, err := s.ratesDB.GetCurrencyRate(ctx, c, time.Now())
+	if s.currencies != nil && time.Since(s.currenciesLoadedAt) < currenciesTTL {
+		return s.currencies
+	}
+
+	infos, err := s.ratesDB.GetCurrencies(ctx)
 
 	if err != nil {
-		if err == types.ErrNoCurrencyRate {
-			err := s.currencyUpdater.UpdateCurrencyRate(ctx)
+		log.Println(errors.Wrap(err, "cannot GetCurrencies"))
 
-			if err != nil {
-				return 0, errors.Wrap(err, "cannot UpdateCurrencyRate")
-			}
+		if s.currencies != nil {
+			return s.currencies
+		}
 
-			return s.getCurrentCurrencyRate(ctx, c)
-		} else {
-			return 0, errors.Wrap(err, "cannot GetCurrencyRate")
+		return types.NewCurrencies(nil)
+	}
+
+	s.currencies = types.NewCurrencies(infos)
+	s.currenciesLoadedAt = time.Now()
+
+	return s.currencies
+}
+
+func (s *Model) getCurrentCurrencyRate(ctx context.Context, c types.Currency) (types.Rate, error) {
+	return s.getCurrencyRate(ctx, c, time.Now())
+}
+
+// getCurrencyRate returns the rate of the date, fetching the rates of the date if they are not known yet.
+func (s *Model) getCurrencyRate(ctx context.Context, c types.Currency, date time.Time) (types.Rate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rate, err := s.ratesDB.GetCurrencyRate(ctx, c, date)
+
+	if err == types.ErrNoCurrencyRate {
+		err = s.currencyUpdater.UpdateCurrencyRateOnDate(ctx, date)
+
+		if err != nil {
+			return 0, errors.Wrap(err, "cannot UpdateCurrencyRateOnDate")
 		}
+
+		rate, err = s.ratesDB.GetCurrencyRate(ctx, c, date)
 	}
 
-	return rate, err
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot GetCurrencyRate")
+	}
+
+	return rate, nil
 }
 
 func (s *Model) getUserCurrency(ctx context.Context, userID int64) (types.Currency, error) {
@@ -292,7 +545,7 @@ func (s *Model) getUserCurrency(ctx context.Context, userID int64) (types.Curren
 				return "", errors.Wrap(err, "cannot SetUserCurrency")
 			}
 
-			_, err = s.getUserCurrency(ctx, userID)
+			currency, err = s.getUserCurrency(ctx, userID)
 			if err != nil {
 				return "", errors.Wrap(err, "cannot getUserCurrency")
 			}
@@ -304,10 +557,21 @@ func (s *Model) getUserCurrency(ctx context.Context, userID int64) (types.Curren
 	return currency, nil
 }
 
-func (s *Model) setLimit(ctx context.Context, msg *Message) error {
+func (s *Model) setLimit(ctx context.Context, msg *Message, args string) error {
+	if args != "" {
+		err := s.limitEntered(ctx, &Message{Text: args, UserID: msg.UserID, MessageID: msg.MessageID})
+
+		if err != nil {
+			return s.tgClient.SendMessage(incorrectLimitMsg, msg.UserID)
+		}
+
+		return s.tgClient.SendMessage("Лимит обновлен", msg.UserID)
+	}
+
 	err := s.usersDB.SetCurrentState(ctx, msg.UserID, types.CurrentState{
 		ExpenseID: msg.MessageID,
 		State:     types.EditingLimit,
+		MemberID:  msg.MemberID,
 	})
 
 	if err != nil {
@@ -316,3 +580,73 @@ func (s *Model) setLimit(ctx context.Context, msg *Message) error {
 
 	return s.tgClient.SendMessage(setLimitMsg, msg.UserID)
 }
+
+func (s *Model) reportCommand(ctx context.Context, msg *Message, args string) error {
+	if args == "" {
+		return s.tgClient.GetReport(getReportMsg, msg.UserID)
+	}
+
+	dates := strings.Fields(args)
+	if len(dates) != 2 {
+		return s.tgClient.SendMessage(reportPeriodMsg, msg.UserID)
+	}
+
+	dateBegin, err := time.Parse("2006-01-02", dates[0])
+	if err != nil {
+		return s.tgClient.SendMessage(reportPeriodMsg, msg.UserID)
+	}
+
+	dateEnd, err := time.Parse("2006-01-02", dates[1])
+	if err != nil || dateEnd.Before(dateBegin) {
+		return s.tgClient.SendMessage(reportPeriodMsg, msg.UserID)
+	}
+
+	return s.reporter.SendReport(ctx, msg.UserID, dateBegin, dateEnd)
+}
+
+// historyCommand shows expenses filtered by "[category] [YYYY-MM-DD YYYY-MM-DD]".
+func (s *Model) historyCommand(ctx context.Context, msg *Message, args string) error {
+	var filter types.ExpenseFilter
+
+	words := strings.Fields(args)
+	if len(words) >= 2 {
+		dateBegin, errBegin := time.Parse("2006-01-02", words[len(words)-2])
+		dateEnd, errEnd := time.Parse("2006-01-02", words[len(words)-1])
+
+		if errBegin == nil && errEnd == nil {
+			filter.DateBegin, filter.DateEnd = dateBegin, dateEnd
+			words = words[:len(words)-2]
+		}
+	}
+
+	if len(words) > 0 {
+		category, _, err := s.findCategory(ctx, msg.UserID, strings.Join(words, " "))
+
+		if err != nil {
+			return errors.Wrap(err, "cannot findCategory")
+		}
+
+		filter.Category = category
+	}
+
+	err := s.usersDB.SetHistoryFilter(ctx, msg.UserID, filter)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SetHistoryFilter")
+	}
+
+	return s.reporter.SendHistory(ctx, msg.UserID)
+}
+
+// deleteEnteredMessage deletes the message with the entered value to keep the chat clean.
+// The bot may have no right to delete messages in a group, then they stay.
+func (s *Model) deleteEnteredMessage(msg *Message) error {
+	err := s.tgClient.DeleteMessage(msg.UserID, msg.MessageID)
+
+	if err != nil && types.IsGroupChat(msg.UserID) {
+		log.Println("cannot delete the message in the group:", err)
+		return nil
+	}
+
+	return err
+}
@@ -51,7 +51,7 @@ func Test_OnSumState_ShouldEditExpenseMessage(t *testing.T) {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
-	updater := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	updater := currency.NewRateUpdater(config, ratesDB, []currency.Provider{currency.NewCbrProvider(config.GetUrl())})
 	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
 
 	for i := 0; i < 10; i++ {
@@ -84,7 +84,7 @@ func Test_OnSumState_ShouldEditExpenseMessage(t *testing.T) {
 
 		message := exp.ToString(&types.UserModel{
 			Currency:     string(types.RUB),
-			CurrencyRate: 100,
+			CurrencyRate: types.UnitRate,
 		})
 		sender.EXPECT().DeleteMessage(int64(i), 123)
 		sender.EXPECT().EditExpenseMessage(message, int64(i), 123)
@@ -114,7 +114,7 @@ func Test_OnSumState_DifferentCurrencies(t *testing.T) {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
-	updater := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	updater := currency.NewRateUpdater(config, ratesDB, []currency.Provider{currency.NewCbrProvider(config.GetUrl())})
 	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
 
 	for i := 0; i < 10; i++ {
@@ -204,7 +204,7 @@ func Test_OnSumStateIncorrectInput_ShouldGiveError(t *testing.T) {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
-	updater := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	updater := currency.NewRateUpdater(config, ratesDB, []currency.Provider{currency.NewCbrProvider(config.GetUrl())})
 	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
 
 	err = expensesDB.DeleteExpense(ctx, int64(0), 123)
@@ -242,7 +242,7 @@ func Test_OnCategoryState_ShouldEditExpenseMessage(t *testing.T) {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
-	updater := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	updater := currency.NewRateUpdater(config, ratesDB, []currency.Provider{currency.NewCbrProvider(config.GetUrl())})
 	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
 
 	err = expensesDB.DeleteExpense(ctx, int64(0), 123)
@@ -300,7 +300,7 @@ func Test_OnDateState_ShouldEditExpenseMessage(t *testing.T) {
 	usersDB := database.NewUsersDB(db)
 	ratesDB := database.NewRatesDB(db)
 	limitsDB := database.NewLimitsDB(db)
-	updater := currency.NewCbrCurrencyUpdater(config, ratesDB)
+	updater := currency.NewRateUpdater(config, ratesDB, []currency.Provider{currency.NewCbrProvider(config.GetUrl())})
 	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
 
 	for i := 0; i < 10; i++ {
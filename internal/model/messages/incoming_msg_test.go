@@ -5,23 +5,68 @@ import (
 	"os"
 	"os/signal"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	mocks "gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/mocks/messages"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/statement"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
 )
 
-func Test_OnStartCommand_ShouldAnswerWithIntroMessage(t *testing.T) {
+// testDeps are the mocks the model of newTestModel is made of.
+type testDeps struct {
+	sender       *mocks.MockmessageSender
+	expensesDB   *mocks.MockexpensesDB
+	usersDB      *mocks.MockusersDB
+	ratesDB      *mocks.MockratesDB
+	limitsDB     *mocks.MocklimitsDB
+	budgetsDB    *mocks.MockbudgetsDB
+	recurringDB  *mocks.MockrecurringDB
+	importsDB    *mocks.MockimportsDB
+	accountsDB   *mocks.MockaccountsDB
+	groupsDB     *mocks.MockgroupsDB
+	splitsDB     *mocks.MocksplitsDB
+	categoriesDB *mocks.MockcategoriesDB
+	txManager    *mocks.MocktxManager
+	updater      *mocks.MockcurrencyUpdater
+	reporter     *mocks.Mockreporter
+	config       *mocks.Mockconfig
+}
+
+func newTestModel(t *testing.T) (*Model, *testDeps) {
 	ctrl := gomock.NewController(t)
-	sender := mocks.NewMockmessageSender(ctrl)
-	expensesDB := mocks.NewMockexpensesDB(ctrl)
-	usersDB := mocks.NewMockusersDB(ctrl)
-	ratesDB := mocks.NewMockratesDB(ctrl)
-	limitsDB := mocks.NewMocklimitsDB(ctrl)
-	updater := mocks.NewMockcurrencyUpdater(ctrl)
-	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
+	deps := &testDeps{
+		sender:       mocks.NewMockmessageSender(ctrl),
+		expensesDB:   mocks.NewMockexpensesDB(ctrl),
+		usersDB:      mocks.NewMockusersDB(ctrl),
+		ratesDB:      mocks.NewMockratesDB(ctrl),
+		limitsDB:     mocks.NewMocklimitsDB(ctrl),
+		budgetsDB:    mocks.NewMockbudgetsDB(ctrl),
+		recurringDB:  mocks.NewMockrecurringDB(ctrl),
+		importsDB:    mocks.NewMockimportsDB(ctrl),
+		accountsDB:   mocks.NewMockaccountsDB(ctrl),
+		groupsDB:     mocks.NewMockgroupsDB(ctrl),
+		splitsDB:     mocks.NewMocksplitsDB(ctrl),
+		categoriesDB: mocks.NewMockcategoriesDB(ctrl),
+		txManager:    mocks.NewMocktxManager(ctrl),
+		updater:      mocks.NewMockcurrencyUpdater(ctrl),
+		reporter:     mocks.NewMockreporter(ctrl),
+		config:       mocks.NewMockconfig(ctrl),
+	}
+
+	model := New(deps.sender, deps.expensesDB, deps.usersDB, deps.ratesDB, deps.limitsDB, deps.budgetsDB,
+		deps.recurringDB, deps.importsDB, deps.accountsDB, deps.groupsDB, deps.splitsDB, deps.categoriesDB,
+		deps.txManager, deps.updater, deps.reporter, deps.config)
+
+	return model, deps
+}
+
+func Test_OnStartCommand_ShouldAnswerWithIntroMessage(t *testing.T) {
+	model, deps := newTestModel(t)
 
-	sender.EXPECT().SendMessage("hello", int64(123))
+	deps.sender.EXPECT().SendMessage("hello", int64(123))
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
@@ -35,20 +80,13 @@ func Test_OnStartCommand_ShouldAnswerWithIntroMessage(t *testing.T) {
 }
 
 func Test_OnNewExpenseCommand_ShouldCreateNewExpense(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	sender := mocks.NewMockmessageSender(ctrl)
-	expensesDB := mocks.NewMockexpensesDB(ctrl)
-	usersDB := mocks.NewMockusersDB(ctrl)
-	ratesDB := mocks.NewMockratesDB(ctrl)
-	limitsDB := mocks.NewMocklimitsDB(ctrl)
-	updater := mocks.NewMockcurrencyUpdater(ctrl)
-	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
+	model, deps := newTestModel(t)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 
-	usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123))
-	ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), gomock.Any(), gomock.Any())
-	sender.EXPECT().CreateExpense(gomock.Any(), int64(123))
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123))
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), gomock.Any(), gomock.Any())
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(123))
 
 	defer cancel()
 	err := model.IncomingMessage(ctx, &Message{
@@ -60,16 +98,9 @@ func Test_OnNewExpenseCommand_ShouldCreateNewExpense(t *testing.T) {
 }
 
 func Test_OnGetReportCommand_ShouldCreateReport(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	sender := mocks.NewMockmessageSender(ctrl)
-	expensesDB := mocks.NewMockexpensesDB(ctrl)
-	usersDB := mocks.NewMockusersDB(ctrl)
-	ratesDB := mocks.NewMockratesDB(ctrl)
-	limitsDB := mocks.NewMocklimitsDB(ctrl)
-	updater := mocks.NewMockcurrencyUpdater(ctrl)
-	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
+	model, deps := newTestModel(t)
 
-	sender.EXPECT().GetReport("Запросить отчет за:", int64(123))
+	deps.sender.EXPECT().GetReport("Запросить отчет за:", int64(123))
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
@@ -82,21 +113,14 @@ func Test_OnGetReportCommand_ShouldCreateReport(t *testing.T) {
 }
 
 func Test_OnUnknownCommand_ShouldAnswerWithHelpMessage(t *testing.T) {
-	ctrl := gomock.NewController(t)
-
-	sender := mocks.NewMockmessageSender(ctrl)
-	expensesDB := mocks.NewMockexpensesDB(ctrl)
-	usersDB := mocks.NewMockusersDB(ctrl)
-	ratesDB := mocks.NewMockratesDB(ctrl)
-	limitsDB := mocks.NewMocklimitsDB(ctrl)
-	updater := mocks.NewMockcurrencyUpdater(ctrl)
-	model := New(sender, expensesDB, usersDB, ratesDB, limitsDB, updater)
+	model, deps := newTestModel(t)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(123))
-	sender.EXPECT().SendMessage("не знаю эту команду", int64(123))
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(123))
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any())
+	deps.sender.EXPECT().SendMessage("не знаю эту команду", int64(123))
 
 	err := model.IncomingMessage(ctx, &Message{
 		Text:   "some text",
@@ -105,3 +129,475 @@ func Test_OnUnknownCommand_ShouldAnswerWithHelpMessage(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func Test_OnUnknownCommand_ShouldAnswerWithHelpMessageWhenCurrenciesFail(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(123)).Times(3)
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any()).Return(nil, errors.New("connection refused"))
+	// The list read after the error is kept for the next messages.
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any()).Return([]types.CurrencyInfo{}, nil)
+	deps.sender.EXPECT().SendMessage("не знаю эту команду", int64(123)).Times(3)
+
+	for i := 0; i < 3; i++ {
+		err := model.IncomingMessage(ctx, &Message{
+			Text:   "some text",
+			UserID: 123,
+		})
+
+		assert.NoError(t, err)
+	}
+}
+
+func Test_OnAddCommand_ShouldWriteExpense(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any())
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).Times(2)
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(types.DefaultCategories, nil).Times(2)
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(123)).Return(456, nil)
+	deps.expensesDB.EXPECT().WriteExpense(gomock.Any(), int64(123), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID int64, expense *types.Expense) error {
+			assert.Equal(t, 456, expense.ExpenseID)
+			assert.Equal(t, 35050, expense.Sum)
+			assert.Equal(t, "Кафе", expense.Category)
+			assert.Equal(t, 35050, expense.OriginalSum)
+			assert.Equal(t, types.RUB, expense.OriginalCurrency)
+			return nil
+		})
+	deps.limitsDB.EXPECT().GetLimit(gomock.Any(), int64(123), gomock.Any()).Return(0, false, nil)
+	deps.config.EXPECT().GetDefaultLimit().Return(1000000)
+	deps.expensesDB.EXPECT().GetMonthReport(gomock.Any(), int64(123), gomock.Any()).Return(35050, nil)
+	deps.budgetsDB.EXPECT().GetBudget(gomock.Any(), int64(123), "Кафе", gomock.Any()).Return(0, false, nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/add 350.50 кафе вчера",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnReportCommand_ShouldSendReportForPeriod(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.reporter.EXPECT().SendReport(gomock.Any(), int64(123),
+		time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC))
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/report 2026-09-01 2026-09-30",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnHistoryCommand_ShouldSaveFilterAndSendHistory(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(types.DefaultCategories, nil)
+	deps.usersDB.EXPECT().SetHistoryFilter(gomock.Any(), int64(123), types.ExpenseFilter{
+		Category:  "Кафе у дома",
+		DateBegin: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		DateEnd:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	})
+	deps.reporter.EXPECT().SendHistory(gomock.Any(), int64(123))
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/history кафе у дома 2026-09-01 2026-09-30",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnSetLimitCommandWithMonth_ShouldSetLimitOfThisMonth(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil).Times(2)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).Times(2)
+	deps.limitsDB.EXPECT().SetLimit(gomock.Any(), int64(123), 2026, 11, 5000000)
+	deps.limitsDB.EXPECT().SetDefaultLimit(gomock.Any(), int64(123), 3000000)
+	deps.sender.EXPECT().SendMessage("Лимит обновлен", int64(123)).Times(2)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/set_limit 2026-11 50000",
+		UserID: 123,
+	})
+	assert.NoError(t, err)
+
+	err = model.IncomingMessage(ctx, &Message{
+		Text:   "/set_limit default 30000",
+		UserID: 123,
+	})
+	assert.NoError(t, err)
+}
+
+func Test_OnStatementDocument_ShouldSendImportPreview(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	data := "date,amount,description\n" +
+		"2026-10-01,-350.50,PYATEROCHKA 123\n" +
+		"2026-10-02,1000,SALARY\n" +
+		"2026-10-03,-99,YANDEX TAXI\n"
+
+	deps.sender.EXPECT().DownloadFile("file").Return([]byte(data), nil)
+	deps.importsDB.EXPECT().GetImportProfile(gomock.Any(), int64(123)).Return(statement.DefaultProfile(), nil)
+	deps.importsDB.EXPECT().GetCategoryRules(gomock.Any(), int64(123)).Return([]statement.Rule{
+		{Pattern: "pyaterochka", Category: "продукты"},
+	}, nil)
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil)
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any())
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).Times(2)
+	deps.importsDB.EXPECT().CreatePendingImport(gomock.Any(), int64(123), []types.Expense{
+		{Sum: 35050, Category: "продукты", Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			OriginalSum: 35050, OriginalCurrency: types.RUB},
+		{Sum: 9900, Category: "Прочее", Date: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+			OriginalSum: 9900, OriginalCurrency: types.RUB},
+	}).Return(7, nil)
+	deps.sender.EXPECT().SendImportPreview(gomock.Any(), int64(123), 7)
+
+	err := model.IncomingMessage(ctx, &Message{
+		UserID:   123,
+		Document: &Document{FileID: "file", FileName: "statement.csv"},
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnDocumentInGroup_ShouldNotImport(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 321, Name: "Борис"}).Return(nil)
+
+	err := model.IncomingMessage(context.Background(), &Message{
+		UserID:     -100,
+		MemberID:   321,
+		MemberName: "Борис",
+		Document:   &Document{FileID: "file", FileName: "photos.zip"},
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnAddCommandInForeignCurrency_ShouldConvertAtRateOfExpenseDate(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	date := time.Date(2025, 3, 5, 0, 0, 0, 0, time.Local)
+
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any()).Return([]types.CurrencyInfo{{Code: types.USD, Name: "Доллар США"}}, nil)
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil)
+	// The rate of the date is not known yet, so it is fetched first.
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.USD, date).Return(types.Rate(0), types.ErrNoCurrencyRate)
+	deps.updater.EXPECT().UpdateCurrencyRateOnDate(gomock.Any(), date)
+	// The four decimal places of the rate are not lost: 10 * 90.1234 = 901.234 RUB.
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.USD, date).Return(types.Rate(9012340000), nil)
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(types.DefaultCategories, nil).Times(2)
+	deps.categoriesDB.EXPECT().AddCategory(gomock.Any(), int64(123), "Такси").Return(nil)
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(123)).Return(456, nil)
+	deps.expensesDB.EXPECT().WriteExpense(gomock.Any(), int64(123), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID int64, expense *types.Expense) error {
+			assert.Equal(t, 90123, expense.Sum)
+			assert.Equal(t, 1000, expense.OriginalSum)
+			assert.Equal(t, types.USD, expense.OriginalCurrency)
+			return nil
+		})
+	deps.limitsDB.EXPECT().GetLimit(gomock.Any(), int64(123), gomock.Any()).Return(0, false, nil)
+	deps.config.EXPECT().GetDefaultLimit().Return(1000000)
+	deps.expensesDB.EXPECT().GetMonthReport(gomock.Any(), int64(123), gomock.Any()).Return(90123, nil)
+	deps.budgetsDB.EXPECT().GetBudget(gomock.Any(), int64(123), "Такси", gomock.Any()).Return(0, false, nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/add 10 USD такси 05.03.2025",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnCurrencyCommand_ShouldSendFoundCurrencies(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.reporter.EXPECT().SendCurrencies(gomock.Any(), int64(123), "тенге")
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/currency тенге",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnTransferCommand_ShouldConvertBetweenCurrencies(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.accountsDB.EXPECT().GetAccounts(gomock.Any(), int64(123)).Return([]types.Account{
+		{ID: 1, Name: "Карта", Currency: types.RUB},
+		{ID: 2, Name: "Доллары", Currency: types.USD},
+	}, nil)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.USD, gomock.Any()).Return(types.RateFromFloat(90), nil)
+	deps.accountsDB.EXPECT().CreateTransfer(gomock.Any(), int64(123), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID int64, transfer types.Transfer) error {
+			assert.Equal(t, 1, transfer.FromAccountID)
+			assert.Equal(t, 2, transfer.ToAccountID)
+			assert.Equal(t, 900000, transfer.FromSum)
+			assert.Equal(t, 10000, transfer.ToSum)
+			return nil
+		})
+	deps.sender.EXPECT().SendMessage(gomock.Any(), int64(123))
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/transfer 9000 карта доллары",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnIncomeCommand_ShouldMarkCardWithoutWritingIncome(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(123)).Return(types.RUB, nil)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil)
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(123)).Return(456, nil)
+	deps.expensesDB.EXPECT().MarkIncomeCard(gomock.Any(), int64(123), 456).Return(nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/income",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnIncomeSumInGroup_ShouldWriteIncomeOfMember(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 123, Name: "Анна"}).Return(nil)
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(-100)).Return(&types.UserStateType{
+		CurrentState: types.CurrentState{ExpenseID: 456, State: types.EditingSum, MemberID: 123},
+	}, true)
+	deps.expensesDB.EXPECT().GetExpense(gomock.Any(), int64(-100), 456).Return(nil, nil)
+	deps.expensesDB.EXPECT().IsIncomeCard(gomock.Any(), int64(-100), 456).Return(true, nil)
+	deps.expensesDB.EXPECT().WriteExpense(gomock.Any(), int64(-100), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID int64, expense *types.Expense) error {
+			assert.Equal(t, 456, expense.ExpenseID)
+			assert.Equal(t, types.KindIncome, expense.Kind)
+			assert.Equal(t, int64(123), expense.PaidBy)
+			return nil
+		})
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(-100)).Return(types.RUB, nil).Times(2)
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).Times(2)
+	deps.expensesDB.EXPECT().WriteSum(gomock.Any(), 5000000, 5000000, types.RUB, int64(-100), 456).Return(nil)
+	deps.sender.EXPECT().DeleteMessage(int64(-100), 789).Return(nil)
+	deps.usersDB.EXPECT().ToWaitState(gomock.Any(), int64(-100)).Return(nil)
+	deps.sender.EXPECT().EditExpenseMessage(gomock.Any(), int64(-100), 456).Return(nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:       "50000",
+		UserID:     -100,
+		MessageID:  789,
+		MemberID:   123,
+		MemberName: "Анна",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnSumInGroup_ShouldNotEditCardOfAnotherMember(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	// Анна has pressed "Изменить сумму", Борис writes a number: it is not the sum of her card.
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 321, Name: "Борис"}).Return(nil)
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(-100)).Return(&types.UserStateType{
+		CurrentState: types.CurrentState{ExpenseID: 456, State: types.EditingSum, MemberID: 123},
+	}, true)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:       "300",
+		UserID:     -100,
+		MessageID:  789,
+		MemberID:   321,
+		MemberName: "Борис",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnTextInGroup_ShouldNotWriteExpense(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	// The members talk to each other, "300 кафе" is not an expense for the bot.
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 321, Name: "Борис"}).Return(nil)
+	deps.usersDB.EXPECT().GetCurrentState(gomock.Any(), int64(-100)).Return(nil, false)
+
+	err := model.IncomingMessage(context.Background(), &Message{
+		Text:       "300 кафе",
+		UserID:     -100,
+		MemberID:   321,
+		MemberName: "Борис",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnCommandToBotInGroup_ShouldAnswer(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 321, Name: "Борис"}).Return(nil)
+	deps.sender.EXPECT().SendMessage(groupStartMsg+"\n\n"+splitHelpMsg, int64(-100))
+
+	err := model.IncomingMessage(context.Background(), &Message{
+		Text:       "/start@MyBot",
+		UserID:     -100,
+		MemberID:   321,
+		MemberName: "Борис",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_SplitCommand(t *testing.T) {
+	command, args := splitCommand("/report@MyBot 2026-10")
+	assert.Equal(t, "/report", command)
+	assert.Equal(t, "2026-10", args)
+
+	command, args = splitCommand("300 кафе user@mail")
+	assert.Equal(t, "300 кафе user@mail", command)
+	assert.Equal(t, "", args)
+}
+
+func Test_OnSplitCommand_ShouldWriteShares(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	members := []types.Member{{ID: 1, Name: "Анна"}, {ID: 2, Name: "Борис"}}
+
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 1, Name: "Анна"}).Return(nil)
+	deps.groupsDB.EXPECT().GetMembers(gomock.Any(), int64(-100)).Return(members, nil)
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any()).Return(nil, nil)
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(-100)).Return(types.RUB, nil).AnyTimes()
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).AnyTimes()
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(-100)).Return(types.DefaultCategories, nil).Times(2)
+	deps.txManager.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	deps.categoriesDB.EXPECT().AddCategory(gomock.Any(), int64(-100), "Ресторан").Return(nil)
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(-100)).Return(456, nil)
+	deps.expensesDB.EXPECT().WriteExpense(gomock.Any(), int64(-100), gomock.Any()).Return(nil)
+	deps.splitsDB.EXPECT().WriteSplits(gomock.Any(), int64(-100), 456, []types.SplitShare{
+		{MemberID: 1, Sum: 100000},
+		{MemberID: 2, Sum: 200000},
+	}).Return(nil)
+	deps.sender.EXPECT().SendMessage("Разделено:\nАнна: 1000.00 RUB\nБорис: 2000.00 RUB\n", int64(-100)).Return(nil)
+	deps.limitsDB.EXPECT().GetLimit(gomock.Any(), int64(-100), gomock.Any()).Return(0, false, nil)
+	deps.config.EXPECT().GetDefaultLimit().Return(1000000)
+	deps.expensesDB.EXPECT().GetMonthReport(gomock.Any(), int64(-100), gomock.Any()).Return(300000, nil)
+	deps.budgetsDB.EXPECT().GetBudget(gomock.Any(), int64(-100), "Ресторан", gomock.Any()).Return(0, false, nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:       "/split 3000 ресторан: я Борис*2",
+		UserID:     -100,
+		MemberID:   1,
+		MemberName: "Анна",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnSplitCommand_ShouldDeleteCardIfNotWritten(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	members := []types.Member{{ID: 1, Name: "Анна"}, {ID: 2, Name: "Борис"}}
+
+	deps.groupsDB.EXPECT().AddMember(gomock.Any(), int64(-100), types.Member{ID: 1, Name: "Анна"}).Return(nil)
+	deps.groupsDB.EXPECT().GetMembers(gomock.Any(), int64(-100)).Return(members, nil)
+	deps.ratesDB.EXPECT().GetCurrencies(gomock.Any()).Return(nil, nil)
+	deps.usersDB.EXPECT().GetUserCurrency(gomock.Any(), int64(-100)).Return(types.RUB, nil).AnyTimes()
+	deps.ratesDB.EXPECT().GetCurrencyRate(gomock.Any(), types.RUB, gomock.Any()).Return(types.UnitRate, nil).AnyTimes()
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(-100)).Return(types.DefaultCategories, nil)
+	deps.categoriesDB.EXPECT().AddCategory(gomock.Any(), int64(-100), "Ресторан").Return(nil)
+	deps.sender.EXPECT().CreateExpense(gomock.Any(), int64(-100)).Return(456, nil)
+	deps.txManager.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	deps.expensesDB.EXPECT().WriteExpense(gomock.Any(), int64(-100), gomock.Any()).Return(nil)
+	deps.splitsDB.EXPECT().WriteSplits(gomock.Any(), int64(-100), 456, gomock.Any()).Return(errors.New("connection lost"))
+	deps.sender.EXPECT().DeleteMessage(int64(-100), 456).Return(nil)
+
+	err := model.IncomingMessage(context.Background(), &Message{
+		Text:       "/split 3000 ресторан: я Борис*2",
+		UserID:     -100,
+		MemberID:   1,
+		MemberName: "Анна",
+	})
+
+	assert.Error(t, err)
+}
+
+func Test_OnCategoriesMerge_ShouldMoveExpensesAndInvalidateReports(t *testing.T) {
+	model, deps := newTestModel(t)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	deps.categoriesDB.EXPECT().GetCategories(gomock.Any(), int64(123)).Return(types.DefaultCategories, nil)
+	deps.txManager.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	deps.categoriesDB.EXPECT().MergeCategory(gomock.Any(), int64(123), "Рестораны", "Кафе").Return(true, nil)
+	deps.expensesDB.EXPECT().InvalidateReports(gomock.Any(), int64(123)).Return(nil)
+	deps.sender.EXPECT().SendMessage("Категория «Рестораны» объединена с «Кафе»", int64(123)).Return(nil)
+
+	err := model.IncomingMessage(ctx, &Message{
+		Text:   "/categories merge  рестораны = кафе",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,224 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	recurringHelpMsg = "Регулярные траты:\n" +
+		"/recurring - список\n" +
+		"/recurring add 500 интернет monthly 15 - добавить (daily, weekly, monthly, yearly и день месяца)\n" +
+		"/recurring pause 1, /recurring resume 1, /recurring delete 1 - управление по номеру"
+	recurringNotFoundMsg = "Регулярная трата не найдена"
+	recurringEmptyMsg    = "Регулярных трат нет"
+)
+
+var periods = map[string]types.Period{
+	"daily":       types.Daily,
+	"ежедневно":   types.Daily,
+	"weekly":      types.Weekly,
+	"еженедельно": types.Weekly,
+	"monthly":     types.Monthly,
+	"ежемесячно":  types.Monthly,
+	"yearly":      types.Yearly,
+	"ежегодно":    types.Yearly,
+}
+
+var periodNames = map[types.Period]string{
+	types.Daily:   "каждый день",
+	types.Weekly:  "каждую неделю",
+	types.Monthly: "каждый месяц",
+	types.Yearly:  "каждый год",
+}
+
+func (s *Model) recurringCommand(ctx context.Context, msg *Message, args string) error {
+	action, args, _ := strings.Cut(args, " ")
+
+	switch action {
+	case "", "list":
+		return s.listRecurringExpenses(ctx, msg)
+	case "add":
+		return s.addRecurringExpense(ctx, msg, args)
+	case "pause", "resume", "delete":
+		id, err := strconv.Atoi(strings.TrimSpace(args))
+		if err != nil {
+			return s.tgClient.SendMessage(recurringHelpMsg, msg.UserID)
+		}
+
+		return s.changeRecurringExpense(ctx, msg, action, id)
+	}
+
+	return s.tgClient.SendMessage(recurringHelpMsg, msg.UserID)
+}
+
+// addRecurringExpense parses "<amount> <category...> <period> [day of month]".
+func (s *Model) addRecurringExpense(ctx context.Context, msg *Message, args string) error {
+	words := strings.Fields(args)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	recurring := &types.RecurringExpense{
+		DayOfMonth: today.Day(),
+	}
+
+	if len(words) > 0 {
+		if day, err := strconv.Atoi(words[len(words)-1]); err == nil && day >= 1 && day <= 31 {
+			recurring.DayOfMonth = day
+			words = words[:len(words)-1]
+		}
+	}
+
+	if len(words) < 3 {
+		return s.tgClient.SendMessage(recurringHelpMsg, msg.UserID)
+	}
+
+	period, ok := periods[strings.ToLower(words[len(words)-1])]
+	if !ok {
+		return s.tgClient.SendMessage(recurringHelpMsg, msg.UserID)
+	}
+	recurring.Period = period
+
+	amount, err := parseAmount(words[0])
+	if err != nil {
+		return s.tgClient.SendMessage(recurringHelpMsg, msg.UserID)
+	}
+
+	recurring.Category, err = s.resolveCategory(ctx, msg.UserID, strings.Join(words[1:len(words)-1], " "))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot resolveCategory")
+	}
+
+	recurring.NextDate = recurring.First(today)
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	recurring.Sum = userModel.CurrencyRate.ToKopecks(int(math.Round(amount * kopecksInRouble)))
+
+	id, err := s.recurringDB.CreateRecurringExpense(ctx, msg.UserID, recurring)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateRecurringExpense")
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Регулярная трата №%d добавлена, ближайшая дата: %s",
+		id, recurring.NextDate.Format("2006-01-02")), msg.UserID)
+}
+
+func (s *Model) listRecurringExpenses(ctx context.Context, msg *Message) error {
+	recurringExpenses, err := s.recurringDB.GetRecurringExpenses(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetRecurringExpenses")
+	}
+
+	if len(recurringExpenses) == 0 {
+		return s.tgClient.SendMessage(recurringEmptyMsg+"\n\n"+recurringHelpMsg, msg.UserID)
+	}
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	result := "Регулярные траты:\n\n"
+	for _, recurring := range recurringExpenses {
+		status := "следующая " + recurring.NextDate.Format("2006-01-02")
+		if recurring.Paused {
+			status = "приостановлена"
+		}
+
+		result += fmt.Sprintf("%d. %s: %.2f %s, %s, %s\n",
+			recurring.ID,
+			recurring.Category,
+			userModel.CurrencyRate.FromKopecks(recurring.Sum),
+			userModel.Currency,
+			periodNames[recurring.Period],
+			status,
+		)
+	}
+
+	return s.tgClient.SendMessage(result, msg.UserID)
+}
+
+func (s *Model) changeRecurringExpense(ctx context.Context, msg *Message, action string, id int) error {
+	var (
+		ok  bool
+		err error
+	)
+
+	switch action {
+	case "pause":
+		ok, err = s.recurringDB.SetRecurringExpensePaused(ctx, msg.UserID, id, true)
+	case "resume":
+		ok, err = s.resumeRecurringExpense(ctx, msg.UserID, id, time.Now())
+	case "delete":
+		ok, err = s.recurringDB.DeleteRecurringExpense(ctx, msg.UserID, id)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "cannot change recurring expense")
+	}
+
+	if !ok {
+		return s.tgClient.SendMessage(recurringNotFoundMsg, msg.UserID)
+	}
+
+	return s.tgClient.SendMessage("Готово", msg.UserID)
+}
+
+// resumeRecurringExpense moves the paused template to its first occurrence on or after today,
+// so nothing is written for the time it was paused. A template which is not paused is left as is.
+func (s *Model) resumeRecurringExpense(ctx context.Context, userID int64, id int, now time.Time) (bool, error) {
+	recurringExpenses, err := s.recurringDB.GetRecurringExpenses(ctx, userID)
+
+	if err != nil {
+		return false, errors.Wrap(err, "cannot GetRecurringExpenses")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, recurring := range recurringExpenses {
+		if recurring.ID != id {
+			continue
+		}
+
+		if !recurring.Paused {
+			return true, nil
+		}
+
+		return s.recurringDB.ResumeRecurringExpense(ctx, userID, id, recurring.Resumed(today))
+	}
+
+	return false, nil
+}
+
+// NotifyRecurringExpense tells the user that the recurring expense was written.
+func (s *Model) NotifyRecurringExpense(ctx context.Context, userID int64, expense *types.Expense) error {
+	userModel, err := s.getUserModel(ctx, userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	err = s.tgClient.SendMessage("Добавлена регулярная трата\n\n"+expense.ToString(userModel), userID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SendMessage")
+	}
+
+	return s.checkLimits(ctx, userID, expense, nil)
+}
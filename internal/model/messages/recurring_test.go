@@ -0,0 +1,42 @@
+package messages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_ResumeRecurringExpense_ShouldSkipOccurrencesMissedWhilePaused(t *testing.T) {
+	model, deps := newTestModel(t)
+	ctx := context.Background()
+
+	rent := types.RecurringExpense{
+		ID:         1,
+		UserID:     123,
+		Sum:        50000,
+		Category:   "аренда",
+		Period:     types.Monthly,
+		DayOfMonth: 5,
+		NextDate:   time.Date(2026, 8, 5, 0, 0, 0, 0, time.UTC),
+	}
+
+	deps.recurringDB.EXPECT().SetRecurringExpensePaused(gomock.Any(), int64(123), 1, true).Return(true, nil)
+	deps.sender.EXPECT().SendMessage("Готово", int64(123))
+
+	err := model.IncomingMessage(ctx, &Message{Text: "/recurring pause 1", UserID: 123})
+	assert.NoError(t, err)
+
+	// Two months later the August to October rent must not be written on resume.
+	rent.Paused = true
+	deps.recurringDB.EXPECT().GetRecurringExpenses(gomock.Any(), int64(123)).Return([]types.RecurringExpense{rent}, nil)
+	deps.recurringDB.EXPECT().ResumeRecurringExpense(gomock.Any(), int64(123), 1,
+		time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC)).Return(true, nil)
+
+	ok, err := model.resumeRecurringExpense(ctx, 123, 1, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,350 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+const (
+	splitHelpMsg = "Общие траты группы:\n" +
+		"/split 3000 ресторан - поровну на всех участников группы\n" +
+		"/split 3000 ресторан вчера: Анна Борис - поровну на перечисленных, «я» - это вы\n" +
+		"/split 3000 ресторан: Анна*2 Борис - по долям\n" +
+		"/split 3000 ресторан: Анна=1000 Борис Вера - точные суммы, остаток делится на остальных\n" +
+		"/debts - кто кому сколько должен\n" +
+		"/debts pay Анна 500 - вы вернули долг участнику"
+	splitGroupOnlyMsg = "Делить траты можно только в группе"
+	memberNotFoundMsg = "Участник не найден: "
+)
+
+var (
+	errSplitExceeds  = errors.New("exact amounts exceed the sum")
+	errSplitMismatch = errors.New("exact amounts do not match the sum")
+)
+
+// splitPart is one participant of the split as entered: "Анна", "Анна*2" or "Анна=1000".
+type splitPart struct {
+	Name   string
+	Weight int
+	Exact  int // In hundredths, used if Weight is zero.
+}
+
+// parseSplitParts parses the participants, the names are checked later against the members.
+func parseSplitParts(text string) ([]splitPart, error) {
+	var parts []splitPart
+
+	for _, token := range strings.Fields(text) {
+		if name, value, ok := strings.Cut(token, "="); ok {
+			amount, err := parseAmount(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot parseAmount")
+			}
+
+			parts = append(parts, splitPart{Name: name, Exact: int(math.Round(amount * kopecksInRouble))})
+			continue
+		}
+
+		part := splitPart{Name: token, Weight: 1}
+
+		if name, value, ok := strings.Cut(token, "*"); ok {
+			weight, err := strconv.Atoi(value)
+			if err != nil || weight <= 0 {
+				return nil, errors.New("share is incorrect")
+			}
+
+			part = splitPart{Name: name, Weight: weight}
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// splitSums divides the total in hundredths: the exact amounts are taken as is
+// and the rest is divided by the shares of the others.
+func splitSums(total int, parts []splitPart) ([]int, error) {
+	rest := total
+	weights := make([]int, len(parts))
+	weighted := false
+
+	for i, part := range parts {
+		if part.Weight == 0 {
+			rest -= part.Exact
+		} else {
+			weights[i] = part.Weight
+			weighted = true
+		}
+	}
+
+	if rest < 0 {
+		return nil, errSplitExceeds
+	}
+
+	if !weighted && rest != 0 {
+		return nil, errSplitMismatch
+	}
+
+	sums := types.Allocate(rest, weights)
+	for i, part := range parts {
+		if part.Weight == 0 {
+			sums[i] = part.Exact
+		}
+	}
+
+	return sums, nil
+}
+
+// findMember finds the member by the name, "я" is the author of the message.
+func findMember(members []types.Member, name string, msg *Message) (types.Member, bool) {
+	if strings.EqualFold(name, "я") {
+		return types.Member{ID: msg.MemberID, Name: msg.MemberName}, true
+	}
+
+	for _, member := range members {
+		if strings.EqualFold(member.Name, name) {
+			return member, true
+		}
+	}
+
+	return types.Member{}, false
+}
+
+func memberNames(members []types.Member) string {
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// splitCommand parses "<expense as in /add>[: participants]", the expense is paid by the author.
+func (s *Model) splitCommand(ctx context.Context, msg *Message, args string) error {
+	if !types.IsGroupChat(msg.UserID) {
+		return s.tgClient.SendMessage(splitGroupOnlyMsg, msg.UserID)
+	}
+
+	expenseText, partsText, _ := strings.Cut(args, ":")
+
+	currencies := s.getCurrencies(ctx)
+
+	parsed, err := parseExpense(expenseText, time.Now(), currencies)
+	if err != nil {
+		return s.tgClient.SendMessage(splitHelpMsg, msg.UserID)
+	}
+
+	parts, err := parseSplitParts(partsText)
+	if err != nil {
+		return s.tgClient.SendMessage(splitHelpMsg, msg.UserID)
+	}
+
+	members, err := s.groupsDB.GetMembers(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetMembers")
+	}
+
+	// Without the participants the expense is shared by everyone the bot has seen in the group.
+	if len(parts) == 0 {
+		for _, member := range members {
+			parts = append(parts, splitPart{Name: member.Name, Weight: 1})
+		}
+	}
+
+	participants := make([]types.Member, 0, len(parts))
+	seen := make(map[int64]bool, len(parts))
+
+	for _, part := range parts {
+		member, ok := findMember(members, part.Name, msg)
+		if !ok {
+			return s.tgClient.SendMessage(memberNotFoundMsg+part.Name+"\nУчастники: "+memberNames(members), msg.UserID)
+		}
+
+		if seen[member.ID] {
+			return s.tgClient.SendMessage("Участник указан дважды: "+part.Name, msg.UserID)
+		}
+		seen[member.ID] = true
+
+		participants = append(participants, member)
+	}
+
+	originalSums, err := splitSums(int(math.Round(parsed.Amount*kopecksInRouble)), parts)
+
+	switch {
+	case errors.Is(err, errSplitExceeds):
+		return s.tgClient.SendMessage("Точные суммы больше суммы траты", msg.UserID)
+	case errors.Is(err, errSplitMismatch):
+		return s.tgClient.SendMessage("Точные суммы не сходятся с суммой траты", msg.UserID)
+	case err != nil:
+		return errors.Wrap(err, "cannot splitSums")
+	}
+
+	// Telegram is not a part of the transaction, so the card is sent first and removed if nothing is written.
+	expense, err := s.sendExpenseCard(ctx, msg, parsed)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot sendExpenseCard")
+	}
+
+	// An expense without its shares would not be in the debts.
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.expensesDB.WriteExpense(ctx, msg.UserID, expense)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot WriteExpense")
+		}
+
+		// The shares in kopecks follow the entered ones and add up to the expense exactly.
+		sums := types.Allocate(expense.Sum, originalSums)
+		shares := make([]types.SplitShare, 0, len(participants))
+
+		for i, member := range participants {
+			shares = append(shares, types.SplitShare{MemberID: member.ID, Sum: sums[i]})
+		}
+
+		return errors.Wrap(s.splitsDB.WriteSplits(ctx, msg.UserID, expense.ExpenseID, shares), "cannot WriteSplits")
+	})
+
+	if err != nil {
+		if deleteErr := s.tgClient.DeleteMessage(msg.UserID, expense.ExpenseID); deleteErr != nil {
+			log.Println(errors.Wrap(deleteErr, "cannot DeleteMessage"))
+		}
+
+		return errors.Wrap(err, "cannot RunInTx")
+	}
+
+	result := "Разделено:\n"
+	for i, member := range participants {
+		result += fmt.Sprintf("%s: %.2f %s\n", member.Name, float64(originalSums[i])/100, expense.OriginalCurrency)
+	}
+
+	err = s.tgClient.SendMessage(result, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot SendMessage")
+	}
+
+	return s.checkLimits(ctx, msg.UserID, expense, nil)
+}
+
+func (s *Model) debtsCommand(ctx context.Context, msg *Message, args string) error {
+	if !types.IsGroupChat(msg.UserID) {
+		return s.tgClient.SendMessage(splitGroupOnlyMsg, msg.UserID)
+	}
+
+	action, args, _ := strings.Cut(args, " ")
+
+	switch action {
+	case "":
+		return s.showDebts(ctx, msg)
+	case "pay":
+		return s.payDebt(ctx, msg, args)
+	}
+
+	return s.tgClient.SendMessage(splitHelpMsg, msg.UserID)
+}
+
+func (s *Model) showDebts(ctx context.Context, msg *Message) error {
+	balances, err := s.splitsDB.GetBalances(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetBalances")
+	}
+
+	debts := types.SettleUp(balances)
+	if len(debts) == 0 {
+		return s.tgClient.SendMessage("Долгов нет", msg.UserID)
+	}
+
+	members, err := s.groupsDB.GetMembers(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetMembers")
+	}
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	return s.tgClient.SendMessage(debtsMessage(debts, members, userModel), msg.UserID)
+}
+
+// debtsMessage lists the transfers that settle the group up.
+func debtsMessage(debts []types.Debt, members []types.Member, userModel *types.UserModel) string {
+	names := make(map[int64]string, len(members))
+	for _, member := range members {
+		names[member.ID] = member.Name
+	}
+
+	name := func(id int64) string {
+		if name, ok := names[id]; ok && name != "" {
+			return name
+		}
+
+		return fmt.Sprintf("Участник %d", id)
+	}
+
+	result := "Чтобы рассчитаться:\n\n"
+	for _, debt := range debts {
+		result += fmt.Sprintf("%s → %s: %.2f %s\n",
+			name(debt.From), name(debt.To), userModel.CurrencyRate.FromKopecks(debt.Sum), userModel.Currency)
+	}
+
+	return result
+}
+
+// payDebt parses "<member> <amount>": the author gave the amount in the chat currency to the member.
+func (s *Model) payDebt(ctx context.Context, msg *Message, args string) error {
+	words := strings.Fields(args)
+	if len(words) != 2 {
+		return s.tgClient.SendMessage(splitHelpMsg, msg.UserID)
+	}
+
+	amount, err := parseAmount(words[1])
+	if err != nil {
+		return s.tgClient.SendMessage(splitHelpMsg, msg.UserID)
+	}
+
+	members, err := s.groupsDB.GetMembers(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetMembers")
+	}
+
+	member, ok := findMember(members, words[0], msg)
+	if !ok || member.ID == msg.MemberID {
+		return s.tgClient.SendMessage(memberNotFoundMsg+words[0]+"\nУчастники: "+memberNames(members), msg.UserID)
+	}
+
+	userModel, err := s.getUserModel(ctx, msg.UserID)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot getUserModel")
+	}
+
+	debt := types.Debt{
+		From: msg.MemberID,
+		To:   member.ID,
+		Sum:  userModel.CurrencyRate.ToKopecks(int(math.Round(amount * kopecksInRouble))),
+	}
+
+	err = s.splitsDB.CreateSettlement(ctx, msg.UserID, debt, getDay(time.Now()))
+
+	if err != nil {
+		return errors.Wrap(err, "cannot CreateSettlement")
+	}
+
+	return s.tgClient.SendMessage(fmt.Sprintf("Записано: %s → %s: %.2f %s",
+		msg.MemberName, member.Name, amount, userModel.Currency), msg.UserID)
+}
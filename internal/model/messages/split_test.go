@@ -0,0 +1,40 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+func Test_SplitSums(t *testing.T) {
+	parts, err := parseSplitParts("Анна=1000 Борис*2 Вера")
+	assert.NoError(t, err)
+	assert.Equal(t, []splitPart{
+		{Name: "Анна", Exact: 100000},
+		{Name: "Борис", Weight: 2},
+		{Name: "Вера", Weight: 1},
+	}, parts)
+
+	sums, err := splitSums(300000, parts)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{100000, 133333, 66667}, sums)
+
+	_, err = splitSums(50000, parts)
+	assert.ErrorIs(t, err, errSplitExceeds)
+
+	_, err = splitSums(300000, []splitPart{{Name: "Анна", Exact: 100000}})
+	assert.ErrorIs(t, err, errSplitMismatch)
+
+	_, err = parseSplitParts("Анна*0")
+	assert.Error(t, err)
+}
+
+func Test_DebtsMessage(t *testing.T) {
+	debts := []types.Debt{{From: 2, To: 1, Sum: 150000}, {From: 3, To: 1, Sum: 5000}}
+	members := []types.Member{{ID: 1, Name: "Анна"}, {ID: 2, Name: "Борис"}}
+
+	result := debtsMessage(debts, members, &types.UserModel{Currency: "RUB", CurrencyRate: types.UnitRate})
+
+	assert.Equal(t, "Чтобы рассчитаться:\n\nБорис → Анна: 1500.00 RUB\nУчастник 3 → Анна: 50.00 RUB\n", result)
+}
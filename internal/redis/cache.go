@@ -2,9 +2,11 @@ package redis
 
 import (
 	"log"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
 )
 
@@ -14,9 +16,15 @@ type Cache struct {
 
 func New(config *config.Service) (*Cache, error) {
 	cache := &Cache{
-		pool: newPool(config),
+		pool: newPool(config.GetRedis()),
 	}
-	err := cache.Ping()
+
+	err := prometheus.Register(&poolCollector{pool: cache.pool})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot prometheus.Register")
+	}
+
+	err = cache.Ping()
 
 	return cache, err
 }
@@ -44,11 +52,17 @@ func (c *Cache) Get(key string) ([]byte, error) {
 	return data, err
 }
 
-func (c *Cache) Set(key string, value []byte) error {
+// Set stores the value for the ttl, a zero ttl keeps it until it is deleted.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) error {
 	conn := c.pool.Get()
 	defer conn.Close()
 
-	_, err := conn.Do("SET", key, value)
+	args := []interface{}{key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+
+	_, err := conn.Do("SET", args...)
 	if err != nil {
 		return errors.Wrap(err, "cannot conn.Do")
 	}
@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolActiveDesc = prometheus.NewDesc("ozon_redis_pool_active_connections",
+		"Connections of the pool, both in use and idle.", nil, nil)
+	poolIdleDesc = prometheus.NewDesc("ozon_redis_pool_idle_connections",
+		"Idle connections of the pool.", nil, nil)
+	poolWaitCountDesc = prometheus.NewDesc("ozon_redis_pool_wait_total",
+		"Times a connection was waited for because the pool was at max active.", nil, nil)
+	poolWaitDurationDesc = prometheus.NewDesc("ozon_redis_pool_wait_seconds_total",
+		"Time spent waiting for a connection.", nil, nil)
+)
+
+// poolCollector exports the stats of the pool at the moment of the scrape.
+type poolCollector struct {
+	pool *redis.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolActiveDesc
+	ch <- poolIdleDesc
+	ch <- poolWaitCountDesc
+	ch <- poolWaitDurationDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(poolActiveDesc, prometheus.GaugeValue, float64(stats.ActiveCount))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stats.IdleCount))
+	ch <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
+)
+
+func TestPoolCollector_ShouldExportPoolStats(t *testing.T) {
+	pool := newPool(config.RedisConfig{Address: "localhost:6379", MaxIdle: 3})
+	defer pool.Close()
+
+	expected := `
+		# HELP ozon_redis_pool_active_connections Connections of the pool, both in use and idle.
+		# TYPE ozon_redis_pool_active_connections gauge
+		ozon_redis_pool_active_connections 0
+		# HELP ozon_redis_pool_idle_connections Idle connections of the pool.
+		# TYPE ozon_redis_pool_idle_connections gauge
+		ozon_redis_pool_idle_connections 0
+	`
+
+	err := testutil.CollectAndCompare(&poolCollector{pool: pool}, strings.NewReader(expected),
+		"ozon_redis_pool_active_connections", "ozon_redis_pool_idle_connections")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, testutil.CollectAndCount(&poolCollector{pool: pool}))
+}
@@ -1,7 +1,7 @@
 package redis
 
 import (
-	"fmt"
+	"crypto/tls"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
@@ -9,14 +9,40 @@ import (
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/config"
 )
 
-func newPool(config *config.Service) *redis.Pool {
+func newPool(config config.RedisConfig) *redis.Pool {
+	options := []redis.DialOption{
+		redis.DialPassword(config.Password),
+		redis.DialDatabase(config.DB),
+		redis.DialConnectTimeout(seconds(config.DialTimeout)),
+		redis.DialReadTimeout(seconds(config.ReadTimeout)),
+		redis.DialWriteTimeout(seconds(config.WriteTimeout)),
+	}
+
+	if config.TLS {
+		options = append(options,
+			redis.DialUseTLS(true),
+			redis.DialTLSSkipVerify(config.TLSSkipVerify),
+		)
+
+		// Redigo ignores DialTLSSkipVerify when the TLS config is given, so it is set in the config.
+		if config.TLSServerName != "" {
+			options = append(options, redis.DialTLSConfig(&tls.Config{
+				ServerName:         config.TLSServerName,
+				MinVersion:         tls.VersionTLS12,
+				InsecureSkipVerify: config.TLSSkipVerify,
+			}))
+		}
+	}
+
 	return &redis.Pool{
-		MaxIdle:     3,
-		IdleTimeout: 240 * time.Second,
+		MaxIdle:     config.MaxIdle,
+		MaxActive:   config.MaxActive,
+		IdleTimeout: seconds(config.IdleTimeout),
+		// Wait for a free connection instead of failing when MaxActive are in use.
+		Wait: config.MaxActive > 0,
 
 		Dial: func() (redis.Conn, error) {
-			address := fmt.Sprintf("%s:%s", config.GetHost(), "6379")
-			c, err := redis.Dial("tcp", address)
+			c, err := redis.Dial("tcp", config.Address, options...)
 			if err != nil {
 				return nil, errors.Wrap(err, "cannot redis.Dial")
 			}
@@ -29,3 +55,7 @@ func newPool(config *config.Service) *redis.Pool {
 		},
 	}
 }
+
+func seconds(value int) time.Duration {
+	return time.Duration(value) * time.Second
+}
@@ -0,0 +1,173 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	NegativeSign = "negative" // Expenses are negative amounts, the rest is skipped.
+	PositiveSign = "positive" // Expenses are positive amounts, the rest is skipped.
+	AnySign      = "any"      // Every row is an expense.
+)
+
+// Profile maps columns of a bank statement, column numbers start from zero, -1 means no column.
+type Profile struct {
+	Delimiter         string `json:"delimiter"`
+	DateColumn        int    `json:"date_column"`
+	AmountColumn      int    `json:"amount_column"`
+	DescriptionColumn int    `json:"description_column"`
+	CurrencyColumn    int    `json:"currency_column"`
+	DateFormat        string `json:"date_format"`
+	SkipHeader        bool   `json:"skip_header"`
+	Encoding          string `json:"encoding"`
+	ExpenseSign       string `json:"expense_sign"`
+}
+
+func DefaultProfile() Profile {
+	return Profile{
+		Delimiter:         ",",
+		DateColumn:        0,
+		AmountColumn:      1,
+		DescriptionColumn: 2,
+		CurrencyColumn:    -1,
+		DateFormat:        "2006-01-02",
+		SkipHeader:        true,
+		Encoding:          "utf-8",
+		ExpenseSign:       NegativeSign,
+	}
+}
+
+// Row is an expense found in the statement.
+type Row struct {
+	Date        time.Time
+	Amount      float64 // Always positive.
+	Description string
+	Currency    string // Empty if the profile has no currency column.
+}
+
+// Parse reads expenses from the statement, rows which are not expenses by the profile are skipped.
+func Parse(data []byte, profile Profile) ([]Row, error) {
+	reader, err := charset.NewReaderLabel(profile.Encoding, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot NewReaderLabel")
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	if delimiter := []rune(profile.Delimiter); len(delimiter) == 1 {
+		csvReader.Comma = delimiter[0]
+	}
+
+	var rows []Row
+
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot Read")
+		}
+
+		if line == 1 && profile.SkipHeader {
+			continue
+		}
+
+		row, ok, err := parseRecord(record, profile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+func parseRecord(record []string, profile Profile) (Row, bool, error) {
+	get := func(column int) string {
+		if column < 0 || column >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[column])
+	}
+
+	date, err := time.Parse(profile.DateFormat, get(profile.DateColumn))
+	if err != nil {
+		return Row{}, false, errors.Wrap(err, "cannot Parse date")
+	}
+
+	amount, err := ParseAmount(get(profile.AmountColumn))
+	if err != nil {
+		return Row{}, false, errors.Wrap(err, "cannot ParseAmount")
+	}
+
+	switch {
+	case amount == 0:
+		return Row{}, false, nil
+	case profile.ExpenseSign == NegativeSign && amount > 0:
+		return Row{}, false, nil
+	case profile.ExpenseSign == PositiveSign && amount < 0:
+		return Row{}, false, nil
+	}
+
+	return Row{
+		Date:        date,
+		Amount:      math.Abs(amount),
+		Description: get(profile.DescriptionColumn),
+		Currency:    strings.ToUpper(get(profile.CurrencyColumn)),
+	}, true, nil
+}
+
+// ParseAmount accepts bank formats like "-1 234,56" or "−350.00 ₽".
+func ParseAmount(value string) (float64, error) {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r == ',':
+			return '.'
+		case r == '−':
+			return '-'
+		}
+		return -1
+	}, value)
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot ParseFloat")
+	}
+
+	return amount, nil
+}
+
+// Rule sets the category of rows whose description contains the pattern.
+type Rule struct {
+	Pattern  string
+	Category string
+}
+
+// Categorize returns the category of the first matching rule, the longest patterns are expected first.
+func Categorize(description string, rules []Rule, fallback string) string {
+	description = strings.ToLower(description)
+
+	for _, rule := range rules {
+		if strings.Contains(description, strings.ToLower(rule.Pattern)) {
+			return rule.Category
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,51 @@
+package statement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	data := []byte("Дата;Описание;Валюта;Сумма\n" +
+		"01.10.2026;Яндекс Такси;RUB;-1 234,56\n" +
+		"02.10.2026;Зарплата;RUB;100 000,00\n" +
+		"03.10.2026;\"Кафе; бар\";usd;−12.5\n")
+
+	profile := Profile{
+		Delimiter:         ";",
+		DateColumn:        0,
+		AmountColumn:      3,
+		DescriptionColumn: 1,
+		CurrencyColumn:    2,
+		DateFormat:        "02.01.2006",
+		SkipHeader:        true,
+		Encoding:          "utf-8",
+		ExpenseSign:       NegativeSign,
+	}
+
+	rows, err := Parse(data, profile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Row{
+		{time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), 1234.56, "Яндекс Такси", "RUB"},
+		{time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), 12.5, "Кафе; бар", "USD"},
+	}, rows)
+}
+
+func Test_Parse_IncorrectDate(t *testing.T) {
+	_, err := Parse([]byte("date,amount\nyesterday,-5\n"), DefaultProfile())
+	assert.Error(t, err)
+}
+
+func Test_Categorize(t *testing.T) {
+	rules := []Rule{
+		{"яндекс такси", "Транспорт"},
+		{"яндекс", "Подписки"},
+	}
+
+	assert.Equal(t, "Транспорт", Categorize("YANDEX Яндекс Такси", rules, "Другое"))
+	assert.Equal(t, "Подписки", Categorize("Яндекс Плюс", rules, "Другое"))
+	assert.Equal(t, "Другое", Categorize("Пятерочка", rules, "Другое"))
+}
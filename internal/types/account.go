@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// Account is a wallet of the user: cash, a debit or a credit card.
+type Account struct {
+	ID             int
+	Name           string
+	Currency       Currency
+	OpeningBalance int // In hundredths of the currency.
+}
+
+// Transfer moves money between accounts, the sums are in the currencies of the accounts.
+type Transfer struct {
+	FromAccountID int
+	ToAccountID   int
+	FromSum       int
+	ToSum         int
+	Date          time.Time
+}
+
+// AccountExpenses is the sum of the expenses or income of an account entered in one currency on one day.
+type AccountExpenses struct {
+	AccountID   int
+	Kind        ExpenseKind
+	Date        time.Time
+	Currency    Currency
+	OriginalSum int // In hundredths of the currency.
+	Sum         int // In kopecks.
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Category is an entry of the user's category catalogue.
+type Category struct {
+	ID       int
+	Name     string
+	Emoji    string // Empty if the user did not choose one.
+	Archived bool   // Archived categories stay in the reports but are not offered for new expenses.
+	ParentID int    // Zero for a top level category.
+}
+
+// CategoryLevel is the level the reports aggregate the expenses at.
+type CategoryLevel string
+
+const (
+	CategoryLevelLeaf   CategoryLevel = "leaf"   // The categories of the expenses as they are.
+	CategoryLevelParent CategoryLevel = "parent" // Subcategories are rolled up into their parents.
+)
+
+// CategoryPathSeparator separates the parent and the subcategory: "Транспорт > Такси".
+const CategoryPathSeparator = ">"
+
+// Title is how the category is shown on the buttons and in the lists.
+func (c Category) Title() string {
+	if c.Emoji == "" {
+		return c.Name
+	}
+
+	return c.Emoji + " " + c.Name
+}
+
+// DefaultCategories are seeded into the catalogue of a new user.
+var DefaultCategories = []Category{
+	{Name: "Продукты", Emoji: "🛒"},
+	{Name: "Кафе", Emoji: "☕"},
+	{Name: "Транспорт", Emoji: "🚌"},
+	{Name: "Дом", Emoji: "🏠"},
+	{Name: "Здоровье", Emoji: "💊"},
+	{Name: "Одежда", Emoji: "👕"},
+	{Name: "Развлечения", Emoji: "🎬"},
+	{Name: "Связь", Emoji: "📱"},
+	{Name: "Подарки", Emoji: "🎁"},
+	{Name: "Прочее", Emoji: "📦"},
+}
+
+// NormalizeCategory trims the name, collapses the spaces inside and capitalizes
+// the first letter, so "  кафе " and "Кафе" are the same category.
+func NormalizeCategory(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+
+	first, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return ""
+	}
+
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// SplitCategoryPath splits "Транспорт > Такси" into the normalized parent and subcategory,
+// the parent is empty for a plain name.
+func SplitCategoryPath(path string) (string, string) {
+	parent, name, ok := strings.Cut(path, CategoryPathSeparator)
+	if !ok {
+		return "", NormalizeCategory(path)
+	}
+
+	return NormalizeCategory(parent), NormalizeCategory(name)
+}
+
+// CategoryParents maps the names of the subcategories to the names of their parents.
+func CategoryParents(categories []Category) map[string]string {
+	names := make(map[int]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	parents := make(map[string]string)
+	for _, category := range categories {
+		if parent, ok := names[category.ParentID]; ok && category.ParentID != 0 {
+			parents[category.Name] = parent
+		}
+	}
+
+	return parents
+}
+
+// FindCategory finds the category by the name ignoring the case and the extra spaces.
+func FindCategory(categories []Category, name string) (Category, bool) {
+	name = NormalizeCategory(name)
+
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, name) {
+			return category, true
+		}
+	}
+
+	return Category{}, false
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCategory(t *testing.T) {
+	assert.Equal(t, "Кафе", NormalizeCategory("  кафе "))
+	assert.Equal(t, "Кафе у дома", NormalizeCategory("кафе   у\tдома"))
+	assert.Equal(t, "ЖКХ", NormalizeCategory("ЖКХ"))
+	assert.Equal(t, "", NormalizeCategory("   "))
+}
+
+func TestSplitCategoryPath(t *testing.T) {
+	parent, name := SplitCategoryPath("транспорт >  такси")
+	assert.Equal(t, "Транспорт", parent)
+	assert.Equal(t, "Такси", name)
+
+	parent, name = SplitCategoryPath("кафе")
+	assert.Equal(t, "", parent)
+	assert.Equal(t, "Кафе", name)
+}
+
+func TestCategoryParents(t *testing.T) {
+	categories := []Category{{ID: 1, Name: "Транспорт"}, {ID: 2, Name: "Такси", ParentID: 1}, {ID: 3, Name: "Кафе"}}
+
+	assert.Equal(t, map[string]string{"Такси": "Транспорт"}, CategoryParents(categories))
+}
+
+func TestFindCategory(t *testing.T) {
+	categories := []Category{{ID: 1, Name: "Кафе"}, {ID: 2, Name: "ЖКХ"}}
+
+	category, ok := FindCategory(categories, "жкх ")
+	assert.True(t, ok)
+	assert.Equal(t, 2, category.ID)
+
+	_, ok = FindCategory(categories, "Такси")
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// CurrencyInfo is a currency the CBR publishes rates for.
+type CurrencyInfo struct {
+	Code Currency
+	Name string
+}
+
+// Currencies are names of the known currencies by code.
+type Currencies map[Currency]string
+
+// popularCurrencies are shown first in the currency list.
+var popularCurrencies = []Currency{RUB, USD, EUR, CNY}
+
+func NewCurrencies(infos []CurrencyInfo) Currencies {
+	currencies := Currencies{RUB: "Российский рубль"}
+	for _, info := range infos {
+		currencies[info.Code] = info.Name
+	}
+
+	return currencies
+}
+
+// Parse converts user input like "usd" to one of the known currencies.
+func (c Currencies) Parse(value string) (Currency, bool) {
+	currency := Currency(strings.ToUpper(value))
+
+	if _, ok := c[currency]; !ok {
+		return "", false
+	}
+
+	return currency, true
+}
+
+// Search returns the currencies with the query in the code or the name, the popular ones first.
+func (c Currencies) Search(query string) []CurrencyInfo {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	priority := make(map[Currency]int, len(popularCurrencies))
+	for i, currency := range popularCurrencies {
+		priority[currency] = len(popularCurrencies) - i
+	}
+
+	var result []CurrencyInfo
+	for code, name := range c {
+		if strings.Contains(strings.ToLower(string(code)), query) || strings.Contains(strings.ToLower(name), query) {
+			result = append(result, CurrencyInfo{Code: code, Name: name})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if priority[result[i].Code] != priority[result[j].Code] {
+			return priority[result[i].Code] > priority[result[j].Code]
+		}
+		return result[i].Code < result[j].Code
+	})
+
+	return result
+}
+
+// CurrencyPage is a page of the currency list shown by /currency.
+type CurrencyPage struct {
+	Currencies []CurrencyInfo
+	Query      string
+	Page       int
+	PagesCount int
+}
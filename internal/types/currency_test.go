@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencies(t *testing.T) {
+	currencies := NewCurrencies([]CurrencyInfo{
+		{Code: "AMD", Name: "Армянских драмов"},
+		{Code: "USD", Name: "Доллар США"},
+		{Code: "EUR", Name: "Евро"},
+		{Code: "KZT", Name: "Казахстанских тенге"},
+	})
+
+	currency, ok := currencies.Parse("kzt")
+	assert.True(t, ok)
+	assert.Equal(t, Currency("KZT"), currency)
+
+	currency, ok = currencies.Parse("rub")
+	assert.True(t, ok)
+	assert.Equal(t, RUB, currency)
+
+	_, ok = currencies.Parse("кафе")
+	assert.False(t, ok)
+
+	assert.Equal(t, []CurrencyInfo{
+		{Code: RUB, Name: "Российский рубль"},
+		{Code: USD, Name: "Доллар США"},
+		{Code: EUR, Name: "Евро"},
+		{Code: "AMD", Name: "Армянских драмов"},
+		{Code: "KZT", Name: "Казахстанских тенге"},
+	}, currencies.Search(""))
+
+	assert.Equal(t, []CurrencyInfo{
+		{Code: "KZT", Name: "Казахстанских тенге"},
+	}, currencies.Search("тенге"))
+
+	assert.Equal(t, []CurrencyInfo{
+		{Code: USD, Name: "Доллар США"},
+	}, currencies.Search("us"))
+}
@@ -15,4 +15,10 @@ const (
 type CurrentState struct {
 	ExpenseID int
 	State     State
+	MemberID  int64 // Who is modifying, the state of a group chat is only for the member who has set it.
+}
+
+// IsOf tells if the message of the member is the input the state waits for.
+func (s CurrentState) IsOf(chatID, memberID int64) bool {
+	return !IsGroupChat(chatID) || s.MemberID == memberID
 }
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RateDecimals is the number of decimal places kept in a Rate, enough for the CBR rates
+// of currencies quoted per 10000 units.
+const RateDecimals = 8
+
+const rateScale = 100000000
+
+// Rate is the price of one unit of a currency in another one (roubles unless stated otherwise),
+// a fixed-point decimal with RateDecimals decimal places.
+type Rate int64
+
+// UnitRate is the rate of a currency in itself, e.g. of the rouble.
+const UnitRate Rate = rateScale
+
+// ParseRate parses a decimal with a comma or a dot separator, e.g. "90,5123" from the CBR or "1.0823" from the ECB.
+// Decimal places beyond RateDecimals are rounded.
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return 0, errors.New("empty rate")
+	}
+	if strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") {
+		return 0, errors.New("rate must be unsigned")
+	}
+
+	roundUp := false
+	if len(fraction) > RateDecimals {
+		roundUp = fraction[RateDecimals] >= '5'
+		fraction = fraction[:RateDecimals]
+	}
+	fraction += strings.Repeat("0", RateDecimals-len(fraction))
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	result, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot ParseInt")
+	}
+
+	if roundUp {
+		result++
+	}
+
+	return Rate(result), nil
+}
+
+// RateFromFloat converts a float price, rounding it to RateDecimals decimal places.
+func RateFromFloat(value float64) Rate {
+	return Rate(math.Round(value * rateScale))
+}
+
+func (r Rate) Float() float64 {
+	return float64(r) / rateScale
+}
+
+func (r Rate) String() string {
+	return strconv.FormatFloat(r.Float(), 'f', -1, 64)
+}
+
+// Decimal returns the rate with all its decimal places, as it is stored in the database.
+func (r Rate) Decimal() string {
+	whole, fraction := int64(r)/rateScale, int64(r)%rateScale
+	if fraction < 0 {
+		fraction = -fraction
+	}
+
+	return strconv.FormatInt(whole, 10) + "." + strconv.FormatInt(rateScale+fraction, 10)[1:]
+}
+
+// Div returns the price of one unit when the rate is quoted per n units, e.g. 100 JPY.
+func (r Rate) Div(n int) Rate {
+	return Rate(divRound(big.NewInt(int64(r)), big.NewInt(int64(n))).Int64())
+}
+
+// Mul returns the price in the currency the other rate is quoted in, used for cross rates.
+func (r Rate) Mul(other Rate) Rate {
+	product := new(big.Int).Mul(big.NewInt(int64(r)), big.NewInt(int64(other)))
+
+	return Rate(divRound(product, big.NewInt(rateScale)).Int64())
+}
+
+// Inverse returns the price of the quote currency in the currency the rate is for,
+// e.g. the price of a euro in dollars from the price of a dollar in euros.
+func (r Rate) Inverse() Rate {
+	if r == 0 {
+		return 0
+	}
+
+	return Rate(divRound(big.NewInt(rateScale*rateScale), big.NewInt(int64(r))).Int64())
+}
+
+// ToKopecks converts hundredths of the currency to kopecks.
+func (r Rate) ToKopecks(hundredths int) int {
+	product := new(big.Int).Mul(big.NewInt(int64(hundredths)), big.NewInt(int64(r)))
+
+	return int(divRound(product, big.NewInt(rateScale)).Int64())
+}
+
+// FromKopecks converts kopecks to units of the currency, e.g. for a report.
+func (r Rate) FromKopecks(kopecks int) float64 {
+	if r == 0 {
+		return 0
+	}
+
+	product := new(big.Int).Mul(big.NewInt(int64(kopecks)), big.NewInt(rateScale))
+
+	return float64(divRound(product, big.NewInt(int64(r))).Int64()) / 100
+}
+
+// divRound divides rounding half away from zero.
+func divRound(x, y *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(x, y, new(big.Int))
+
+	if new(big.Int).Abs(new(big.Int).Mul(remainder, big.NewInt(2))).Cmp(new(big.Int).Abs(y)) >= 0 {
+		if (x.Sign() < 0) != (y.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
@@ -2,15 +2,30 @@ package types
 
 import (
 	"fmt"
-	"log"
 	"time"
 )
 
+// ExpenseKind tells spending from income, both are kept as expenses.
+type ExpenseKind string
+
+const (
+	KindExpense ExpenseKind = "expense"
+	KindIncome  ExpenseKind = "income"
+)
+
 type Expense struct {
-	ExpenseID int
-	Sum       int
-	Category  string
-	Date      time.Time
+	ExpenseID        int
+	Sum              int // In kopecks at the rate of the expense date.
+	Category         string
+	Date             time.Time
+	OriginalSum      int      // As entered, in hundredths of OriginalCurrency.
+	OriginalCurrency Currency // Empty if the expense was entered in kopecks.
+	OriginalRate     Rate     // RUB for a unit of OriginalCurrency on the date, filled by the export.
+	AccountID        int      // Zero if the account is not chosen.
+	Account          string   // Name of the account, filled when the expense is read.
+	Kind             ExpenseKind
+	PaidBy           int64  // The member who paid, zero for the expenses written by the bot.
+	PaidByName       string // Filled for the expenses of group chats.
 }
 
 func NewExpense() *Expense {
@@ -18,21 +33,83 @@ func NewExpense() *Expense {
 		Sum:      0,
 		Category: "Новая категория",
 		Date:     time.Now(),
+		Kind:     KindExpense,
 	}
 }
 
+// NewIncome returns a new income entry for the card of /income.
+func NewIncome() *Expense {
+	income := NewExpense()
+	income.Kind = KindIncome
+
+	return income
+}
+
 type UserModel struct {
 	Currency     string
-	CurrencyRate int
+	CurrencyRate Rate
 }
 
 func (e *Expense) ToString(model *UserModel) string {
-	log.Println(model.CurrencyRate, float64(e.Sum)/float64(model.CurrencyRate))
-	return fmt.Sprintf(
+	// The amount entered in the user's currency is shown as is, not converted back.
+	sum := fmt.Sprintf("%.2f", model.CurrencyRate.FromKopecks(e.Sum))
+	if e.OriginalCurrency != "" && e.OriginalCurrency == Currency(model.Currency) {
+		sum = fmt.Sprintf("%.2f", float64(e.OriginalSum)/100)
+	} else if e.OriginalCurrency != "" {
+		sum += fmt.Sprintf(" (%.2f %s)", float64(e.OriginalSum)/100, e.OriginalCurrency)
+	}
+
+	result := fmt.Sprintf(
 		"Используемая валюта: "+model.Currency+"\n\n"+
-			"Сумма: %.2f\nКатегория: %s\nДата: %s",
-		float64(e.Sum)/float64(model.CurrencyRate),
+			"Сумма: %s\nКатегория: %s\nДата: %s",
+		sum,
 		e.Category,
 		e.Date.Format("2006-01-02"),
 	)
+
+	if e.Account != "" {
+		result += "\nСчет: " + e.Account
+	}
+
+	if e.PaidByName != "" {
+		result += "\nОплатил: " + e.PaidByName
+	}
+
+	if e.Kind == KindIncome {
+		result = "Доход\n" + result
+	}
+
+	return result
+}
+
+// ReportRow is the sum of a category of expenses or income for a day in one of the currencies they were entered in.
+type ReportRow struct {
+	Kind             ExpenseKind
+	Date             time.Time
+	Category         string
+	Sum              int
+	OriginalSum      int
+	OriginalCurrency Currency
+}
+
+// ReportDrillDown is the button of the report which shows the subcategories of the category for the period.
+type ReportDrillDown struct {
+	CategoryID int
+	Title      string
+	DateBegin  time.Time
+	DateEnd    time.Time
+}
+
+// ExpenseFilter selects expenses for the history. Empty fields do not filter.
+type ExpenseFilter struct {
+	Category  string // A parent category includes its subcategories.
+	DateBegin time.Time
+	DateEnd   time.Time
+}
+
+// HistoryPage is a page of the expenses list shown by /history.
+type HistoryPage struct {
+	Expenses   []Expense
+	Page       int
+	PagesCount int
 }
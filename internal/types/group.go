@@ -0,0 +1,12 @@
+package types
+
+// Member is a member of a group chat who shares its ledger.
+type Member struct {
+	ID   int64
+	Name string
+}
+
+// IsGroupChat tells a group chat from a private one, Telegram gives groups negative ids.
+func IsGroupChat(chatID int64) bool {
+	return chatID < 0
+}
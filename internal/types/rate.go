@@ -2,18 +2,38 @@ package types
 
 import "time"
 
+// MaxRateAgeDays is how long a rate stays in effect when no newer one is set,
+// long enough for the New Year holidays.
+const MaxRateAgeDays = 10
+
 type CurrencyRate struct {
 	CharCode     string
 	BaseCurrency string
-	Rate         int
+	Rate         Rate
 	Date         time.Time
 }
 
 type EncodedCurrencies struct {
 	CharCode string `xml:"CharCode"`
+	Nominal  int    `xml:"Nominal"` // Value is the price of Nominal units, e.g. 100 JPY.
+	Name     string `xml:"Name"`
 	Value    string `xml:"Value"`
 }
 
 type CurrenciesRate struct {
+	Date              string              `xml:"Date,attr"` // The date the rates are set for, DD.MM.YYYY.
 	EncodedCurrencies []EncodedCurrencies `xml:"Valute"`
 }
+
+// RateOnDate returns the rate in effect on the date from the rates by the dates they were set.
+func RateOnDate(rates map[time.Time]Rate, date time.Time) (Rate, bool) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= MaxRateAgeDays; i++ {
+		if rate, ok := rates[day.AddDate(0, 0, -i)]; ok {
+			return rate, true
+		}
+	}
+
+	return 0, false
+}
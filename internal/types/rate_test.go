@@ -0,0 +1,73 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateOnDate(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	rates := map[time.Time]Rate{
+		friday.AddDate(0, 0, -1): 9000,
+		friday:                   9100,
+	}
+
+	rate, ok := RateOnDate(rates, friday)
+	assert.True(t, ok)
+	assert.Equal(t, Rate(9100), rate)
+
+	// The weekend uses the rate of Friday.
+	rate, ok = RateOnDate(rates, time.Date(2026, 10, 18, 15, 30, 0, 0, time.Local))
+	assert.True(t, ok)
+	assert.Equal(t, Rate(9100), rate)
+
+	_, ok = RateOnDate(rates, friday.AddDate(0, 0, MaxRateAgeDays+1))
+	assert.False(t, ok)
+
+	_, ok = RateOnDate(rates, friday.AddDate(0, 0, -2))
+	assert.False(t, ok)
+}
+
+func TestParseRate(t *testing.T) {
+	for value, expected := range map[string]Rate{
+		"90,5123":      9051230000,
+		"90.5":         9050000000,
+		"90":           9000000000,
+		"0,0034":       340000,
+		",5":           50000000,
+		"1.234567895":  123456790,
+		" 12,3456789 ": 1234567890,
+	} {
+		rate, err := ParseRate(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, rate, value)
+	}
+
+	for _, value := range []string{"", ",", "abc", "-1", "1,2,3"} {
+		_, err := ParseRate(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRate_Conversions(t *testing.T) {
+	rate, err := ParseRate("60,1234")
+	assert.NoError(t, err)
+
+	// A yen is quoted per 100 units.
+	yen := rate.Div(100)
+	assert.Equal(t, "0.601234", yen.String())
+
+	// 12345.67 JPY.
+	assert.Equal(t, 742264, yen.ToKopecks(1234567))
+	assert.Equal(t, 12345.68, yen.FromKopecks(742264))
+
+	assert.Equal(t, "90.51230000", Rate(9051230000).Decimal())
+	assert.Equal(t, UnitRate, UnitRate.Mul(UnitRate))
+
+	euro, err := ParseRate("1,25")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.8", euro.Inverse().String())
+	assert.Equal(t, "80", euro.Inverse().Mul(Rate(10000000000)).String())
+}
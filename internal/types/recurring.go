@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Weekly  Period = "weekly"
+	Monthly Period = "monthly"
+	Yearly  Period = "yearly"
+)
+
+// RecurringExpense is a template of an expense which is written automatically on schedule.
+type RecurringExpense struct {
+	ID         int
+	UserID     int64
+	Sum        int
+	Category   string
+	Period     Period
+	DayOfMonth int // Used by monthly and yearly periods, the last day of the month if it is shorter.
+	NextDate   time.Time
+	Paused     bool
+}
+
+// Following returns the occurrence after the given one.
+func (r *RecurringExpense) Following(date time.Time) time.Time {
+	switch r.Period {
+	case Daily:
+		return date.AddDate(0, 0, 1)
+	case Weekly:
+		return date.AddDate(0, 0, 7)
+	case Yearly:
+		return r.dayInMonth(date.Year()+1, date.Month(), date.Location())
+	default:
+		return r.dayInMonth(date.Year(), date.Month()+1, date.Location())
+	}
+}
+
+// First returns the first occurrence starting from the date.
+func (r *RecurringExpense) First(date time.Time) time.Time {
+	switch r.Period {
+	case Monthly, Yearly:
+		first := r.dayInMonth(date.Year(), date.Month(), date.Location())
+		if first.Before(date) {
+			return r.Following(first)
+		}
+		return first
+	default:
+		return date
+	}
+}
+
+// Resumed returns the first occurrence on or after today, skipping the ones missed while paused.
+func (r *RecurringExpense) Resumed(today time.Time) time.Time {
+	next := r.NextDate
+	for next.Before(today) {
+		next = r.Following(next)
+	}
+	return next
+}
+
+func (r *RecurringExpense) dayInMonth(year int, month time.Month, location *time.Location) time.Time {
+	// Day 0 of the next month is the last day of this one.
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, location).Day()
+
+	day := r.DayOfMonth
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecurringExpense_Schedule(t *testing.T) {
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	monthly := &RecurringExpense{Period: Monthly, DayOfMonth: 31}
+	assert.Equal(t, day(2026, 10, 31), monthly.First(day(2026, 10, 17)))
+	assert.Equal(t, day(2026, 11, 30), monthly.Following(day(2026, 10, 31)))
+	assert.Equal(t, day(2027, 1, 31), monthly.Following(day(2026, 12, 31)))
+	assert.Equal(t, day(2027, 2, 28), monthly.Following(day(2027, 1, 31)))
+
+	rent := &RecurringExpense{Period: Monthly, DayOfMonth: 5}
+	assert.Equal(t, day(2026, 11, 5), rent.First(day(2026, 10, 17)))
+
+	yearly := &RecurringExpense{Period: Yearly, DayOfMonth: 29}
+	assert.Equal(t, day(2028, 2, 29), yearly.First(day(2028, 2, 1)))
+	assert.Equal(t, day(2029, 2, 28), yearly.Following(day(2028, 2, 29)))
+
+	weekly := &RecurringExpense{Period: Weekly}
+	assert.Equal(t, day(2026, 10, 17), weekly.First(day(2026, 10, 17)))
+	assert.Equal(t, day(2026, 10, 24), weekly.Following(day(2026, 10, 17)))
+
+	paused := &RecurringExpense{Period: Weekly, NextDate: day(2026, 8, 1)}
+	assert.Equal(t, day(2026, 10, 17), paused.Resumed(day(2026, 10, 17)))
+	assert.Equal(t, day(2026, 10, 24), paused.Resumed(day(2026, 10, 18)))
+}
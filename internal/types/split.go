@@ -0,0 +1,115 @@
+package types
+
+import "sort"
+
+// SplitShare is the part of a shared expense that falls on the member, in kopecks.
+type SplitShare struct {
+	MemberID int64
+	Sum      int
+}
+
+// Debt is the transfer from one member to another that settles them up, in kopecks.
+type Debt struct {
+	From int64
+	To   int64
+	Sum  int
+}
+
+// Allocate divides the total proportionally to the weights without losing a kopeck:
+// the rest of the rounding goes to the largest remainders, the first ones on a tie.
+func Allocate(total int, weights []int) []int {
+	result := make([]int, len(weights))
+
+	weightSum := 0
+	for _, weight := range weights {
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return result
+	}
+
+	remainders := make([]int, len(weights))
+	rest := total
+
+	for i, weight := range weights {
+		result[i] = total * weight / weightSum
+		remainders[i] = total * weight % weightSum
+		rest -= result[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+
+	for i := 0; i < rest; i++ {
+		result[order[i%len(order)]]++
+	}
+
+	return result
+}
+
+// SettleUp turns the balances of the members into the transfers that settle them up.
+// A positive balance is owed to the member, a negative one is owed by the member.
+// The largest debt is paid to the largest creditor first, so there are at most n-1 transfers.
+func SettleUp(balances map[int64]int) []Debt {
+	type balance struct {
+		memberID int64
+		sum      int
+	}
+
+	var debtors, creditors []balance
+
+	for memberID, sum := range balances {
+		switch {
+		case sum > 0:
+			creditors = append(creditors, balance{memberID, sum})
+		case sum < 0:
+			debtors = append(debtors, balance{memberID, -sum})
+		}
+	}
+
+	byLargest := func(balances []balance) {
+		sort.Slice(balances, func(i, j int) bool {
+			if balances[i].sum != balances[j].sum {
+				return balances[i].sum > balances[j].sum
+			}
+			return balances[i].memberID < balances[j].memberID
+		})
+	}
+	byLargest(debtors)
+	byLargest(creditors)
+
+	var debts []Debt
+
+	for len(debtors) > 0 && len(creditors) > 0 {
+		debtor, creditor := &debtors[0], &creditors[0]
+
+		sum := debtor.sum
+		if creditor.sum < sum {
+			sum = creditor.sum
+		}
+
+		debts = append(debts, Debt{From: debtor.memberID, To: creditor.memberID, Sum: sum})
+
+		debtor.sum -= sum
+		creditor.sum -= sum
+
+		if debtor.sum == 0 {
+			debtors = debtors[1:]
+		}
+		if creditor.sum == 0 {
+			creditors = creditors[1:]
+		}
+
+		// The rest of the partly paid one may be smaller than the next one now.
+		byLargest(debtors)
+		byLargest(creditors)
+	}
+
+	return debts
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocate(t *testing.T) {
+	assert.Equal(t, []int{334, 333, 333}, Allocate(1000, []int{1, 1, 1}))
+	assert.Equal(t, []int{667, 333}, Allocate(1000, []int{2, 1}))
+	assert.Equal(t, []int{100000, 200000}, Allocate(300000, []int{1000, 2000}))
+	assert.Equal(t, []int{0, 0}, Allocate(1000, []int{0, 0}))
+
+	// The rest goes to the largest remainders.
+	assert.Equal(t, []int{1, 2, 2}, Allocate(5, []int{1, 2, 2}))
+}
+
+func TestSettleUp(t *testing.T) {
+	// 1 paid 3000 for three, 2 paid 600 for three.
+	debts := SettleUp(map[int64]int{1: 2000, 2: -400, 3: -1600})
+
+	assert.Equal(t, []Debt{
+		{From: 3, To: 1, Sum: 1600},
+		{From: 2, To: 1, Sum: 400},
+	}, debts)
+
+	debts = SettleUp(map[int64]int{1: 500, 2: 500, 3: -1000, 4: 0})
+
+	assert.Equal(t, []Debt{
+		{From: 3, To: 1, Sum: 500},
+		{From: 3, To: 2, Sum: 500},
+	}, debts)
+
+	assert.Empty(t, SettleUp(map[int64]int{1: 0}))
+}
@@ -14,6 +14,23 @@ const (
 	RUB Currency = "RUB"
 )
 
+// ReportMode is how the reports are shown to the user.
+type ReportMode string
+
+const (
+	ReportText  ReportMode = "text"
+	ReportChart ReportMode = "chart"
+)
+
+// ReportCurrencyMode is how reports convert expenses entered in different currencies.
+type ReportCurrencyMode string
+
+const (
+	ReportOriginal    ReportCurrencyMode = "original"     // Sums in the currencies they were entered in.
+	ReportExpenseDate ReportCurrencyMode = "expense_date" // At the rate of the expense date.
+	ReportToday       ReportCurrencyMode = "today"        // At today's rate.
+)
+
 type UserStateType struct {
 	CurrentState CurrentState // Contains the expense we are modifying now, and what we are modifying.
 	Currency     Currency     // With which currency the user is working now.
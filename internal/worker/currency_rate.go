@@ -12,18 +12,27 @@ type updater interface {
 }
 
 type CurrencyRateWorker struct {
-	updater updater
+	updater   updater
+	frequency chan time.Duration
 }
 
 func NewCurrencyRateWorker(updater updater) *CurrencyRateWorker {
 	return &CurrencyRateWorker{
-		updater: updater,
+		updater:   updater,
+		frequency: make(chan time.Duration, 1),
 	}
 }
 
+// SetUpdateFrequency changes the frequency of the running worker, the next update is a full new period later.
+func (w *CurrencyRateWorker) SetUpdateFrequency(updateFrequency time.Duration) {
+	setFrequency(w.frequency, updateFrequency)
+}
+
 func (w *CurrencyRateWorker) Run(ctx context.Context, updateFrequency time.Duration) {
 	go func() {
 		ticker := time.NewTicker(updateFrequency)
+		defer ticker.Stop()
+
 		err := w.updater.UpdateCurrencyRate(ctx)
 		if err != nil {
 			log.Println(err)
@@ -34,6 +43,12 @@ func (w *CurrencyRateWorker) Run(ctx context.Context, updateFrequency time.Durat
 			case <-ctx.Done():
 				w.updater.Close()
 				return
+			case frequency := <-w.frequency:
+				// A reload of the same frequency does not put the next update off.
+				if frequency != updateFrequency {
+					updateFrequency = frequency
+					ticker.Reset(updateFrequency)
+				}
 			case <-ticker.C:
 				select {
 				case <-ctx.Done():
@@ -49,3 +64,19 @@ func (w *CurrencyRateWorker) Run(ctx context.Context, updateFrequency time.Durat
 		}
 	}()
 }
+
+// setFrequency keeps only the latest frequency if the worker has not taken the previous one yet.
+func setFrequency(frequencies chan time.Duration, frequency time.Duration) {
+	for {
+		select {
+		case frequencies <- frequency:
+			return
+		default:
+		}
+
+		select {
+		case <-frequencies:
+		default:
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetFrequency_ShouldKeepLatestFrequency(t *testing.T) {
+	frequencies := make(chan time.Duration, 1)
+
+	setFrequency(frequencies, time.Minute)
+	setFrequency(frequencies, time.Hour)
+
+	assert.Equal(t, time.Hour, <-frequencies)
+	assert.Empty(t, frequencies)
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type recurringDB interface {
+	GetDueRecurringExpenses(ctx context.Context, date time.Time) ([]types.RecurringExpense, error)
+	AdvanceRecurringExpense(ctx context.Context, id int, date, nextDate time.Time) (bool, error)
+}
+
+type expenseWriter interface {
+	NextExpenseID(ctx context.Context) (int, error)
+	WriteExpense(ctx context.Context, fromID int64, expense *types.Expense) error
+}
+
+type txManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type recurringNotifier interface {
+	NotifyRecurringExpense(ctx context.Context, userID int64, expense *types.Expense) error
+}
+
+// RecurringExpenseWorker writes expenses of the recurring templates when they are due.
+type RecurringExpenseWorker struct {
+	recurringDB recurringDB
+	expensesDB  expenseWriter
+	txManager   txManager
+	notifier    recurringNotifier
+	frequency   chan time.Duration
+}
+
+func NewRecurringExpenseWorker(recurringDB recurringDB, expensesDB expenseWriter, txManager txManager, notifier recurringNotifier) *RecurringExpenseWorker {
+	return &RecurringExpenseWorker{
+		recurringDB: recurringDB,
+		expensesDB:  expensesDB,
+		txManager:   txManager,
+		notifier:    notifier,
+		frequency:   make(chan time.Duration, 1),
+	}
+}
+
+// SetCheckFrequency changes the frequency of the running worker, the next check is a full new period later.
+func (w *RecurringExpenseWorker) SetCheckFrequency(checkFrequency time.Duration) {
+	setFrequency(w.frequency, checkFrequency)
+}
+
+func (w *RecurringExpenseWorker) Run(ctx context.Context, checkFrequency time.Duration) {
+	go func() {
+		ticker := time.NewTicker(checkFrequency)
+		defer ticker.Stop()
+
+		err := w.WriteDueExpenses(ctx, time.Now())
+		if err != nil {
+			log.Println(err)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case frequency := <-w.frequency:
+				if frequency != checkFrequency {
+					checkFrequency = frequency
+					ticker.Reset(checkFrequency)
+				}
+			case <-ticker.C:
+				err := w.WriteDueExpenses(ctx, time.Now())
+				if err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}()
+}
+
+// WriteDueExpenses writes every occurrence up to the date, including the ones missed while the bot was down.
+func (w *RecurringExpenseWorker) WriteDueExpenses(ctx context.Context, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	due, err := w.recurringDB.GetDueRecurringExpenses(ctx, today)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot GetDueRecurringExpenses")
+	}
+
+	for i := range due {
+		recurring := &due[i]
+
+		for !recurring.NextDate.After(today) {
+			expense, err := w.writeOccurrence(ctx, recurring)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot writeOccurrence")
+			}
+
+			if expense == nil {
+				// Already written by another instance or paused meanwhile.
+				break
+			}
+
+			err = w.notifier.NotifyRecurringExpense(ctx, recurring.UserID, expense)
+			if err != nil {
+				log.Println(err)
+			}
+
+			recurring.NextDate = recurring.Following(recurring.NextDate)
+		}
+	}
+
+	return nil
+}
+
+// writeOccurrence writes the expense and moves the template to the next date in one transaction,
+// so an occurrence is written exactly once even if the bot restarts in between.
+func (w *RecurringExpenseWorker) writeOccurrence(ctx context.Context, recurring *types.RecurringExpense) (*types.Expense, error) {
+	var expense *types.Expense
+
+	err := w.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		ok, err := w.recurringDB.AdvanceRecurringExpense(ctx, recurring.ID,
+			recurring.NextDate, recurring.Following(recurring.NextDate))
+
+		if err != nil {
+			return errors.Wrap(err, "cannot AdvanceRecurringExpense")
+		}
+
+		if !ok {
+			return nil
+		}
+
+		expenseID, err := w.expensesDB.NextExpenseID(ctx)
+
+		if err != nil {
+			return errors.Wrap(err, "cannot NextExpenseID")
+		}
+
+		expense = &types.Expense{
+			ExpenseID: expenseID,
+			Sum:       recurring.Sum,
+			Category:  recurring.Category,
+			Date:      recurring.NextDate,
+		}
+
+		return errors.Wrap(w.expensesDB.WriteExpense(ctx, recurring.UserID, expense), "cannot WriteExpense")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return expense, nil
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/types"
+)
+
+type fakeRecurringDB struct {
+	recurring []types.RecurringExpense
+}
+
+func (db *fakeRecurringDB) GetDueRecurringExpenses(ctx context.Context, date time.Time) ([]types.RecurringExpense, error) {
+	var due []types.RecurringExpense
+	for _, recurring := range db.recurring {
+		if !recurring.NextDate.After(date) {
+			due = append(due, recurring)
+		}
+	}
+	return due, nil
+}
+
+func (db *fakeRecurringDB) AdvanceRecurringExpense(ctx context.Context, id int, date, nextDate time.Time) (bool, error) {
+	for i := range db.recurring {
+		if db.recurring[i].ID == id && db.recurring[i].NextDate.Equal(date) {
+			db.recurring[i].NextDate = nextDate
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeExpensesDB struct {
+	lastID   int
+	expenses []types.Expense
+}
+
+func (db *fakeExpensesDB) NextExpenseID(ctx context.Context) (int, error) {
+	db.lastID--
+	return db.lastID, nil
+}
+
+func (db *fakeExpensesDB) WriteExpense(ctx context.Context, fromID int64, expense *types.Expense) error {
+	db.expenses = append(db.expenses, *expense)
+	return nil
+}
+
+type fakeTxManager struct{}
+
+func (fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeNotifier struct {
+	notified int
+}
+
+func (n *fakeNotifier) NotifyRecurringExpense(ctx context.Context, userID int64, expense *types.Expense) error {
+	n.notified++
+	return nil
+}
+
+func Test_WriteDueExpenses_ShouldWriteMissedOccurrencesOnce(t *testing.T) {
+	ctx := context.Background()
+
+	recurringDB := &fakeRecurringDB{recurring: []types.RecurringExpense{{
+		ID:         1,
+		UserID:     123,
+		Sum:        50000,
+		Category:   "аренда",
+		Period:     types.Monthly,
+		DayOfMonth: 5,
+		NextDate:   time.Date(2026, 8, 5, 0, 0, 0, 0, time.UTC),
+	}}}
+	expensesDB := &fakeExpensesDB{}
+	notifier := &fakeNotifier{}
+	worker := NewRecurringExpenseWorker(recurringDB, expensesDB, fakeTxManager{}, notifier)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, worker.WriteDueExpenses(ctx, now))
+	// The second run, e.g. after a restart, must not write anything.
+	assert.NoError(t, worker.WriteDueExpenses(ctx, now))
+
+	assert.Len(t, expensesDB.expenses, 3)
+	assert.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), expensesDB.expenses[2].Date)
+	assert.Equal(t, time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC), recurringDB.recurring[0].NextDate)
+	assert.Equal(t, 3, notifier.notified)
+}
+
+func Test_WriteDueExpenses_ShouldNotBackfillResumedExpense(t *testing.T) {
+	ctx := context.Background()
+
+	recurringDB := &fakeRecurringDB{recurring: []types.RecurringExpense{{
+		ID:         1,
+		UserID:     123,
+		Sum:        50000,
+		Category:   "аренда",
+		Period:     types.Monthly,
+		DayOfMonth: 5,
+		NextDate:   time.Date(2026, 8, 5, 0, 0, 0, 0, time.UTC),
+	}}}
+	expensesDB := &fakeExpensesDB{}
+	worker := NewRecurringExpenseWorker(recurringDB, expensesDB, fakeTxManager{}, &fakeNotifier{})
+
+	// Resumed two months after it was paused.
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	recurring := &recurringDB.recurring[0]
+	recurring.NextDate = recurring.Resumed(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, worker.WriteDueExpenses(ctx, now))
+
+	assert.Empty(t, expensesDB.expenses)
+	assert.Equal(t, time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC), recurring.NextDate)
+}
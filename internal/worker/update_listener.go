@@ -13,7 +13,6 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/model/callbacks"
 	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/model/messages"
-	"gitlab.ozon.dev/e.gerasimov/telegram-bot/internal/redis"
 )
 
 var (
@@ -68,15 +67,19 @@ type CallbackHandler interface {
 	IncomingCallback(ctx context.Context, callback *callbacks.CallbackData) error
 }
 
+type cacheCloser interface {
+	Close()
+}
+
 type updateListenerWorker struct {
 	updateFetcher   updateFetcher
 	messageHandler  MessageHandler
 	callbackHandler CallbackHandler
-	cache           *redis.Cache
+	cache           cacheCloser
 }
 
 func NewUpdateListenerWorker(updateFetcher updateFetcher,
-	messageHandler MessageHandler, callbackHandler CallbackHandler, cache *redis.Cache) *updateListenerWorker {
+	messageHandler MessageHandler, callbackHandler CallbackHandler, cache cacheCloser) *updateListenerWorker {
 	return &updateListenerWorker{
 		updateFetcher:   updateFetcher,
 		messageHandler:  messageHandler,
@@ -122,12 +125,23 @@ func (w *updateListenerWorker) HandleUpdate(ctx context.Context, update tgbotapi
 		user := fmt.Sprintf("%d", update.SentFrom().ID)
 		SentMessagesTotal.WithLabelValues(user, update.Message.Text).Inc()
 
+		msg := &messages.Message{
+			Text:       update.Message.Text,
+			UserID:     update.Message.Chat.ID,
+			MemberID:   update.Message.From.ID,
+			MemberName: memberName(update.Message.From),
+			MessageID:  update.Message.MessageID,
+		}
+
+		if update.Message.Document != nil {
+			msg.Document = &messages.Document{
+				FileID:   update.Message.Document.FileID,
+				FileName: update.Message.Document.FileName,
+			}
+		}
+
 		startTime := time.Now()
-		err := w.messageHandler.IncomingMessage(ctx, &messages.Message{
-			Text:      update.Message.Text,
-			UserID:    update.Message.From.ID,
-			MessageID: update.Message.MessageID,
-		})
+		err := w.messageHandler.IncomingMessage(ctx, msg)
 
 		duration := time.Since(startTime)
 
@@ -149,7 +163,8 @@ func (w *updateListenerWorker) HandleUpdate(ctx context.Context, update tgbotapi
 		startTime := time.Now()
 		err := w.callbackHandler.IncomingCallback(ctx, &callbacks.CallbackData{
 			Data:       update.CallbackData(),
-			FromID:     update.CallbackQuery.From.ID,
+			FromID:     update.CallbackQuery.Message.Chat.ID,
+			MemberID:   update.CallbackQuery.From.ID,
 			MessageID:  update.CallbackQuery.Message.MessageID,
 			CallbackID: update.CallbackQuery.ID,
 		})
@@ -165,3 +180,12 @@ func (w *updateListenerWorker) HandleUpdate(ctx context.Context, update tgbotapi
 
 	return nil
 }
+
+// memberName is how the member is shown in the shared ledger of a group.
+func memberName(user *tgbotapi.User) string {
+	if user.FirstName != "" {
+		return user.FirstName
+	}
+
+	return user.UserName
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Filter of the last /history request, pages are switched with inline buttons.
+ALTER TABLE users
+    ADD COLUMN history_category TEXT,
+    ADD COLUMN history_begin    DATE,
+    ADD COLUMN history_end      DATE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN history_category,
+    DROP COLUMN history_begin,
+    DROP COLUMN history_end;
+
+-- +goose StatementEnd
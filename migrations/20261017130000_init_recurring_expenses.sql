@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE recurring_expenses
+(
+    recurring_id SERIAL PRIMARY KEY,
+    tg_user_id   BIGINT REFERENCES users (tg_user_id),
+    expense_sum  INTEGER,
+    category     TEXT,
+    period       TEXT,
+    day_of_month INTEGER,
+    next_date    DATE,
+    paused       BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX recurring_expenses_next_date_idx on recurring_expenses(next_date);
+
+-- Ids of expenses created without an expense card message.
+-- They are negated so they never clash with telegram message ids.
+CREATE SEQUENCE generated_expense_id_seq;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SEQUENCE generated_expense_id_seq;
+
+DROP INDEX recurring_expenses_next_date_idx;
+
+DROP TABLE recurring_expenses;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A budget of the category applies from the month it was set for
+-- until a budget is set for a later month.
+CREATE TABLE budgets
+(
+    tg_user_id   BIGINT REFERENCES users (tg_user_id),
+    category     TEXT,
+    since        DATE,
+    budget_limit INTEGER,
+
+    UNIQUE (tg_user_id, category, since)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE budgets;
+
+-- +goose StatementEnd
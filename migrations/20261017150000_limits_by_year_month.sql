@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Limits are keyed by the calendar month now, limit_year = limit_month = 0
+-- is the default limit for every month without its own limit.
+ALTER TABLE limits
+    ADD COLUMN limit_year  INTEGER,
+    ADD COLUMN limit_month INTEGER;
+
+-- Old limits applied to the month of every year, keep them for the current one.
+UPDATE limits
+SET
+    limit_year  = EXTRACT(YEAR FROM NOW()),
+    limit_month = month_no;
+
+DELETE FROM limits a
+    USING limits b
+WHERE
+    a.ctid < b.ctid AND
+    a.tg_user_id = b.tg_user_id AND
+    a.month_no = b.month_no;
+
+ALTER TABLE limits
+    DROP COLUMN month_no,
+    ADD CONSTRAINT limits_user_year_month_key UNIQUE (tg_user_id, limit_year, limit_month);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE limits
+    DROP CONSTRAINT limits_user_year_month_key,
+    ADD COLUMN month_no INTEGER;
+
+DELETE FROM limits
+WHERE
+    limit_month = 0;
+
+UPDATE limits
+SET
+    month_no = limit_month;
+
+ALTER TABLE limits
+    DROP COLUMN limit_year,
+    DROP COLUMN limit_month;
+
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Column mapping of the user's bank statements, see statement.Profile.
+CREATE TABLE import_profiles
+(
+    tg_user_id BIGINT UNIQUE PRIMARY KEY REFERENCES users (tg_user_id),
+    profile    JSONB
+);
+
+-- Rows with the pattern in the description get the category.
+CREATE TABLE category_rules
+(
+    tg_user_id BIGINT REFERENCES users (tg_user_id),
+    pattern    TEXT,
+    category   TEXT,
+
+    UNIQUE (tg_user_id, pattern)
+);
+
+-- Parsed statements waiting for the user's confirmation.
+CREATE TABLE pending_imports
+(
+    import_id  SERIAL PRIMARY KEY,
+    tg_user_id BIGINT REFERENCES users (tg_user_id),
+    expenses   JSONB,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX expenses_user_date_sum_idx on expenses(tg_user_id, created_at, expense_sum);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX expenses_user_date_sum_idx;
+
+DROP TABLE pending_imports;
+
+DROP TABLE category_rules;
+
+DROP TABLE import_profiles;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- How reports are shown: 'text' or 'chart'.
+ALTER TABLE users
+    ADD COLUMN report_mode TEXT NOT NULL DEFAULT 'text';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN report_mode;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The amount as it was entered, in hundredths of its currency.
+-- expense_sum stays in kopecks at the rate of the expense date.
+ALTER TABLE expenses
+    ADD COLUMN original_sum      INTEGER,
+    ADD COLUMN original_currency TEXT;
+
+UPDATE expenses
+SET
+    original_sum = expense_sum,
+    original_currency = 'RUB';
+
+-- How reports convert the expenses: 'original', 'expense_date' or 'today'.
+ALTER TABLE users
+    ADD COLUMN report_currency TEXT NOT NULL DEFAULT 'expense_date';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN report_currency;
+
+ALTER TABLE expenses
+    DROP COLUMN original_sum,
+    DROP COLUMN original_currency;
+
+-- +goose StatementEnd
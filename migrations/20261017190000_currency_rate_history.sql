@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A rate per currency, base currency and the date the CBR set it,
+-- so expenses of past dates are converted at the rate of their date.
+ALTER TABLE currency_rate
+    DROP CONSTRAINT currency_rate_char_code_key,
+    ADD CONSTRAINT currency_rate_code_base_date_key UNIQUE (char_code, base, rate_date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM currency_rate other
+WHERE
+    other.base <> 'RUB';
+
+DELETE FROM currency_rate older
+USING currency_rate newer
+WHERE
+    older.char_code = newer.char_code AND
+    older.rate_date < newer.rate_date;
+
+ALTER TABLE currency_rate
+    DROP CONSTRAINT currency_rate_code_base_date_key,
+    ADD CONSTRAINT currency_rate_char_code_key UNIQUE (char_code);
+
+-- +goose StatementEnd
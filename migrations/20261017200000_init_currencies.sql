@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Currencies published by the CBR, filled by the rates updater.
+CREATE TABLE currencies
+(
+    char_code TEXT PRIMARY KEY,
+    name      TEXT
+);
+
+INSERT INTO currencies(char_code, name)
+VALUES
+    ('RUB', 'Российский рубль'),
+    ('USD', 'Доллар США'),
+    ('EUR', 'Евро'),
+    ('CNY', 'Китайский юань');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE currencies;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Rates are kept in roubles with the decimal places published by the CBR instead of whole kopecks.
+ALTER TABLE currency_rate
+    ALTER COLUMN rate TYPE NUMERIC(20, 8) USING rate / 100.0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE currency_rate
+    ALTER COLUMN rate TYPE INTEGER USING ROUND(rate * 100);
+
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Wallets of the user: cash, cards. The opening balance is in hundredths of the account currency.
+CREATE TABLE accounts
+(
+    account_id      SERIAL PRIMARY KEY,
+    tg_user_id      BIGINT REFERENCES users (tg_user_id),
+    name            TEXT,
+    currency        TEXT,
+    opening_balance BIGINT,
+
+    UNIQUE (tg_user_id, name)
+);
+
+-- Expenses without an account do not change any balance.
+ALTER TABLE expenses
+    ADD COLUMN account_id INTEGER REFERENCES accounts (account_id) ON DELETE SET NULL;
+
+-- Sums are in hundredths of the currency of each account.
+CREATE TABLE transfers
+(
+    transfer_id     SERIAL PRIMARY KEY,
+    tg_user_id      BIGINT REFERENCES users (tg_user_id),
+    from_account_id INTEGER REFERENCES accounts (account_id) ON DELETE CASCADE,
+    to_account_id   INTEGER REFERENCES accounts (account_id) ON DELETE CASCADE,
+    from_sum        BIGINT,
+    to_sum          BIGINT,
+    created_at      DATE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE transfers;
+
+ALTER TABLE expenses
+    DROP COLUMN account_id;
+
+DROP TABLE accounts;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Income is kept next to the expenses and aggregated the same way, see types.ExpenseKind.
+ALTER TABLE expenses
+    ADD COLUMN kind TEXT NOT NULL DEFAULT 'expense';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM expenses WHERE kind = 'income';
+
+ALTER TABLE expenses
+    DROP COLUMN kind;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A group chat has its own row in users and keeps the ledger shared by its members.
+CREATE TABLE group_members
+(
+    chat_id    BIGINT REFERENCES users (tg_user_id),
+    tg_user_id BIGINT,
+    name       TEXT,
+
+    PRIMARY KEY (chat_id, tg_user_id)
+);
+
+-- The member who paid, NULL for the expenses written by the bot itself.
+ALTER TABLE expenses
+    ADD COLUMN paid_by BIGINT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE expenses
+    DROP COLUMN paid_by;
+
+DROP TABLE group_members;
+
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The parts of a shared expense by the members, in kopecks. They are fixed when the expense
+-- is split, the splits of a deleted expense are ignored.
+CREATE TABLE expense_splits
+(
+    tg_user_id BIGINT REFERENCES users (tg_user_id),
+    expense_id INTEGER,
+    member_id  BIGINT,
+    share_sum  INTEGER,
+
+    PRIMARY KEY (tg_user_id, expense_id, member_id)
+);
+
+-- Money the members gave each other to settle up, in kopecks.
+CREATE TABLE settlements
+(
+    settlement_id  SERIAL PRIMARY KEY,
+    tg_user_id     BIGINT REFERENCES users (tg_user_id),
+    from_member_id BIGINT,
+    to_member_id   BIGINT,
+    settlement_sum INTEGER,
+    created_at     DATE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE settlements;
+
+DROP TABLE expense_splits;
+
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The catalogue of the user's categories, names are unique ignoring the case.
+CREATE TABLE categories
+(
+    category_id SERIAL PRIMARY KEY,
+    tg_user_id  BIGINT REFERENCES users (tg_user_id),
+    name        TEXT,
+    emoji       TEXT    DEFAULT '',
+    archived    BOOLEAN DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX categories_user_name_idx on categories(tg_user_id, lower(name));
+
+-- The same as types.NormalizeCategory: "  кафе " becomes "Кафе".
+CREATE FUNCTION pg_temp.normalize_category(name TEXT) RETURNS TEXT AS $$
+    SELECT upper(left(n, 1)) || substr(n, 2)
+    FROM (SELECT regexp_replace(btrim(name), '\s+', ' ', 'g') AS n) AS normalized
+$$ LANGUAGE SQL;
+
+UPDATE expenses
+SET category = pg_temp.normalize_category(category)
+WHERE category <> pg_temp.normalize_category(category);
+
+-- The most used spelling of the category becomes the name in the catalogue.
+INSERT INTO categories(tg_user_id, name)
+SELECT DISTINCT ON (tg_user_id, lower(category))
+    tg_user_id,
+    category
+FROM expenses
+WHERE category <> ''
+GROUP BY tg_user_id, category
+ORDER BY tg_user_id, lower(category), COUNT(*) DESC, category;
+
+UPDATE expenses AS e
+SET category = c.name
+FROM categories AS c
+WHERE
+    c.tg_user_id = e.tg_user_id AND
+    lower(c.name) = lower(e.category) AND
+    c.name <> e.category;
+
+-- The name in the catalogue if there is one, the normalized name otherwise.
+CREATE FUNCTION pg_temp.catalogue_category(user_id BIGINT, name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(
+        (SELECT c.name FROM categories AS c
+         WHERE c.tg_user_id = user_id AND lower(c.name) = lower(pg_temp.normalize_category(name))),
+        pg_temp.normalize_category(name)
+    )
+$$ LANGUAGE SQL;
+
+UPDATE recurring_expenses
+SET category = pg_temp.catalogue_category(tg_user_id, category);
+
+UPDATE category_rules
+SET category = pg_temp.catalogue_category(tg_user_id, category);
+
+-- Budgets that become the same after the normalization are unique by the month, one of them is kept.
+DELETE FROM budgets AS b
+USING budgets AS o
+WHERE
+    b.tg_user_id = o.tg_user_id AND
+    b.since = o.since AND
+    lower(pg_temp.normalize_category(b.category)) = lower(pg_temp.normalize_category(o.category)) AND
+    b.ctid > o.ctid;
+
+UPDATE budgets
+SET category = pg_temp.catalogue_category(tg_user_id, category);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX categories_user_name_idx;
+
+DROP TABLE categories;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Categories have one level of subcategories: "Транспорт > Такси".
+-- Expenses keep the name of the subcategory, reports roll it up into the parent.
+ALTER TABLE categories
+    ADD COLUMN parent_id INTEGER REFERENCES categories (category_id) ON DELETE SET NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE categories
+    DROP COLUMN parent_id;
+
+-- +goose StatementEnd
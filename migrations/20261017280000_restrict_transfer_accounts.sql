@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Deleting an account must not delete the transfers, they change the balance of the other account.
+ALTER TABLE transfers
+    DROP CONSTRAINT transfers_from_account_id_fkey,
+    DROP CONSTRAINT transfers_to_account_id_fkey,
+    ADD CONSTRAINT transfers_from_account_id_fkey
+        FOREIGN KEY (from_account_id) REFERENCES accounts (account_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT transfers_to_account_id_fkey
+        FOREIGN KEY (to_account_id) REFERENCES accounts (account_id) ON DELETE RESTRICT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE transfers
+    DROP CONSTRAINT transfers_from_account_id_fkey,
+    DROP CONSTRAINT transfers_to_account_id_fkey,
+    ADD CONSTRAINT transfers_from_account_id_fkey
+        FOREIGN KEY (from_account_id) REFERENCES accounts (account_id) ON DELETE CASCADE,
+    ADD CONSTRAINT transfers_to_account_id_fkey
+        FOREIGN KEY (to_account_id) REFERENCES accounts (account_id) ON DELETE CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Cards sent by /income. The income is written when its card is first edited, like an expense.
+CREATE TABLE income_cards
+(
+    tg_user_id BIGINT,
+    card_id    INTEGER,
+
+    PRIMARY KEY (tg_user_id, card_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE income_cards;
+
+-- +goose StatementEnd
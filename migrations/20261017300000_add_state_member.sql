@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The member of a group chat who edits a card, the other members' messages are not the input.
+ALTER TABLE users
+    ADD COLUMN state_member_id BIGINT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN state_member_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The accounts are found by the name regardless of the case, as the categories are.
+-- The names which differ only in the case get the id, so they stay distinguishable.
+UPDATE accounts a
+SET name = a.name || ' ' || a.account_id
+WHERE EXISTS(SELECT 1
+             FROM accounts b
+             WHERE b.tg_user_id = a.tg_user_id
+               AND lower(b.name) = lower(a.name)
+               AND b.account_id < a.account_id);
+
+ALTER TABLE accounts
+    DROP CONSTRAINT accounts_tg_user_id_name_key;
+
+CREATE UNIQUE INDEX accounts_user_name_idx on accounts (tg_user_id, lower(name));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX accounts_user_name_idx;
+
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_tg_user_id_name_key UNIQUE (tg_user_id, name);
+
+-- +goose StatementEnd